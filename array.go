@@ -0,0 +1,119 @@
+package sqlbuilder
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+)
+
+// Array operators
+// On PostgreSQL the field is a native array column and the value is bound as a single
+// array parameter, so the driver must be able to encode Go slices (pgx does, lib/pq
+// needs pq.Array). On MySQL the field is a JSON array column and the value is bound as
+// a JSON document.
+const (
+	OpArrayContains    = "array_contains"     // Field contains every value (or the single value)
+	OpArrayContainedBy = "array_contained_by" // Every element of field is in the value
+	OpArrayOverlaps    = "array_overlaps"     // Field and value share at least one element
+	OpArrayLength      = "array_length"       // Compares the element count, value is ArrayLength or its decoded JSON object
+)
+
+// ArrayLength is the value of OpArrayLength
+type ArrayLength struct {
+	Operator string `json:"operator"` // One of eq, ne, gt, gte, lt, lte
+	Length   int    `json:"length"`
+}
+
+// comparisonOperators maps the comparison operators to SQL
+var comparisonOperators = map[string]string{
+	OpEqual:         "=",
+	OpNotEqual:      "!=",
+	OpGreaterThan:   ">",
+	OpGreaterThanEq: ">=",
+	OpLessThan:      "<",
+	OpLessThanEq:    "<=",
+}
+
+// buildArrayCondition builds the array operators for the builder dialect
+func (s *SQLBuilder) buildArrayCondition(column, field, operator string, value any) string {
+	if operator == OpArrayLength {
+		return s.buildArrayLengthCondition(column, field, value)
+	}
+
+	isSlice := isSliceValue(value)
+	if !isSlice && operator != OpArrayContains {
+		s.setErr(fmt.Errorf("%w: %s expects a slice, got %T", ErrInvalidValue, operator, value))
+		return ""
+	}
+
+	if s.dialect == DialectPostgres {
+		if !isSlice {
+			return fmt.Sprintf("%s = ANY(%s)", s.bind(field, value), column)
+		}
+		symbol := map[string]string{
+			OpArrayContains:    "@>",
+			OpArrayContainedBy: "<@",
+			OpArrayOverlaps:    "&&",
+		}[operator]
+		return fmt.Sprintf("%s %s %s", column, symbol, s.bind(field, value))
+	}
+
+	if !isSlice {
+		return fmt.Sprintf("%s MEMBER OF(%s)", s.bind(field, value), column)
+	}
+	document, err := json.Marshal(value)
+	if err != nil {
+		s.setErr(fmt.Errorf("sqlbuilder: encode %s value: %w", field, err))
+		return ""
+	}
+	placeholder := s.bind(field, string(document))
+	switch operator {
+	case OpArrayContainedBy:
+		return fmt.Sprintf("JSON_CONTAINS(%s, %s)", placeholder, column)
+	case OpArrayOverlaps:
+		return fmt.Sprintf("JSON_OVERLAPS(%s, %s)", column, placeholder)
+	}
+	return fmt.Sprintf("JSON_CONTAINS(%s, %s)", column, placeholder)
+}
+
+// buildArrayLengthCondition compares the number of elements in the array
+func (s *SQLBuilder) buildArrayLengthCondition(column, field string, value any) string {
+	length, ok := structValue[ArrayLength](value)
+	symbol, known := comparisonOperators[length.Operator]
+	if !ok || !known {
+		s.setErr(fmt.Errorf("%w: %s expects an ArrayLength with a comparison operator", ErrInvalidValue, OpArrayLength))
+		return ""
+	}
+
+	function := "JSON_LENGTH"
+	if s.dialect == DialectPostgres {
+		function = "cardinality"
+	}
+	return fmt.Sprintf("%s(%s) %s %s", function, column, symbol, s.bind(field, length.Length))
+}
+
+// listValues returns the items of a slice or array value, []string{"a"} as []any{"a"}
+// ok is false for other values, including []byte.
+func listValues(value any) ([]any, bool) {
+	if values, ok := value.([]any); ok {
+		return values, true
+	}
+	if !isSliceValue(value) {
+		return nil, false
+	}
+	list := reflect.ValueOf(value)
+	values := make([]any, list.Len())
+	for i := range values {
+		values[i] = list.Index(i).Interface()
+	}
+	return values, true
+}
+
+// isSliceValue returns true for slices and arrays other than []byte
+func isSliceValue(value any) bool {
+	if _, isBytes := value.([]byte); isBytes || value == nil {
+		return false
+	}
+	kind := reflect.TypeOf(value).Kind()
+	return kind == reflect.Slice || kind == reflect.Array
+}
@@ -0,0 +1,139 @@
+package sqlbuilder
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Test array operators per dialect
+func TestSQLBuilder_ArrayOperators(t *testing.T) {
+	tests := []struct {
+		name           string
+		dialect        Dialect
+		operator       string
+		value          any
+		expectedSQL    string
+		expectedParams []any
+	}{
+		{
+			name:           "postgres contains",
+			dialect:        DialectPostgres,
+			operator:       OpArrayContains,
+			value:          []string{"go", "sql"},
+			expectedSQL:    "tags @> $1",
+			expectedParams: []any{[]string{"go", "sql"}},
+		},
+		{
+			name:           "postgres contains single value",
+			dialect:        DialectPostgres,
+			operator:       OpArrayContains,
+			value:          "go",
+			expectedSQL:    "$1 = ANY(tags)",
+			expectedParams: []any{"go"},
+		},
+		{
+			name:           "postgres contained by",
+			dialect:        DialectPostgres,
+			operator:       OpArrayContainedBy,
+			value:          []string{"go", "sql"},
+			expectedSQL:    "tags <@ $1",
+			expectedParams: []any{[]string{"go", "sql"}},
+		},
+		{
+			name:           "postgres overlaps",
+			dialect:        DialectPostgres,
+			operator:       OpArrayOverlaps,
+			value:          []int{1, 2},
+			expectedSQL:    "tags && $1",
+			expectedParams: []any{[]int{1, 2}},
+		},
+		{
+			name:           "postgres length",
+			dialect:        DialectPostgres,
+			operator:       OpArrayLength,
+			value:          ArrayLength{Operator: OpGreaterThanEq, Length: 2},
+			expectedSQL:    "cardinality(tags) >= $1",
+			expectedParams: []any{2},
+		},
+		{
+			name:           "mysql contains",
+			operator:       OpArrayContains,
+			value:          []string{"go", "sql"},
+			expectedSQL:    "JSON_CONTAINS(tags, ?)",
+			expectedParams: []any{`["go","sql"]`},
+		},
+		{
+			name:           "mysql member of",
+			operator:       OpArrayContains,
+			value:          "go",
+			expectedSQL:    "? MEMBER OF(tags)",
+			expectedParams: []any{"go"},
+		},
+		{
+			name:           "mysql contained by",
+			operator:       OpArrayContainedBy,
+			value:          []any{"go", 1},
+			expectedSQL:    "JSON_CONTAINS(?, tags)",
+			expectedParams: []any{`["go",1]`},
+		},
+		{
+			name:           "mysql overlaps",
+			operator:       OpArrayOverlaps,
+			value:          [2]string{"a", "b"},
+			expectedSQL:    "JSON_OVERLAPS(tags, ?)",
+			expectedParams: []any{`["a","b"]`},
+		},
+		{
+			name:           "mysql length",
+			operator:       OpArrayLength,
+			value:          &ArrayLength{Operator: OpEqual, Length: 0},
+			expectedSQL:    "JSON_LENGTH(tags) = ?",
+			expectedParams: []any{0},
+		},
+		{
+			name:           "decoded json length",
+			dialect:        DialectPostgres,
+			operator:       OpArrayLength,
+			value:          map[string]any{"operator": "gt", "length": float64(2)},
+			expectedSQL:    "cardinality(tags) > $1",
+			expectedParams: []any{2},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			builder := NewSQLBuilder()
+			builder.SetDialect(tt.dialect)
+			assert.Equal(t, tt.expectedSQL, builder.buildCondition("tags", tt.operator, tt.value))
+			assert.Equal(t, tt.expectedParams, builder.GetParams())
+			assert.NoError(t, builder.Err())
+		})
+	}
+}
+
+// Test invalid array operator values
+func TestSQLBuilder_ArrayOperatorsInvalid(t *testing.T) {
+	tests := []struct {
+		name     string
+		operator string
+		value    any
+	}{
+		{name: "overlaps scalar", operator: OpArrayOverlaps, value: "go"},
+		{name: "contained by bytes", operator: OpArrayContainedBy, value: []byte("go")},
+		{name: "length without struct", operator: OpArrayLength, value: 3},
+		{name: "length with unknown operator", operator: OpArrayLength, value: ArrayLength{Operator: "between", Length: 3}},
+		{name: "json length missing field", operator: OpArrayLength, value: map[string]any{"operator": "gt"}},
+		{name: "json length unknown field", operator: OpArrayLength, value: map[string]any{"operator": "gt", "length": 2, "max": 3}},
+		{name: "json length fraction", operator: OpArrayLength, value: map[string]any{"operator": "gt", "length": 2.5}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			builder := NewSQLBuilder()
+			assert.Equal(t, "", builder.buildCondition("tags", tt.operator, tt.value))
+			assert.Equal(t, 0, len(builder.GetParams()))
+			assert.ErrorIs(t, builder.Err(), ErrInvalidValue)
+		})
+	}
+}
@@ -0,0 +1,169 @@
+package sqlbuilder
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Relative date operators
+// They resolve against the builder clock and location (see SetClock and SetLocation)
+// and always compile to a range on the bare column, e.g. (created_at >= ? AND created_at < ?),
+// so indexes on the column stay usable.
+const (
+	OpWithinLast   = "within_last"   // Value is a window such as "30m", "24h", "7d" or "2w"
+	OpOnDate       = "on_date"       // Value is a date "2006-01-02" or a time.Time
+	OpInPeriod     = "in_period"     // Value is a calendar period such as PeriodThisMonth
+	OpBeforePeriod = "before_period" // Before the start of the calendar period
+	OpAfterPeriod  = "after_period"  // At or after the end of the calendar period
+)
+
+// Calendar periods, weeks start on Monday
+const (
+	PeriodToday       = "today"
+	PeriodYesterday   = "yesterday"
+	PeriodThisWeek    = "this_week"
+	PeriodLastWeek    = "last_week"
+	PeriodThisMonth   = "this_month"
+	PeriodLastMonth   = "last_month"
+	PeriodThisQuarter = "this_quarter"
+	PeriodLastQuarter = "last_quarter"
+	PeriodThisYear    = "this_year"
+	PeriodLastYear    = "last_year"
+)
+
+// SetClock sets the function returning the current time, time.Now by default
+func (s *SQLBuilder) SetClock(now func() time.Time) {
+	s.now = now
+}
+
+// SetLocation sets the time zone used for dates and calendar periods, UTC by default
+func (s *SQLBuilder) SetLocation(location *time.Location) {
+	s.location = location
+}
+
+// buildDateCondition builds the relative date operators
+func (s *SQLBuilder) buildDateCondition(column, field, operator string, value any) string {
+	start, end, err := s.dateRange(operator, value)
+	if err != nil {
+		s.setErr(err)
+		return ""
+	}
+
+	switch operator {
+	case OpBeforePeriod:
+		return fmt.Sprintf("%s < %s", column, s.bind(field, start))
+	case OpAfterPeriod:
+		return fmt.Sprintf("%s >= %s", column, s.bind(field, end))
+	}
+	return fmt.Sprintf("(%s >= %s AND %s < %s)", column, s.bind(field, start), column, s.bind(field, end))
+}
+
+// dateRange resolves the operator value to a half-open [start, end) range
+func (s *SQLBuilder) dateRange(operator string, value any) (time.Time, time.Time, error) {
+	now := s.currentTime()
+
+	switch operator {
+	case OpWithinLast:
+		window, err := parseWindow(fmt.Sprint(value))
+		if err != nil {
+			return time.Time{}, time.Time{}, err
+		}
+		return now.Add(-window), now, nil
+	case OpOnDate:
+		var day time.Time
+		switch v := value.(type) {
+		case time.Time:
+			day = v.In(now.Location())
+		default:
+			parsed, err := time.ParseInLocation(time.DateOnly, fmt.Sprint(value), now.Location())
+			if err != nil {
+				return time.Time{}, time.Time{}, fmt.Errorf("%w: %s expects a date, got %v", ErrInvalidValue, operator, value)
+			}
+			day = parsed
+		}
+		start := startOfDay(day)
+		return start, start.AddDate(0, 0, 1), nil
+	}
+	return calendarPeriod(fmt.Sprint(value), now)
+}
+
+// currentTime returns the clock time in the builder location
+func (s *SQLBuilder) currentTime() time.Time {
+	now := time.Now
+	if s.now != nil {
+		now = s.now
+	}
+	location := time.UTC
+	if s.location != nil {
+		location = s.location
+	}
+	return now().In(location)
+}
+
+// calendarPeriod returns the [start, end) range of a named period containing now
+func calendarPeriod(period string, now time.Time) (time.Time, time.Time, error) {
+	today := startOfDay(now)
+	switch period {
+	case PeriodToday:
+		return today, today.AddDate(0, 0, 1), nil
+	case PeriodYesterday:
+		return today.AddDate(0, 0, -1), today, nil
+	case PeriodThisWeek, PeriodLastWeek:
+		monday := today.AddDate(0, 0, -((int(today.Weekday()) + 6) % 7))
+		if period == PeriodLastWeek {
+			monday = monday.AddDate(0, 0, -7)
+		}
+		return monday, monday.AddDate(0, 0, 7), nil
+	case PeriodThisMonth, PeriodLastMonth:
+		first := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+		if period == PeriodLastMonth {
+			first = first.AddDate(0, -1, 0)
+		}
+		return first, first.AddDate(0, 1, 0), nil
+	case PeriodThisQuarter, PeriodLastQuarter:
+		month := time.Month((int(now.Month())-1)/3*3 + 1)
+		first := time.Date(now.Year(), month, 1, 0, 0, 0, 0, now.Location())
+		if period == PeriodLastQuarter {
+			first = first.AddDate(0, -3, 0)
+		}
+		return first, first.AddDate(0, 3, 0), nil
+	case PeriodThisYear, PeriodLastYear:
+		first := time.Date(now.Year(), 1, 1, 0, 0, 0, 0, now.Location())
+		if period == PeriodLastYear {
+			first = first.AddDate(-1, 0, 0)
+		}
+		return first, first.AddDate(1, 0, 0), nil
+	}
+	return time.Time{}, time.Time{}, fmt.Errorf("%w: unknown period %q", ErrInvalidValue, period)
+}
+
+// parseWindow parses a positive window such as "30m", "24h", "7d" or "2w"
+func parseWindow(window string) (time.Duration, error) {
+	units := map[string]time.Duration{
+		"m": time.Minute,
+		"h": time.Hour,
+		"d": 24 * time.Hour,
+		"w": 7 * 24 * time.Hour,
+	}
+
+	window = strings.TrimSpace(window)
+	if len(window) >= 2 {
+		unit, ok := units[window[len(window)-1:]]
+		count, err := strconv.Atoi(window[:len(window)-1])
+		if ok && err == nil && count > 0 {
+			if int64(count) > math.MaxInt64/int64(unit) {
+				return 0, fmt.Errorf("%w: window %q is too large", ErrInvalidValue, window)
+			}
+			return time.Duration(count) * unit, nil
+		}
+	}
+	return 0, fmt.Errorf("%w: invalid window %q", ErrInvalidValue, window)
+}
+
+// startOfDay truncates t to midnight in its location
+func startOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
@@ -0,0 +1,159 @@
+package sqlbuilder
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Test relative date operators against a fixed clock
+func TestSQLBuilder_DateOperators(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skip("time zone database not available")
+	}
+	// Thursday 2024-05-16 01:30 in Berlin, still Wednesday in UTC
+	now := time.Date(2024, 5, 15, 23, 30, 0, 0, time.UTC)
+	date := func(year int, month time.Month, day int) time.Time {
+		return time.Date(year, month, day, 0, 0, 0, 0, berlin)
+	}
+
+	tests := []struct {
+		name           string
+		operator       string
+		value          any
+		expectedSQL    string
+		expectedParams []any
+	}{
+		{
+			name:           "within last hours",
+			operator:       OpWithinLast,
+			value:          "24h",
+			expectedSQL:    "(created_at >= ? AND created_at < ?)",
+			expectedParams: []any{now.Add(-24 * time.Hour).In(berlin), now.In(berlin)},
+		},
+		{
+			name:           "within last weeks",
+			operator:       OpWithinLast,
+			value:          "2w",
+			expectedSQL:    "(created_at >= ? AND created_at < ?)",
+			expectedParams: []any{now.AddDate(0, 0, -14).In(berlin), now.In(berlin)},
+		},
+		{
+			name:           "on date string",
+			operator:       OpOnDate,
+			value:          "2024-02-29",
+			expectedSQL:    "(created_at >= ? AND created_at < ?)",
+			expectedParams: []any{date(2024, 2, 29), date(2024, 3, 1)},
+		},
+		{
+			name:           "on date time value",
+			operator:       OpOnDate,
+			value:          now,
+			expectedSQL:    "(created_at >= ? AND created_at < ?)",
+			expectedParams: []any{date(2024, 5, 16), date(2024, 5, 17)},
+		},
+		{
+			name:           "today uses the location",
+			operator:       OpInPeriod,
+			value:          PeriodToday,
+			expectedSQL:    "(created_at >= ? AND created_at < ?)",
+			expectedParams: []any{date(2024, 5, 16), date(2024, 5, 17)},
+		},
+		{
+			name:           "this week starts monday",
+			operator:       OpInPeriod,
+			value:          PeriodThisWeek,
+			expectedSQL:    "(created_at >= ? AND created_at < ?)",
+			expectedParams: []any{date(2024, 5, 13), date(2024, 5, 20)},
+		},
+		{
+			name:           "last month",
+			operator:       OpInPeriod,
+			value:          PeriodLastMonth,
+			expectedSQL:    "(created_at >= ? AND created_at < ?)",
+			expectedParams: []any{date(2024, 4, 1), date(2024, 5, 1)},
+		},
+		{
+			name:           "this quarter",
+			operator:       OpInPeriod,
+			value:          PeriodThisQuarter,
+			expectedSQL:    "(created_at >= ? AND created_at < ?)",
+			expectedParams: []any{date(2024, 4, 1), date(2024, 7, 1)},
+		},
+		{
+			name:           "last year",
+			operator:       OpInPeriod,
+			value:          PeriodLastYear,
+			expectedSQL:    "(created_at >= ? AND created_at < ?)",
+			expectedParams: []any{date(2023, 1, 1), date(2024, 1, 1)},
+		},
+		{
+			name:           "before yesterday",
+			operator:       OpBeforePeriod,
+			value:          PeriodYesterday,
+			expectedSQL:    "created_at < ?",
+			expectedParams: []any{date(2024, 5, 15)},
+		},
+		{
+			name:           "after this month",
+			operator:       OpAfterPeriod,
+			value:          PeriodThisMonth,
+			expectedSQL:    "created_at >= ?",
+			expectedParams: []any{date(2024, 6, 1)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			builder := NewSQLBuilder()
+			builder.SetClock(func() time.Time { return now })
+			builder.SetLocation(berlin)
+			assert.Equal(t, tt.expectedSQL, builder.buildCondition("created_at", tt.operator, tt.value))
+			params := builder.GetParams()
+			assert.Equal(t, len(tt.expectedParams), len(params))
+			for i := range params {
+				assert.True(t, tt.expectedParams[i].(time.Time).Equal(params[i].(time.Time)), "param %d: %v != %v", i, tt.expectedParams[i], params[i])
+			}
+			assert.NoError(t, builder.Err())
+		})
+	}
+}
+
+// Test invalid relative date values
+func TestSQLBuilder_DateOperatorsInvalid(t *testing.T) {
+	tests := []struct {
+		name     string
+		operator string
+		value    any
+	}{
+		{name: "window without unit", operator: OpWithinLast, value: "7"},
+		{name: "window with unknown unit", operator: OpWithinLast, value: "7y"},
+		{name: "negative window", operator: OpWithinLast, value: "-7d"},
+		{name: "overflowing window", operator: OpWithinLast, value: "99999999999w"},
+		{name: "malformed date", operator: OpOnDate, value: "16/05/2024"},
+		{name: "unknown period", operator: OpInPeriod, value: "next_decade"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			builder := NewSQLBuilder()
+			assert.Equal(t, "", builder.buildCondition("created_at", tt.operator, tt.value))
+			assert.Equal(t, 0, len(builder.GetParams()))
+			assert.ErrorIs(t, builder.Err(), ErrInvalidValue)
+		})
+	}
+}
+
+// Test that the default location is UTC
+func TestSQLBuilder_DateOperatorsDefaultLocation(t *testing.T) {
+	builder := NewSQLBuilder()
+	builder.SetClock(func() time.Time { return time.Date(2024, 5, 15, 23, 30, 0, 0, time.FixedZone("X", 3600)) })
+
+	assert.Equal(t, "(d >= ? AND d < ?)", builder.buildCondition("d", OpInPeriod, PeriodToday))
+	assert.Equal(t, []any{
+		time.Date(2024, 5, 15, 0, 0, 0, 0, time.UTC),
+		time.Date(2024, 5, 16, 0, 0, 0, 0, time.UTC),
+	}, builder.GetParams())
+}
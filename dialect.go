@@ -0,0 +1,137 @@
+package sqlbuilder
+
+import (
+	"errors"
+	"fmt"
+	"regexp/syntax"
+	"strconv"
+)
+
+// Dialect selects the SQL flavour generated by the builder
+type Dialect int
+
+// Supported dialects
+const (
+	DialectMySQL    Dialect = iota // MySQL 8, ? placeholders
+	DialectPostgres                // PostgreSQL, $n placeholders
+)
+
+// ErrInvalidRegex is returned when a regex operator value fails validation
+var ErrInvalidRegex = errors.New("sqlbuilder: invalid regex")
+
+// RegexLimits bounds regex operator patterns before they reach the database
+// Patterns are compiled with Go's RE2 parser, so constructs RE2 does not support
+// (backreferences, lookaround) are rejected even where the database would accept them.
+// Nested unbounded repetition such as (a+)+ is always rejected because it is the
+// usual source of catastrophic backtracking in MySQL and PostgreSQL regex engines.
+type RegexLimits struct {
+	MaxLength     int // Maximum pattern length in bytes, 0 means unlimited
+	MaxComplexity int // Maximum number of nodes in the parsed pattern, 0 means unlimited
+}
+
+// SetDialect sets the dialect used for conditions built afterwards
+func (s *SQLBuilder) SetDialect(dialect Dialect) {
+	s.dialect = dialect
+}
+
+// SetRegexLimits enables server side validation of regex operator values
+// Passing nil disables validation, which is the default. Invalid patterns are left
+// out of the generated SQL and reported through Err.
+func (s *SQLBuilder) SetRegexLimits(limits *RegexLimits) {
+	s.regexLimits = limits
+}
+
+// ValidateRegex checks that pattern compiles as RE2 and stays within limits
+func ValidateRegex(pattern string, limits RegexLimits) error {
+	if limits.MaxLength > 0 && len(pattern) > limits.MaxLength {
+		return fmt.Errorf("%w: pattern length %d exceeds %d", ErrInvalidRegex, len(pattern), limits.MaxLength)
+	}
+
+	re, err := syntax.Parse(pattern, syntax.Perl)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidRegex, err)
+	}
+
+	nodes := 0
+	nested := false
+	walkRegex(re, 0, func(node *syntax.Regexp, repeatDepth int) {
+		nodes++
+		if isUnboundedRepeat(node) && repeatDepth > 0 {
+			nested = true
+		}
+	})
+
+	if nested {
+		return fmt.Errorf("%w: nested repetition in %q", ErrInvalidRegex, pattern)
+	}
+	if limits.MaxComplexity > 0 && nodes > limits.MaxComplexity {
+		return fmt.Errorf("%w: pattern complexity %d exceeds %d", ErrInvalidRegex, nodes, limits.MaxComplexity)
+	}
+	return nil
+}
+
+// buildRegexCondition builds a regex match for the builder dialect
+// MySQL uses REGEXP_LIKE with the 'i' match type for the case-insensitive operators,
+// so the result no longer depends on the column collation.
+func (s *SQLBuilder) buildRegexCondition(field, operator string, value any) string {
+	if s.regexLimits != nil {
+		if err := ValidateRegex(fmt.Sprint(value), *s.regexLimits); err != nil {
+			s.setErr(err)
+			return ""
+		}
+	}
+
+	placeholder := s.bind(field, value)
+	if s.dialect == DialectPostgres {
+		switch operator {
+		case OpIRegex:
+			return fmt.Sprintf("%s ~* %s", field, placeholder)
+		case OpNotRegex:
+			return fmt.Sprintf("%s !~ %s", field, placeholder)
+		case OpNotIRegex:
+			return fmt.Sprintf("%s !~* %s", field, placeholder)
+		}
+		return fmt.Sprintf("%s ~ %s", field, placeholder)
+	}
+
+	switch operator {
+	case OpIRegex:
+		return fmt.Sprintf("REGEXP_LIKE(%s, %s, 'i')", field, placeholder)
+	case OpNotRegex:
+		return fmt.Sprintf("%s NOT REGEXP %s", field, placeholder)
+	case OpNotIRegex:
+		return fmt.Sprintf("NOT REGEXP_LIKE(%s, %s, 'i')", field, placeholder)
+	}
+	return fmt.Sprintf("%s REGEXP %s", field, placeholder)
+}
+
+// positionalPlaceholder returns the placeholder for the next positional parameter
+func (s *SQLBuilder) positionalPlaceholder() string {
+	s.paramIndex++
+	if s.dialect == DialectPostgres {
+		return "$" + strconv.Itoa(s.paramIndex)
+	}
+	return "?"
+}
+
+// walkRegex visits every node of a parsed pattern with the number of enclosing unbounded repeats
+func walkRegex(re *syntax.Regexp, repeatDepth int, fn func(*syntax.Regexp, int)) {
+	fn(re, repeatDepth)
+	if isUnboundedRepeat(re) {
+		repeatDepth++
+	}
+	for _, sub := range re.Sub {
+		walkRegex(sub, repeatDepth, fn)
+	}
+}
+
+// isUnboundedRepeat returns true for *, + and {n,} repetitions
+func isUnboundedRepeat(re *syntax.Regexp) bool {
+	switch re.Op {
+	case syntax.OpStar, syntax.OpPlus:
+		return true
+	case syntax.OpRepeat:
+		return re.Max == -1
+	}
+	return false
+}
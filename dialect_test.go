@@ -0,0 +1,96 @@
+package sqlbuilder
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Test Postgres dialect rendering
+func TestSQLBuilder_PostgresDialect(t *testing.T) {
+	tests := []struct {
+		name        string
+		operator    string
+		value       any
+		expectedSQL string
+	}{
+		{name: "OpEqual", operator: OpEqual, value: "a", expectedSQL: "title = $1"},
+		{name: "OpRegex", operator: OpRegex, value: "^a", expectedSQL: "title ~ $1"},
+		{name: "OpIRegex", operator: OpIRegex, value: "^a", expectedSQL: "title ~* $1"},
+		{name: "OpNotRegex", operator: OpNotRegex, value: "^a", expectedSQL: "title !~ $1"},
+		{name: "OpNotIRegex", operator: OpNotIRegex, value: "^a", expectedSQL: "title !~* $1"},
+		{name: "OpFullText", operator: OpFullText, value: "go", expectedSQL: "to_tsvector(title) @@ plainto_tsquery($1)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			builder := NewSQLBuilder()
+			builder.SetDialect(DialectPostgres)
+			assert.Equal(t, tt.expectedSQL, builder.buildCondition("title", tt.operator, tt.value))
+			assert.Equal(t, []any{tt.value}, builder.GetParams())
+		})
+	}
+
+	t.Run("placeholders are numbered across conditions", func(t *testing.T) {
+		builder := NewSQLBuilder()
+		builder.SetDialect(DialectPostgres)
+		result := builder.BuildFilterConditions([]FilterCriteria{
+			{Field: "status", Operator: OpEqual, Value: "active"},
+			{Field: "id", Operator: OpIn, Value: []any{1, 2}},
+			{Field: "name", Operator: OpIRegex, Value: "^jo"},
+		})
+		assert.Equal(t, "status = $1 AND id IN ($2, $3) AND name ~* $4", result)
+		assert.Equal(t, []any{"active", 1, 2, "^jo"}, builder.GetParams())
+	})
+
+	t.Run("named style ignores numbering", func(t *testing.T) {
+		builder := NewNamedSQLBuilder()
+		builder.SetDialect(DialectPostgres)
+		assert.Equal(t, "name ~* :p_name_1", builder.buildCondition("name", OpIRegex, "^jo"))
+	})
+}
+
+// Test regex validation
+func TestValidateRegex(t *testing.T) {
+	limits := RegexLimits{MaxLength: 20, MaxComplexity: 10}
+
+	assert.NoError(t, ValidateRegex("^[a-z]+$", limits))
+	assert.NoError(t, ValidateRegex("(ab)+c*", RegexLimits{}))
+
+	tests := []struct {
+		name    string
+		pattern string
+		limits  RegexLimits
+	}{
+		{name: "does not compile", pattern: "([a-z]", limits: limits},
+		{name: "too long", pattern: "abcdefghijklmnopqrstuvwxyz", limits: limits},
+		{name: "too complex", pattern: "a.b.c.d.e.f", limits: limits},
+		{name: "nested repetition", pattern: "(a+)+$", limits: RegexLimits{}},
+		{name: "nested open repeat", pattern: "(a|b{2,})*", limits: RegexLimits{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.ErrorIs(t, ValidateRegex(tt.pattern, tt.limits), ErrInvalidRegex)
+		})
+	}
+}
+
+// Test regex validation on the builder
+func TestSQLBuilder_RegexLimits(t *testing.T) {
+	builder := NewSQLBuilder()
+	builder.SetRegexLimits(&RegexLimits{MaxLength: 50})
+
+	result := builder.BuildFilterConditions([]FilterCriteria{
+		{Field: "code", Operator: OpRegex, Value: "^[A-Z]{3}$"},
+		{Field: "name", Operator: OpIRegex, Value: "(x+x+)+y"},
+	})
+	assert.Equal(t, "code REGEXP ?", result)
+	assert.Equal(t, []any{"^[A-Z]{3}$"}, builder.GetParams())
+	assert.ErrorIs(t, builder.Err(), ErrInvalidRegex)
+
+	// Validation is off by default
+	builder = NewSQLBuilder()
+	assert.Equal(t, "REGEXP_LIKE(name, ?, 'i')", builder.buildCondition("name", OpIRegex, "(x+x+)+y"))
+	assert.NoError(t, builder.Err())
+}
@@ -0,0 +1,740 @@
+package sqlbuilder
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrUnknownField is returned by Evaluate when a criterion names a field the items do not have
+var ErrUnknownField = errors.New("sqlbuilder: unknown field")
+
+// ErrUnsupportedOperator is returned by Evaluate for operators that only exist in the database
+var ErrUnsupportedOperator = errors.New("sqlbuilder: operator not supported in memory")
+
+// earthRadiusMeters matches the default sphere radius of MySQL ST_Distance_Sphere
+const earthRadiusMeters = 6370986
+
+// truth is the result of evaluating a condition with SQL three-valued logic
+type truth int
+
+const (
+	truthOmitted truth = iota // The condition renders no SQL and is left out
+	truthFalse
+	truthUnknown // SQL NULL
+	truthTrue
+)
+
+// Evaluate applies params to in-memory items with the same semantics as the generated SQL
+// Search groups, filters, sorting and pagination are applied in that order, and the
+// requested page is returned together with its PaginationMeta.
+//
+// Fields are looked up by the db struct tag, then the json tag, then the Go field name
+// (case-insensitively), or by key for map[string]any items. Dotted fields walk nested
+// structs and maps; a table prefix such as u.name falls back to name.
+//
+// Nil pointers, nil interfaces and invalid sql.Null* values are NULL: every comparison
+// with NULL is unknown, NOT unknown stays unknown, and only rows whose condition is true
+// are kept. Comparisons and LIKE are case-sensitive, like the documented operators.
+// Sorting places NULLs first in ascending order, as MySQL does. Relative date operators
+// resolve against time.Now in UTC. Full-text, JSON and raw SQL conditions cannot be
+// evaluated and return ErrUnsupportedOperator.
+func Evaluate[T any](items []T, params *AdvancedQueryParams) ([]T, *PaginationMeta, error) {
+	if err := params.Validate(); err != nil {
+		return nil, nil, err
+	}
+
+	ev := newEvaluator()
+	expr := params.Expr()
+
+	matched := make([]T, 0, len(items))
+	for _, item := range items {
+		result, err := ev.eval(expr, reflect.ValueOf(item))
+		if err != nil {
+			return nil, nil, err
+		}
+		if result == truthTrue || result == truthOmitted {
+			matched = append(matched, item)
+		}
+	}
+
+	if err := evaluateSort(ev, matched, params.Sort); err != nil {
+		return nil, nil, err
+	}
+
+	pagination := NewPaginationParams(params.Pagination.Page, params.Pagination.Limit)
+	start := min(pagination.Offset, len(matched))
+	end := min(start+pagination.Limit, len(matched))
+	meta := CalculatePaginationMeta(len(matched), pagination.Page, pagination.Limit)
+	return matched[start:end], meta, nil
+}
+
+// evaluator holds per call caches
+type evaluator struct {
+	builder *SQLBuilder
+	regexps map[string]*regexp.Regexp
+	fields  map[reflect.Type]map[string]int
+}
+
+func newEvaluator() *evaluator {
+	return &evaluator{
+		builder: NewSQLBuilder(),
+		regexps: make(map[string]*regexp.Regexp),
+		fields:  make(map[reflect.Type]map[string]int),
+	}
+}
+
+// eval evaluates an expression against one item
+func (ev *evaluator) eval(e Expr, item reflect.Value) (truth, error) {
+	switch node := e.(type) {
+	case AndExpr:
+		return ev.combine(node.exprs, item, truthFalse)
+	case OrExpr:
+		return ev.combine(node.exprs, item, truthTrue)
+	case NotExpr:
+		result, err := ev.eval(node.expr, item)
+		return negate(result), err
+	case CmpExpr:
+		return ev.compare(item, node.field, node.operator, node.value)
+	case InExpr:
+		if len(node.values) == 0 {
+			return truthOmitted, nil
+		}
+		result, err := ev.in(item, node.field, node.values)
+		if node.negated {
+			result = negate(result)
+		}
+		return result, err
+	case RawExpr:
+		return truthFalse, fmt.Errorf("%w: raw SQL", ErrUnsupportedOperator)
+	case invalidExpr:
+		return truthFalse, node.err
+	}
+	return truthOmitted, nil
+}
+
+// combine applies AND (dominant false) or OR (dominant true) to the children, skipping omitted ones
+func (ev *evaluator) combine(exprs []Expr, item reflect.Value, dominant truth) (truth, error) {
+	result := truthOmitted
+	for _, child := range exprs {
+		childResult, err := ev.eval(child, item)
+		if err != nil {
+			return truthFalse, err
+		}
+		switch {
+		case childResult == truthOmitted:
+			continue
+		case childResult == dominant:
+			return dominant, nil
+		case childResult == truthUnknown || result == truthOmitted:
+			result = childResult
+		}
+	}
+	return result, nil
+}
+
+// negate applies NOT with three-valued logic
+func negate(result truth) truth {
+	switch result {
+	case truthTrue:
+		return truthFalse
+	case truthFalse:
+		return truthTrue
+	}
+	return result
+}
+
+// compare evaluates a single criterion
+func (ev *evaluator) compare(item reflect.Value, field, operator string, value any) (truth, error) {
+	switch operator {
+	case OpFullText, OpFullTextBoolean, OpFullTextExpansion, OpJSONContains, OpJSONHasKey:
+		return truthFalse, fmt.Errorf("%w: %s", ErrUnsupportedOperator, operator)
+	case OpIn, OpNotIn:
+		// Only reached for non-list values, which render no SQL
+		return truthOmitted, nil
+	}
+
+	if !IsOperator(operator) {
+		// Unknown operators render no SQL
+		return truthOmitted, nil
+	}
+
+	actual, err := ev.fieldValue(item, field)
+	if err != nil {
+		return truthFalse, err
+	}
+
+	switch operator {
+	case OpIsNull:
+		return truthOf(actual == nil), nil
+	case OpIsNotNull:
+		return truthOf(actual != nil), nil
+	}
+
+	if symbol, ok := comparisonOperators[operator]; ok {
+		if actual == nil || value == nil {
+			return truthUnknown, nil
+		}
+		return truthOf(orderSatisfies(symbol, compareValues(actual, value))), nil
+	}
+
+	if pattern, caseInsensitive, negated, ok := likePattern(operator, value); ok {
+		if actual == nil || value == nil {
+			return truthUnknown, nil
+		}
+		text := fmt.Sprint(actual)
+		if caseInsensitive {
+			text, pattern = strings.ToLower(text), strings.ToLower(pattern)
+		}
+		re, err := ev.regexp(likeExpression(pattern))
+		if err != nil {
+			return truthFalse, err
+		}
+		return truthOf(re.MatchString(text) != negated), nil
+	}
+
+	switch operator {
+	case OpRegex, OpIRegex, OpNotRegex, OpNotIRegex:
+		if actual == nil || value == nil {
+			return truthUnknown, nil
+		}
+		pattern := fmt.Sprint(value)
+		if operator == OpIRegex || operator == OpNotIRegex {
+			pattern = "(?i)" + pattern
+		}
+		re, err := ev.regexp(pattern)
+		if err != nil {
+			return truthFalse, err
+		}
+		negated := operator == OpNotRegex || operator == OpNotIRegex
+		return truthOf(re.MatchString(fmt.Sprint(actual)) != negated), nil
+	case OpArrayContains, OpArrayContainedBy, OpArrayOverlaps, OpArrayLength:
+		if actual == nil {
+			return truthUnknown, nil
+		}
+		return evaluateArray(actual, operator, value)
+	case OpWithinRadius, OpWithinBBox:
+		if actual == nil {
+			return truthUnknown, nil
+		}
+		return evaluateGeo(actual, operator, value)
+	case OpWithinLast, OpOnDate, OpInPeriod, OpBeforePeriod, OpAfterPeriod:
+		start, end, err := ev.builder.dateRange(operator, value)
+		if err != nil {
+			return truthFalse, err
+		}
+		if actual == nil {
+			return truthUnknown, nil
+		}
+		switch operator {
+		case OpBeforePeriod:
+			return truthOf(compareValues(actual, start) < 0), nil
+		case OpAfterPeriod:
+			return truthOf(compareValues(actual, end) >= 0), nil
+		}
+		return truthOf(compareValues(actual, start) >= 0 && compareValues(actual, end) < 0), nil
+	}
+	return truthOmitted, nil
+}
+
+// in evaluates field IN (values) with SQL NULL semantics
+func (ev *evaluator) in(item reflect.Value, field string, values []any) (truth, error) {
+	actual, err := ev.fieldValue(item, field)
+	if err != nil || actual == nil {
+		return truthUnknown, err
+	}
+
+	result := truthFalse
+	for _, value := range values {
+		if value == nil {
+			result = truthUnknown
+			continue
+		}
+		if compareValues(actual, value) == 0 {
+			return truthTrue, nil
+		}
+	}
+	return result, nil
+}
+
+// regexp compiles and caches a pattern
+func (ev *evaluator) regexp(pattern string) (*regexp.Regexp, error) {
+	if re, ok := ev.regexps[pattern]; ok {
+		return re, nil
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidRegex, err)
+	}
+	ev.regexps[pattern] = re
+	return re, nil
+}
+
+// fieldValue resolves a field on an item, returning nil for NULL
+func (ev *evaluator) fieldValue(item reflect.Value, field string) (any, error) {
+	if value, ok := ev.lookup(item, field); ok {
+		return value, nil
+	}
+
+	current := item
+	for i, segment := range strings.Split(strings.ReplaceAll(field, "->", "."), ".") {
+		value, ok := ev.lookup(current, segment)
+		if !ok && i > 0 && indirect(current).Kind() == reflect.Map {
+			// Missing keys in nested documents are NULL, like JSON extraction in SQL
+			return nil, nil
+		}
+		if !ok {
+			// Fall back to the last segment for table qualified fields
+			if index := strings.LastIndex(field, "."); index >= 0 {
+				if value, ok := ev.lookup(item, field[index+1:]); ok {
+					return value, nil
+				}
+			}
+			return nil, fmt.Errorf("%w: %s", ErrUnknownField, field)
+		}
+		if value == nil {
+			return nil, nil
+		}
+		current = reflect.ValueOf(value)
+	}
+	return normalizeValue(current), nil
+}
+
+// lookup resolves one name on a struct or map
+func (ev *evaluator) lookup(item reflect.Value, name string) (any, bool) {
+	item = indirect(item)
+	if !item.IsValid() {
+		return nil, false
+	}
+
+	switch item.Kind() {
+	case reflect.Map:
+		if item.Type().Key().Kind() != reflect.String {
+			return nil, false
+		}
+		value := item.MapIndex(reflect.ValueOf(name).Convert(item.Type().Key()))
+		if !value.IsValid() {
+			return nil, false
+		}
+		return normalizeValue(value), true
+	case reflect.Struct:
+		index, ok := ev.structFields(item.Type())[strings.ToLower(name)]
+		if !ok {
+			return nil, false
+		}
+		return normalizeValue(item.Field(index)), true
+	}
+	return nil, false
+}
+
+// structFields maps lowercase db tags, json tags and field names to field indexes
+func (ev *evaluator) structFields(t reflect.Type) map[string]int {
+	if fields, ok := ev.fields[t]; ok {
+		return fields
+	}
+
+	fields := make(map[string]int)
+	// Lower priority names first, so tags win
+	for pass := 0; pass < 3; pass++ {
+		for i := 0; i < t.NumField(); i++ {
+			structField := t.Field(i)
+			if !structField.IsExported() {
+				continue
+			}
+			name := ""
+			switch pass {
+			case 0:
+				name = structField.Name
+			case 1:
+				name = strings.Split(structField.Tag.Get("json"), ",")[0]
+			case 2:
+				name = strings.Split(structField.Tag.Get("db"), ",")[0]
+			}
+			if name != "" && name != "-" {
+				fields[strings.ToLower(name)] = i
+			}
+		}
+	}
+	ev.fields[t] = fields
+	return fields
+}
+
+// evaluateSort sorts items in place, NULLs first in ascending order
+func evaluateSort[T any](ev *evaluator, items []T, sortCriteria []SortCriteria) error {
+	var criteria []SortCriteria
+	for _, criterion := range sortCriteria {
+		// Relevance only exists for full-text queries, which cannot be evaluated
+		if criterion.Field != SortRelevance {
+			criteria = append(criteria, criterion)
+		}
+	}
+	if len(criteria) == 0 || len(items) < 2 {
+		return nil
+	}
+
+	keys := make([][]any, len(items))
+	for i, item := range items {
+		keys[i] = make([]any, len(criteria))
+		for j, criterion := range criteria {
+			value, err := ev.fieldValue(reflect.ValueOf(item), criterion.Field)
+			if err != nil {
+				return err
+			}
+			if criterion.Near != nil && value != nil {
+				point, ok := geoPointValue(value)
+				if !ok {
+					return fmt.Errorf("%w: %s is not a GeoPoint", ErrInvalidValue, criterion.Field)
+				}
+				value = haversine(point, *criterion.Near)
+			}
+			keys[i][j] = value
+		}
+	}
+
+	order := make([]int, len(items))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		for j, criterion := range criteria {
+			result := compareNullable(keys[order[a]][j], keys[order[b]][j])
+			if strings.ToLower(criterion.Order) == SortDesc {
+				result = -result
+			}
+			if result != 0 {
+				return result < 0
+			}
+		}
+		return false
+	})
+
+	sorted := make([]T, len(items))
+	for i, index := range order {
+		sorted[i] = items[index]
+	}
+	copy(items, sorted)
+	return nil
+}
+
+// evaluateArray evaluates the array operators against a slice field
+func evaluateArray(actual any, operator string, value any) (truth, error) {
+	elements, ok := sliceValues(actual)
+	if !ok {
+		return truthFalse, fmt.Errorf("%w: %s needs a slice field, got %T", ErrInvalidValue, operator, actual)
+	}
+
+	if operator == OpArrayLength {
+		length, ok := structValue[ArrayLength](value)
+		symbol, known := comparisonOperators[length.Operator]
+		if !ok || !known {
+			return truthFalse, fmt.Errorf("%w: %s expects an ArrayLength with a comparison operator", ErrInvalidValue, operator)
+		}
+		return truthOf(orderSatisfies(symbol, compareValues(len(elements), length.Length))), nil
+	}
+
+	wanted, isSlice := sliceValues(value)
+	if !isSlice {
+		if operator != OpArrayContains {
+			return truthFalse, fmt.Errorf("%w: %s expects a slice, got %T", ErrInvalidValue, operator, value)
+		}
+		wanted = []any{value}
+	}
+
+	switch operator {
+	case OpArrayContainedBy:
+		return truthOf(containsAll(wanted, elements)), nil
+	case OpArrayOverlaps:
+		for _, element := range elements {
+			if containsValue(wanted, element) {
+				return truthTrue, nil
+			}
+		}
+		return truthFalse, nil
+	}
+	return truthOf(containsAll(elements, wanted)), nil
+}
+
+// evaluateGeo evaluates the geospatial operators against a GeoPoint field
+func evaluateGeo(actual any, operator string, value any) (truth, error) {
+	point, ok := geoPointValue(actual)
+	if !ok {
+		return truthFalse, fmt.Errorf("%w: %s needs a GeoPoint field, got %T", ErrInvalidValue, operator, actual)
+	}
+
+	if operator == OpWithinRadius {
+		radius, ok := structValue[GeoRadius](value)
+		if !ok {
+			return truthFalse, fmt.Errorf("%w: %s expects a GeoRadius, got %T", ErrInvalidValue, operator, value)
+		}
+		if err := radius.Validate(); err != nil {
+			return truthFalse, err
+		}
+		return truthOf(haversine(point, GeoPoint{Lat: radius.Lat, Lng: radius.Lng}) <= radius.RadiusKm*1000), nil
+	}
+
+	box, ok := structValue[GeoBBox](value)
+	if !ok {
+		return truthFalse, fmt.Errorf("%w: %s expects a GeoBBox, got %T", ErrInvalidValue, operator, value)
+	}
+	if err := box.Validate(); err != nil {
+		return truthFalse, err
+	}
+	return truthOf(point.Lat >= box.MinLat && point.Lat <= box.MaxLat && point.Lng >= box.MinLng && point.Lng <= box.MaxLng), nil
+}
+
+// likePattern returns the LIKE pattern the builder would bind for a LIKE based operator
+func likePattern(operator string, value any) (pattern string, caseInsensitive, negated, ok bool) {
+	switch operator {
+	case OpContains, OpIContains, OpNotContains, OpNotIContains:
+		pattern = fmt.Sprintf("%%%v%%", value)
+	case OpStartsWith, OpIStartsWith, OpNotStartsWith, OpNotIStartsWith:
+		pattern = fmt.Sprintf("%v%%", value)
+	case OpEndsWith, OpIEndsWith, OpNotEndsWith, OpNotIEndsWith:
+		pattern = fmt.Sprintf("%%%v", value)
+	case OpLike, OpILike, OpNotLike, OpNotILike:
+		pattern = fmt.Sprint(value)
+	default:
+		return "", false, false, false
+	}
+
+	switch operator {
+	case OpIContains, OpIStartsWith, OpIEndsWith, OpILike:
+		caseInsensitive = true
+	case OpNotContains, OpNotStartsWith, OpNotEndsWith, OpNotLike:
+		negated = true
+	case OpNotIContains, OpNotIStartsWith, OpNotIEndsWith, OpNotILike:
+		caseInsensitive, negated = true, true
+	}
+	return pattern, caseInsensitive, negated, true
+}
+
+// likeExpression converts a SQL LIKE pattern with % and _ wildcards into a regular expression
+func likeExpression(pattern string) string {
+	var expression strings.Builder
+	expression.WriteString(`(?s)^`)
+	for _, r := range pattern {
+		switch r {
+		case '%':
+			expression.WriteString(".*")
+		case '_':
+			expression.WriteString(".")
+		default:
+			expression.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	expression.WriteString("$")
+	return expression.String()
+}
+
+// orderSatisfies reports whether a comparison result satisfies a SQL comparison symbol
+func orderSatisfies(symbol string, order int) bool {
+	switch symbol {
+	case "=":
+		return order == 0
+	case "!=":
+		return order != 0
+	case ">":
+		return order > 0
+	case ">=":
+		return order >= 0
+	case "<":
+		return order < 0
+	}
+	return order <= 0
+}
+
+// compareValues orders two non-NULL values
+// When either side is a time.Time both compare chronologically (strings are parsed as
+// RFC 3339 or dates), when either side is a number or bool both compare numerically
+// (numeric strings included), and anything else compares by its string form.
+func compareValues(a, b any) int {
+	_, aIsTime := a.(time.Time)
+	_, bIsTime := b.(time.Time)
+	if aIsTime || bIsTime {
+		at, aOK := timeValue(a)
+		bt, bOK := timeValue(b)
+		if aOK && bOK {
+			return at.Compare(bt)
+		}
+	}
+	if ar, aOK := numericValue(a); aOK {
+		if br, bOK := numericValue(b); bOK {
+			// Go numbers compare exactly, integers beyond 2^53 included
+			return ar.Cmp(br)
+		}
+	}
+	if isNumberKind(a) || isNumberKind(b) {
+		af, aOK := numberValue(a)
+		bf, bOK := numberValue(b)
+		if aOK && bOK {
+			switch {
+			case af < bf:
+				return -1
+			case af > bf:
+				return 1
+			}
+			return 0
+		}
+	}
+	return strings.Compare(fmt.Sprint(a), fmt.Sprint(b))
+}
+
+// compareNullable orders values with NULL before everything else
+func compareNullable(a, b any) int {
+	switch {
+	case a == nil && b == nil:
+		return 0
+	case a == nil:
+		return -1
+	case b == nil:
+		return 1
+	}
+	return compareValues(a, b)
+}
+
+// isNumberKind returns true for numeric and bool values
+func isNumberKind(value any) bool {
+	switch reflect.ValueOf(value).Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64, reflect.Bool:
+		return true
+	}
+	return false
+}
+
+// numberValue converts numeric values, numeric strings and booleans to float64
+func numberValue(value any) (float64, bool) {
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(v.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), true
+	case reflect.Bool:
+		if v.Bool() {
+			return 1, true
+		}
+		return 0, true
+	case reflect.String:
+		f, err := strconv.ParseFloat(strings.TrimSpace(v.String()), 64)
+		return f, err == nil
+	}
+	return 0, false
+}
+
+// timeValue converts time.Time values and RFC 3339 or date strings
+func timeValue(value any) (time.Time, bool) {
+	switch v := value.(type) {
+	case time.Time:
+		return v, true
+	case string:
+		for _, layout := range []string{time.RFC3339Nano, time.DateTime, time.DateOnly} {
+			if t, err := time.Parse(layout, v); err == nil {
+				return t, true
+			}
+		}
+	}
+	return time.Time{}, false
+}
+
+// normalizeValue dereferences pointers and driver.Valuer values, returning nil for NULL
+func normalizeValue(value reflect.Value) any {
+	value = indirect(value)
+	if !value.IsValid() {
+		return nil
+	}
+	result := value.Interface()
+	if valuer, ok := result.(driver.Valuer); ok {
+		driverValue, err := valuer.Value()
+		if err != nil {
+			return nil
+		}
+		return driverValue
+	}
+	return result
+}
+
+// indirect dereferences pointers and interfaces, returning an invalid value for nil
+func indirect(value reflect.Value) reflect.Value {
+	for value.IsValid() && (value.Kind() == reflect.Pointer || value.Kind() == reflect.Interface) {
+		if value.IsNil() {
+			return reflect.Value{}
+		}
+		value = value.Elem()
+	}
+	return value
+}
+
+// sliceValues converts a slice or array (other than []byte) into []any
+func sliceValues(value any) ([]any, bool) {
+	if !isSliceValue(value) {
+		return nil, false
+	}
+	v := reflect.ValueOf(value)
+	values := make([]any, v.Len())
+	for i := range values {
+		values[i] = v.Index(i).Interface()
+	}
+	return values, true
+}
+
+// containsAll returns true if every wanted value is in values
+func containsAll(values, wanted []any) bool {
+	for _, w := range wanted {
+		if !containsValue(values, w) {
+			return false
+		}
+	}
+	return true
+}
+
+// containsValue returns true if value equals one of values
+func containsValue(values []any, value any) bool {
+	for _, v := range values {
+		if v != nil && value != nil && compareValues(v, value) == 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// geoPointValue accepts a GeoPoint by value or pointer
+func geoPointValue(value any) (GeoPoint, bool) {
+	switch v := value.(type) {
+	case GeoPoint:
+		return v, true
+	case *GeoPoint:
+		if v != nil {
+			return *v, true
+		}
+	}
+	return GeoPoint{}, false
+}
+
+// haversine returns the great-circle distance in meters
+func haversine(a, b GeoPoint) float64 {
+	toRadians := func(degrees float64) float64 { return degrees * math.Pi / 180 }
+	dLat := toRadians(b.Lat - a.Lat)
+	dLng := toRadians(b.Lng - a.Lng)
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(toRadians(a.Lat))*math.Cos(toRadians(b.Lat))*math.Sin(dLng/2)*math.Sin(dLng/2)
+	return 2 * earthRadiusMeters * math.Asin(math.Sqrt(h))
+}
+
+// truthOf converts a bool to a truth value
+func truthOf(condition bool) truth {
+	if condition {
+		return truthTrue
+	}
+	return truthFalse
+}
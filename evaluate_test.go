@@ -0,0 +1,245 @@
+package sqlbuilder
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type evaluateProduct struct {
+	ID        int            `db:"id"`
+	Name      string         `json:"name"`
+	Category  *string        `db:"category"`
+	Price     float64        `db:"price"`
+	Tags      []string       `db:"tags"`
+	Note      sql.NullString `db:"note"`
+	Location  GeoPoint       `db:"location"`
+	CreatedAt time.Time      `db:"created_at"`
+	Metadata  map[string]any `db:"metadata"`
+}
+
+func evaluateProducts() []evaluateProduct {
+	books, games := "books", "games"
+	return []evaluateProduct{
+		{ID: 1, Name: "Go Programming", Category: &books, Price: 30, Tags: []string{"go", "dev"}, Note: sql.NullString{String: "bestseller", Valid: true}, Location: GeoPoint{Lat: 52.52, Lng: 13.405}, CreatedAt: time.Now().Add(-time.Hour), Metadata: map[string]any{"color": "red"}},
+		{ID: 2, Name: "SQL Basics", Category: &books, Price: 20, Tags: []string{"sql"}, Location: GeoPoint{Lat: 48.137, Lng: 11.575}, CreatedAt: time.Now().AddDate(0, 0, -30), Metadata: map[string]any{}},
+		{ID: 3, Name: "Chess", Category: &games, Price: 15, Tags: []string{}, Location: GeoPoint{Lat: 52.50, Lng: 13.40}, CreatedAt: time.Now().AddDate(-1, 0, 0)},
+		{ID: 4, Name: "go kart", Price: 200, Tags: []string{"go", "outdoor"}, Location: GeoPoint{Lat: 40.71, Lng: -74.0}, CreatedAt: time.Now().AddDate(0, 0, -2)},
+	}
+}
+
+func evaluateIDs(items []evaluateProduct) []int {
+	ids := make([]int, len(items))
+	for i, item := range items {
+		ids[i] = item.ID
+	}
+	return ids
+}
+
+// Test Evaluate filtering for every operator family
+func TestEvaluate_Operators(t *testing.T) {
+	tests := []struct {
+		name     string
+		filter   FilterCriteria
+		expected []int
+	}{
+		{name: "eq", filter: FilterCriteria{Field: "id", Operator: OpEqual, Value: 2}, expected: []int{2}},
+		{name: "numeric string", filter: FilterCriteria{Field: "price", Operator: OpGreaterThan, Value: "25"}, expected: []int{1, 4}},
+		{name: "ne skips NULL", filter: FilterCriteria{Field: "category", Operator: OpNotEqual, Value: "games"}, expected: []int{1, 2}},
+		{name: "lte", filter: FilterCriteria{Field: "price", Operator: OpLessThanEq, Value: 20}, expected: []int{2, 3}},
+		{name: "contains is case-sensitive", filter: FilterCriteria{Field: "name", Operator: OpContains, Value: "go"}, expected: []int{4}},
+		{name: "icontains", filter: FilterCriteria{Field: "name", Operator: OpIContains, Value: "go"}, expected: []int{1, 4}},
+		{name: "not icontains", filter: FilterCriteria{Field: "name", Operator: OpNotIContains, Value: "go"}, expected: []int{2, 3}},
+		{name: "starts with", filter: FilterCriteria{Field: "name", Operator: OpStartsWith, Value: "SQL"}, expected: []int{2}},
+		{name: "ends with", filter: FilterCriteria{Field: "name", Operator: OpIEndsWith, Value: "KART"}, expected: []int{4}},
+		{name: "contains NULL matches nothing", filter: FilterCriteria{Field: "name", Operator: OpContains, Value: nil}, expected: []int{}},
+		{name: "not contains NULL matches nothing", filter: FilterCriteria{Field: "name", Operator: OpNotContains, Value: nil}, expected: []int{}},
+		{name: "like wildcards", filter: FilterCriteria{Field: "name", Operator: OpLike, Value: "_hes%"}, expected: []int{3}},
+		{name: "regex", filter: FilterCriteria{Field: "name", Operator: OpRegex, Value: "^[A-Z]{3} "}, expected: []int{2}},
+		{name: "iregex", filter: FilterCriteria{Field: "name", Operator: OpIRegex, Value: "^go"}, expected: []int{1, 4}},
+		{name: "not regex", filter: FilterCriteria{Field: "name", Operator: OpNotRegex, Value: "o"}, expected: []int{2, 3}},
+		{name: "in", filter: FilterCriteria{Field: "id", Operator: OpIn, Value: []any{1, 3, 5}}, expected: []int{1, 3}},
+		{name: "not in skips NULL", filter: FilterCriteria{Field: "category", Operator: OpNotIn, Value: []any{"games"}}, expected: []int{1, 2}},
+		{name: "not in with NULL value matches nothing", filter: FilterCriteria{Field: "id", Operator: OpNotIn, Value: []any{1, nil}}, expected: []int{}},
+		{name: "is null pointer", filter: FilterCriteria{Field: "category", Operator: OpIsNull}, expected: []int{4}},
+		{name: "is null sql.NullString", filter: FilterCriteria{Field: "note", Operator: OpIsNotNull}, expected: []int{1}},
+		{name: "array contains", filter: FilterCriteria{Field: "tags", Operator: OpArrayContains, Value: "go"}, expected: []int{1, 4}},
+		{name: "array contained by", filter: FilterCriteria{Field: "tags", Operator: OpArrayContainedBy, Value: []string{"go", "dev", "sql"}}, expected: []int{1, 2, 3}},
+		{name: "array overlaps", filter: FilterCriteria{Field: "tags", Operator: OpArrayOverlaps, Value: []string{"sql", "outdoor"}}, expected: []int{2, 4}},
+		{name: "array length", filter: FilterCriteria{Field: "tags", Operator: OpArrayLength, Value: ArrayLength{Operator: OpGreaterThanEq, Length: 2}}, expected: []int{1, 4}},
+		{name: "array length decoded json", filter: FilterCriteria{Field: "tags", Operator: OpArrayLength, Value: map[string]any{"operator": "gte", "length": float64(2)}}, expected: []int{1, 4}},
+		{name: "within radius", filter: FilterCriteria{Field: "location", Operator: OpWithinRadius, Value: GeoRadius{Lat: 52.52, Lng: 13.405, RadiusKm: 5}}, expected: []int{1, 3}},
+		{name: "within bbox", filter: FilterCriteria{Field: "location", Operator: OpWithinBBox, Value: GeoBBox{MinLat: 47, MinLng: 5, MaxLat: 55, MaxLng: 15}}, expected: []int{1, 2, 3}},
+		{name: "within last", filter: FilterCriteria{Field: "created_at", Operator: OpWithinLast, Value: "7d"}, expected: []int{1, 4}},
+		{name: "nested map key", filter: FilterCriteria{Field: "metadata.color", Operator: OpEqual, Value: "red"}, expected: []int{1}},
+		{name: "missing nested key is NULL", filter: FilterCriteria{Field: "metadata.color", Operator: OpIsNull}, expected: []int{2, 3, 4}},
+		{name: "table qualified field", filter: FilterCriteria{Field: "p.id", Operator: OpEqual, Value: 3}, expected: []int{3}},
+		{name: "unknown operator is left out", filter: FilterCriteria{Field: "nope", Operator: "nope", Value: 1}, expected: []int{1, 2, 3, 4}},
+		{name: "empty in is left out", filter: FilterCriteria{Field: "id", Operator: OpIn, Value: []any{}}, expected: []int{1, 2, 3, 4}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			params := NewAdvancedQueryParams()
+			params.Filters = append(params.Filters, tt.filter)
+
+			page, meta, err := Evaluate(evaluateProducts(), params)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, evaluateIDs(page))
+			assert.Equal(t, len(tt.expected), meta.TotalItems)
+		})
+	}
+}
+
+// Test Evaluate logical groups with NULL semantics
+func TestEvaluate_Groups(t *testing.T) {
+	t.Run("or group and filters", func(t *testing.T) {
+		params := NewAdvancedQueryParams()
+		params.AddSearchGroup(LogicOr, []SearchCriteria{
+			CreateSearchCondition("name", OpIContains, "chess"),
+			CreateSearchCondition("name", OpIContains, "go"),
+		})
+		params.Filters = append(params.Filters, CreateFilterCondition("price", OpLessThan, 100))
+
+		page, _, err := Evaluate(evaluateProducts(), params)
+		assert.NoError(t, err)
+		assert.Equal(t, []int{1, 3}, evaluateIDs(page))
+	})
+
+	t.Run("NOT of unknown stays unknown", func(t *testing.T) {
+		params := NewAdvancedQueryParams()
+		params.SearchGroups = append(params.SearchGroups, LogicalGroup{
+			Operator:   LogicAnd,
+			Not:        true,
+			Conditions: []SearchCriteria{CreateSearchCondition("category", OpEqual, "games")},
+		})
+
+		page, _, err := Evaluate(evaluateProducts(), params)
+		assert.NoError(t, err)
+		assert.Equal(t, []int{1, 2}, evaluateIDs(page))
+	})
+
+	t.Run("unknown OR true is true", func(t *testing.T) {
+		params := NewAdvancedQueryParams()
+		params.AddSearchGroup(LogicOr, []SearchCriteria{
+			CreateSearchCondition("category", OpEqual, "books"),
+			CreateSearchCondition("price", OpGreaterThan, 100),
+		})
+
+		page, _, err := Evaluate(evaluateProducts(), params)
+		assert.NoError(t, err)
+		assert.Equal(t, []int{1, 2, 4}, evaluateIDs(page))
+	})
+
+	t.Run("multi field search", func(t *testing.T) {
+		params := NewAdvancedQueryParams()
+		params.AddSearchGroup(LogicAnd, []SearchCriteria{
+			CreateMultiFieldSearchCondition(OpIContains, "go books", true, "name", "category"),
+		})
+
+		page, _, err := Evaluate(evaluateProducts(), params)
+		assert.NoError(t, err)
+		assert.Equal(t, []int{1}, evaluateIDs(page))
+	})
+}
+
+// Test Evaluate sorting and pagination
+func TestEvaluate_SortAndPaginate(t *testing.T) {
+	params := NewAdvancedQueryParams()
+	params.Sort = []SortCriteria{{Field: "category", Order: SortAsc}, {Field: "price", Order: SortDesc}}
+	params.SetPagination(1, 3)
+
+	page, meta, err := Evaluate(evaluateProducts(), params)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{4, 1, 2}, evaluateIDs(page))
+	assert.Equal(t, &PaginationMeta{TotalItems: 4, TotalPage: 2, CurrentPage: 1, PageLimit: 3, HasNext: true, From: 1, To: 3, NextPage: 2}, meta)
+
+	params.SetPagination(2, 3)
+	page, _, err = Evaluate(evaluateProducts(), params)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{3}, evaluateIDs(page))
+
+	params.SetPagination(5, 3)
+	page, _, err = Evaluate(evaluateProducts(), params)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{}, evaluateIDs(page))
+
+	t.Run("sort by distance", func(t *testing.T) {
+		params := NewAdvancedQueryParams()
+		params.Sort = []SortCriteria{{Field: "location", Near: &GeoPoint{Lat: 48.1, Lng: 11.5}}}
+
+		page, _, err := Evaluate(evaluateProducts(), params)
+		assert.NoError(t, err)
+		assert.Equal(t, []int{2, 3, 1, 4}, evaluateIDs(page))
+	})
+}
+
+// Test Evaluate with map items
+func TestEvaluate_Maps(t *testing.T) {
+	items := []map[string]any{
+		{"name": "a", "score": 3},
+		{"name": "b", "score": nil},
+		{"name": "c", "score": 7},
+	}
+	params := NewAdvancedQueryParams()
+	params.Filters = append(params.Filters, CreateFilterCondition("score", OpGreaterThan, 1))
+	params.Sort = []SortCriteria{{Field: "score", Order: SortDesc}}
+
+	page, _, err := Evaluate(items, params)
+	assert.NoError(t, err)
+	assert.Equal(t, []map[string]any{{"name": "c", "score": 7}, {"name": "a", "score": 3}}, page)
+}
+
+// Test Evaluate errors
+func TestEvaluate_Errors(t *testing.T) {
+	tests := []struct {
+		name     string
+		params   *AdvancedQueryParams
+		expected error
+	}{
+		{
+			name: "unknown field",
+			params: &AdvancedQueryParams{
+				Filters: []FilterCriteria{{Field: "missing", Operator: OpEqual, Value: 1}},
+			},
+			expected: ErrUnknownField,
+		},
+		{
+			name: "full text",
+			params: &AdvancedQueryParams{
+				Filters: []FilterCriteria{{Field: "name", Operator: OpFullText, Value: "go"}},
+			},
+			expected: ErrUnsupportedOperator,
+		},
+		{
+			name: "invalid group operator",
+			params: &AdvancedQueryParams{
+				SearchGroups: []LogicalGroup{{Operator: "XOR"}},
+			},
+			expected: ErrInvalidGroupOperator,
+		},
+		{
+			name: "invalid regex",
+			params: &AdvancedQueryParams{
+				Filters: []FilterCriteria{{Field: "name", Operator: OpRegex, Value: "("}},
+			},
+			expected: ErrInvalidRegex,
+		},
+		{
+			name: "unknown sort field",
+			params: &AdvancedQueryParams{
+				Sort: []SortCriteria{{Field: "missing"}},
+			},
+			expected: ErrUnknownField,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := Evaluate(evaluateProducts(), tt.params)
+			assert.ErrorIs(t, err, tt.expected)
+		})
+	}
+}
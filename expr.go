@@ -0,0 +1,515 @@
+package sqlbuilder
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Expr is an immutable boolean condition tree
+// LogicalGroup, SearchCriteria and FilterCriteria compile into Expr values, which are
+// only turned into SQL (and bound parameters) by SQLBuilder.Render. Until then a tree
+// can be inspected with Walk, rewritten with Transform and compared with EqualExpr.
+type Expr interface {
+	isExpr()
+}
+
+// AndExpr joins its children with AND
+type AndExpr struct {
+	exprs []Expr
+}
+
+// OrExpr joins its children with OR
+type OrExpr struct {
+	exprs []Expr
+}
+
+// NotExpr negates its child
+type NotExpr struct {
+	expr Expr
+}
+
+// CmpExpr compares a field using one of the search operators
+type CmpExpr struct {
+	field    string
+	operator string
+	value    any
+	column   bool // field is a member of an expanded field group, never a group itself
+}
+
+// InExpr matches a field against a list of values
+type InExpr struct {
+	field   string
+	values  []any
+	negated bool
+	column  bool // field is a member of an expanded field group, never a group itself
+}
+
+// RawExpr is a hand-written SQL fragment with ? placeholders for its arguments
+type RawExpr struct {
+	sql  string
+	args []any
+}
+
+func (AndExpr) isExpr() {}
+func (OrExpr) isExpr()  {}
+func (NotExpr) isExpr() {}
+func (CmpExpr) isExpr() {}
+func (InExpr) isExpr()  {}
+func (RawExpr) isExpr() {}
+
+// invalidExpr stands in for a part of the input that could not be compiled
+// Rendering an expression that contains one records the error on the builder and
+// produces 1 = 0 for the whole expression.
+type invalidExpr struct {
+	err error
+}
+
+func (invalidExpr) isExpr() {}
+
+// And creates an AND expression, nil children are dropped
+func And(exprs ...Expr) Expr {
+	return AndExpr{exprs: compactExprs(exprs)}
+}
+
+// Or creates an OR expression, nil children are dropped
+func Or(exprs ...Expr) Expr {
+	return OrExpr{exprs: compactExprs(exprs)}
+}
+
+// Not creates a negated expression
+func Not(expr Expr) Expr {
+	return NotExpr{expr: expr}
+}
+
+// Cmp creates a comparison expression using a search operator such as OpEqual
+func Cmp(field, operator string, value any) Expr {
+	return CmpExpr{field: field, operator: operator, value: value}
+}
+
+// In creates a field IN (...) expression
+func In(field string, values ...any) Expr {
+	return InExpr{field: field, values: append([]any{}, values...)}
+}
+
+// NotIn creates a field NOT IN (...) expression
+func NotIn(field string, values ...any) Expr {
+	return InExpr{field: field, values: append([]any{}, values...), negated: true}
+}
+
+// Raw creates an expression from a SQL fragment using ? placeholders for args, ?? for a literal ?
+func Raw(sql string, args ...any) Expr {
+	return RawExpr{sql: sql, args: append([]any{}, args...)}
+}
+
+// Exprs returns a copy of the children
+func (e AndExpr) Exprs() []Expr { return append([]Expr{}, e.exprs...) }
+
+// Exprs returns a copy of the children
+func (e OrExpr) Exprs() []Expr { return append([]Expr{}, e.exprs...) }
+
+// Inner returns the negated expression
+func (e NotExpr) Inner() Expr { return e.expr }
+
+// Field returns the compared field
+func (e CmpExpr) Field() string { return e.field }
+
+// Operator returns the search operator
+func (e CmpExpr) Operator() string { return e.operator }
+
+// Value returns the compared value
+func (e CmpExpr) Value() any { return e.value }
+
+// Field returns the matched field
+func (e InExpr) Field() string { return e.field }
+
+// Values returns a copy of the matched values
+func (e InExpr) Values() []any { return append([]any{}, e.values...) }
+
+// Negated returns true for NOT IN expressions
+func (e InExpr) Negated() bool { return e.negated }
+
+// SQL returns the raw SQL fragment
+func (e RawExpr) SQL() string { return e.sql }
+
+// Args returns a copy of the fragment arguments
+func (e RawExpr) Args() []any { return append([]any{}, e.args...) }
+
+// Expr compiles the search criterion into an expression
+// Several fields compile to an OR across the fields. With Tokenize the value is split
+// on whitespace and every word must match, giving an AND of such ORs.
+func (c SearchCriteria) Expr() Expr {
+	fields := c.renderedFields()
+	values := c.renderedValues()
+
+	if len(fields) == 1 && len(values) == 1 {
+		return criterionExpr(fields[0], c.Operator, values[0])
+	}
+
+	terms := make([]Expr, 0, len(values))
+	for _, value := range values {
+		alternatives := make([]Expr, len(fields))
+		for i, field := range fields {
+			alternatives[i] = criterionExpr(field, c.Operator, value)
+		}
+		terms = append(terms, Or(alternatives...))
+	}
+	if len(terms) == 1 {
+		return terms[0]
+	}
+	return And(terms...)
+}
+
+// renderedFields returns the fields Expr compiles, Fields taking precedence over Field
+func (c SearchCriteria) renderedFields() []string {
+	if len(c.Fields) > 0 {
+		return c.Fields
+	}
+	return []string{c.Field}
+}
+
+// renderedValues returns the values Expr compiles, the words of the value with Tokenize
+func (c SearchCriteria) renderedValues() []any {
+	if !c.Tokenize {
+		return []any{c.Value}
+	}
+	words := strings.Fields(fmt.Sprint(c.Value))
+	values := make([]any, len(words))
+	for i, word := range words {
+		values[i] = word
+	}
+	return values
+}
+
+// Expr compiles the filter criterion into an expression
+func (f FilterCriteria) Expr() Expr {
+	return criterionExpr(f.Field, f.Operator, f.Value)
+}
+
+// Expr compiles the group, its conditions first and then its nested groups
+// An empty operator defaults to AND. Groups with any other operator than AND or OR
+// compile to an expression that renders 1 = 0 and sets SQLBuilder.Err.
+func (g LogicalGroup) Expr() Expr {
+	operator, err := groupOperator(g.Operator)
+	if err != nil {
+		return invalidExpr{err: err}
+	}
+
+	exprs := make([]Expr, 0, len(g.Conditions)+len(g.Groups))
+	for _, criterion := range g.Conditions {
+		exprs = append(exprs, criterion.Expr())
+	}
+	for _, nestedGroup := range g.Groups {
+		exprs = append(exprs, nestedGroup.Expr())
+	}
+
+	expr := And(exprs...)
+	if operator == LogicOr {
+		expr = Or(exprs...)
+	}
+	if g.Not {
+		return Not(expr)
+	}
+	return expr
+}
+
+// Validate checks the operators of the group and all nested groups
+func (g LogicalGroup) Validate() error {
+	if _, err := groupOperator(g.Operator); err != nil {
+		return err
+	}
+	for _, nestedGroup := range g.Groups {
+		if err := nestedGroup.Validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Validate checks every search group
+func (q *AdvancedQueryParams) Validate() error {
+	for _, group := range q.SearchGroups {
+		if err := group.Validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Expr compiles the search groups and filters into a single AND expression
+func (q *AdvancedQueryParams) Expr() Expr {
+	exprs := make([]Expr, 0, len(q.SearchGroups)+len(q.Filters))
+	for _, group := range q.SearchGroups {
+		exprs = append(exprs, group.Expr())
+	}
+	for _, filter := range q.Filters {
+		exprs = append(exprs, filter.Expr())
+	}
+	return And(exprs...)
+}
+
+// Walk visits e and its descendants depth-first
+// Children of a node are skipped when fn returns false for it
+func Walk(e Expr, fn func(Expr) bool) {
+	if e == nil || !fn(e) {
+		return
+	}
+	switch node := e.(type) {
+	case AndExpr:
+		for _, child := range node.exprs {
+			Walk(child, fn)
+		}
+	case OrExpr:
+		for _, child := range node.exprs {
+			Walk(child, fn)
+		}
+	case NotExpr:
+		Walk(node.expr, fn)
+	}
+}
+
+// Transform rebuilds e bottom-up, replacing every node with fn(node)
+// Returning nil from fn removes the node from its parent. The input tree is left untouched.
+func Transform(e Expr, fn func(Expr) Expr) Expr {
+	switch node := e.(type) {
+	case nil:
+		return nil
+	case AndExpr:
+		return fn(And(transformAll(node.exprs, fn)...))
+	case OrExpr:
+		return fn(Or(transformAll(node.exprs, fn)...))
+	case NotExpr:
+		inner := Transform(node.expr, fn)
+		if inner == nil {
+			return nil
+		}
+		return fn(Not(inner))
+	default:
+		return fn(e)
+	}
+}
+
+// EqualExpr reports whether two expressions are structurally identical
+func EqualExpr(a, b Expr) bool {
+	return reflect.DeepEqual(a, b)
+}
+
+// AddWhereExpr renders the expression and adds it to the WHERE clause
+func (s *SQLBuilder) AddWhereExpr(e Expr) {
+	s.AddWhereCondition(s.Render(e))
+}
+
+// Render renders an expression to SQL and binds its parameters
+// Conditions that render empty (unknown operators, empty IN lists, empty groups) are omitted,
+// and nested AND/OR children are wrapped in their own parentheses. An expression with a part
+// that could not be compiled, such as a group with an unknown operator, a field that is not an
+// identifier or a raw fragment whose ? markers do not match its args, sets Err and renders
+// 1 = 0 as a whole, as leaving out only that part would widen the result.
+func (s *SQLBuilder) Render(e Expr) string {
+	if err := s.exprErr(e); err != nil {
+		s.setErr(err)
+		return scopeNever
+	}
+	return s.render(e)
+}
+
+// exprErr returns the error of the first part of e that could not be compiled
+// Fields that are not identifiers and raw fragments whose ? markers do not match their
+// args count as such a part.
+func (s *SQLBuilder) exprErr(e Expr) error {
+	var err error
+	Walk(e, func(node Expr) bool {
+		if err != nil {
+			return false
+		}
+		switch node := node.(type) {
+		case invalidExpr:
+			err = node.err
+		case CmpExpr:
+			err = s.checkField(node.field, node.operator)
+		case InExpr:
+			err = s.checkField(node.field, OpIn)
+		case RawExpr:
+			pieces, _ := splitRaw(node.sql)
+			err = rawErr(pieces, node.args)
+		}
+		return err == nil
+	})
+	return err
+}
+
+// render renders an expression that compiled without errors
+func (s *SQLBuilder) render(e Expr) string {
+	switch node := e.(type) {
+	case AndExpr:
+		return s.renderJoined(node.exprs, LogicAnd)
+	case OrExpr:
+		return s.renderJoined(node.exprs, LogicOr)
+	case NotExpr:
+		inner := s.render(node.expr)
+		if inner == "" {
+			return ""
+		}
+		return "NOT (" + inner + ")"
+	case CmpExpr:
+		if fields, ok := s.schema.FieldGroup(node.field); ok && !node.column {
+			alternatives := make([]Expr, len(fields))
+			for i, field := range fields {
+				alternatives[i] = CmpExpr{field: field, operator: node.operator, value: node.value, column: true}
+			}
+			return s.render(Or(alternatives...))
+		}
+		return s.buildCondition(node.field, node.operator, node.value)
+	case InExpr:
+		if len(node.values) == 0 {
+			return ""
+		}
+		if fields, ok := s.schema.FieldGroup(node.field); ok && !node.column {
+			alternatives := make([]Expr, len(fields))
+			for i, field := range fields {
+				alternatives[i] = InExpr{field: field, values: node.values, negated: node.negated, column: true}
+			}
+			return s.render(Or(alternatives...))
+		}
+		column, ok := s.resolveField(node.field, numericValues(node.values))
+		if !ok {
+			return ""
+		}
+		if node.negated {
+			return column + " NOT IN (" + s.bindList(node.field, node.values) + ")"
+		}
+		return column + " IN (" + s.bindList(node.field, node.values) + ")"
+	case RawExpr:
+		return s.bindRaw(node.sql, node.args)
+	case invalidExpr:
+		s.setErr(node.err)
+	}
+	return ""
+}
+
+// renderJoined renders children joined by a logical operator inside parentheses
+func (s *SQLBuilder) renderJoined(exprs []Expr, operator string) string {
+	var conditions []string
+	for _, child := range exprs {
+		condition := s.render(child)
+		if condition == "" {
+			continue
+		}
+		if isCompositeExpr(child) {
+			condition = "(" + condition + ")"
+		}
+		conditions = append(conditions, condition)
+	}
+
+	if len(conditions) == 0 {
+		return ""
+	}
+	return "(" + strings.Join(conditions, " "+operator+" ") + ")"
+}
+
+// bindRaw binds args to the ? markers of a raw fragment, outside of quoted literals
+// ?? writes a literal ?, e.g. for the PostgreSQL JSONB key operator.
+func (s *SQLBuilder) bindRaw(fragment string, args []any) string {
+	pieces, tail := splitRaw(fragment)
+	if len(pieces) != len(args) {
+		s.setErr(rawErr(pieces, args))
+		return ""
+	}
+	var sql strings.Builder
+	for i, piece := range pieces {
+		sql.WriteString(piece)
+		sql.WriteString(s.bind("raw", args[i]))
+	}
+	sql.WriteString(tail)
+	return sql.String()
+}
+
+// splitRaw splits a raw fragment at its ? markers, returning the text before each marker and the rest
+func splitRaw(fragment string) (pieces []string, tail string) {
+	var b strings.Builder
+	var quote rune
+	runes := []rune(fragment)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '\'' || r == '"' || r == '`':
+			quote = r
+		case r == '?' && i+1 < len(runes) && runes[i+1] == '?':
+			i++
+		case r == '?':
+			pieces = append(pieces, b.String())
+			b.Reset()
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return pieces, b.String()
+}
+
+// rawErr returns an error if the marker count of a raw fragment does not match args
+func rawErr(pieces []string, args []any) error {
+	if len(pieces) == len(args) {
+		return nil
+	}
+	return fmt.Errorf("%w: raw condition has %d placeholders for %d args", ErrInvalidValue, len(pieces), len(args))
+}
+
+// groupOperator normalizes a group operator, an empty operator means AND
+func groupOperator(operator string) (string, error) {
+	switch strings.ToUpper(operator) {
+	case "", LogicAnd:
+		return LogicAnd, nil
+	case LogicOr:
+		return LogicOr, nil
+	}
+	return "", fmt.Errorf("%w: %q", ErrInvalidGroupOperator, operator)
+}
+
+// criterionExpr compiles a single criterion, list operators become InExpr
+// Typed lists such as []string are accepted like []any.
+func criterionExpr(field, operator string, value any) Expr {
+	if values, ok := listValues(value); ok {
+		switch operator {
+		case OpIn:
+			return In(field, values...)
+		case OpNotIn:
+			return NotIn(field, values...)
+		}
+	}
+	return Cmp(field, operator, value)
+}
+
+// isCompositeExpr returns true for expressions that join several children
+func isCompositeExpr(e Expr) bool {
+	switch e.(type) {
+	case AndExpr, OrExpr:
+		return true
+	}
+	return false
+}
+
+// compactExprs copies exprs without nil entries
+func compactExprs(exprs []Expr) []Expr {
+	result := make([]Expr, 0, len(exprs))
+	for _, e := range exprs {
+		if e != nil {
+			result = append(result, e)
+		}
+	}
+	return result
+}
+
+// transformAll transforms every expression and drops removed ones
+func transformAll(exprs []Expr, fn func(Expr) Expr) []Expr {
+	result := make([]Expr, 0, len(exprs))
+	for _, child := range exprs {
+		if transformed := Transform(child, fn); transformed != nil {
+			result = append(result, transformed)
+		}
+	}
+	return result
+}
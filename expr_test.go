@@ -0,0 +1,288 @@
+package sqlbuilder
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Test Render for every expression node
+func TestSQLBuilder_Render(t *testing.T) {
+	tests := []struct {
+		name           string
+		expr           Expr
+		expectedSQL    string
+		expectedParams []any
+	}{
+		{
+			name:           "comparison",
+			expr:           Cmp("status", OpEqual, "active"),
+			expectedSQL:    "status = ?",
+			expectedParams: []any{"active"},
+		},
+		{
+			name:           "in",
+			expr:           In("id", 1, 2),
+			expectedSQL:    "id IN (?, ?)",
+			expectedParams: []any{1, 2},
+		},
+		{
+			name:           "not in",
+			expr:           NotIn("id", 3),
+			expectedSQL:    "id NOT IN (?)",
+			expectedParams: []any{3},
+		},
+		{
+			name:           "empty in is omitted",
+			expr:           In("id"),
+			expectedSQL:    "",
+			expectedParams: []any{},
+		},
+		{
+			name:           "raw fragment binds placeholders outside quotes",
+			expr:           Raw("tenant_id = ? AND note != '?'", 7),
+			expectedSQL:    "tenant_id = ? AND note != '?'",
+			expectedParams: []any{7},
+		},
+		{
+			name:           "and with nested or",
+			expr:           And(Cmp("a", OpEqual, 1), Or(Cmp("b", OpEqual, 2), Cmp("c", OpEqual, 3))),
+			expectedSQL:    "(a = ? AND ((b = ? OR c = ?)))",
+			expectedParams: []any{1, 2, 3},
+		},
+		{
+			name:           "not",
+			expr:           Not(Cmp("deleted", OpEqual, true)),
+			expectedSQL:    "NOT (deleted = ?)",
+			expectedParams: []any{true},
+		},
+		{
+			name:           "empty children are skipped",
+			expr:           And(Or(), Cmp("a", "unknown", 1), Cmp("b", OpIsNull, nil)),
+			expectedSQL:    "(b IS NULL)",
+			expectedParams: []any{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			builder := NewSQLBuilder()
+			assert.Equal(t, tt.expectedSQL, builder.Render(tt.expr))
+			assert.Equal(t, tt.expectedParams, builder.GetParams())
+		})
+	}
+}
+
+// Test compiling groups into expressions
+func TestLogicalGroup_Expr(t *testing.T) {
+	group := LogicalGroup{
+		Operator: "or",
+		Conditions: []SearchCriteria{
+			{Field: "title", Operator: OpContains, Value: "go"},
+			{Field: "category", Operator: OpIn, Value: []any{"a", "b"}},
+		},
+		Groups: []LogicalGroup{
+			{Operator: LogicAnd, Conditions: []SearchCriteria{{Field: "status", Operator: OpEqual, Value: "active"}}},
+		},
+	}
+
+	expected := Or(
+		Cmp("title", OpContains, "go"),
+		In("category", "a", "b"),
+		And(Cmp("status", OpEqual, "active")),
+	)
+	assert.True(t, EqualExpr(expected, group.Expr()))
+	assert.False(t, EqualExpr(expected, And(expected)))
+}
+
+// Test Walk and Transform
+func TestExpr_WalkAndTransform(t *testing.T) {
+	original := And(
+		Cmp("tenant_id", OpEqual, 1),
+		Or(Cmp("title", OpContains, "go"), Not(Cmp("tenant_id", OpEqual, 2))),
+	)
+
+	var fields []string
+	Walk(original, func(e Expr) bool {
+		if cmp, ok := e.(CmpExpr); ok {
+			fields = append(fields, cmp.Field())
+		}
+		return true
+	})
+	assert.Equal(t, []string{"tenant_id", "title", "tenant_id"}, fields)
+
+	// Strip client supplied tenant predicates and enforce our own
+	stripped := Transform(original, func(e Expr) Expr {
+		if cmp, ok := e.(CmpExpr); ok && cmp.Field() == "tenant_id" {
+			return nil
+		}
+		return e
+	})
+	scoped := And(Cmp("tenant_id", OpEqual, 42), stripped)
+
+	builder := NewSQLBuilder()
+	assert.Equal(t, "(tenant_id = ? AND ((((title LIKE ?)))))", builder.Render(scoped))
+	assert.Equal(t, []any{42, "%go%"}, builder.GetParams())
+
+	// The original tree is unchanged
+	builder = NewSQLBuilder()
+	assert.Equal(t, "(tenant_id = ? AND ((title LIKE ? OR NOT (tenant_id = ?))))", builder.Render(original))
+}
+
+// Test AdvancedQueryParams Expr with AddWhereExpr
+func TestAdvancedQueryParams_Expr(t *testing.T) {
+	params := NewAdvancedQueryParams()
+	params.AddSearchGroup(LogicOr, []SearchCriteria{
+		CreateSearchCondition("title", OpIContains, "go"),
+		CreateSearchCondition("body", OpIContains, "go"),
+	})
+	params.Filters = append(params.Filters, CreateFilterCondition("status", OpEqual, "published"))
+
+	builder := NewSQLBuilder()
+	builder.AddWhereExpr(params.Expr())
+	assert.Equal(t, "WHERE (((LOWER(title) LIKE LOWER(?) OR LOWER(body) LIKE LOWER(?))) AND status = ?)", builder.GetWhereClause())
+	assert.Equal(t, []any{"%go%", "%go%", "published"}, builder.GetParams())
+}
+
+// Test NOT groups and group operator validation
+func TestLogicalGroup_NotAndValidation(t *testing.T) {
+	t.Run("negated group", func(t *testing.T) {
+		builder := NewSQLBuilder()
+		groups := []LogicalGroup{
+			{
+				Operator: LogicAnd,
+				Conditions: []SearchCriteria{
+					{Field: "status", Operator: OpEqual, Value: "active"},
+				},
+				Groups: []LogicalGroup{
+					{
+						Operator: LogicOr,
+						Not:      true,
+						Conditions: []SearchCriteria{
+							{Field: "name", Operator: OpContains, Value: "test"},
+							{Field: "email", Operator: OpEndsWith, Value: "@example.com"},
+						},
+					},
+				},
+			},
+		}
+
+		result := builder.BuildAdvancedSearchConditions(groups)
+		assert.Equal(t, "(status = ? AND NOT ((name LIKE ? OR email LIKE ?)))", result)
+		assert.Equal(t, []any{"active", "%test%", "%@example.com"}, builder.GetParams())
+		assert.NoError(t, builder.Err())
+	})
+
+	t.Run("lowercase and empty operators are accepted", func(t *testing.T) {
+		group := LogicalGroup{
+			Operator: "or",
+			Groups:   []LogicalGroup{{Conditions: []SearchCriteria{{Field: "a", Operator: OpIsNull}}}},
+		}
+		assert.NoError(t, group.Validate())
+
+		builder := NewSQLBuilder()
+		assert.Equal(t, "(((a IS NULL)))", builder.BuildAdvancedSearchConditions([]LogicalGroup{group}))
+		assert.NoError(t, builder.Err())
+	})
+
+	t.Run("unknown operator is rejected", func(t *testing.T) {
+		params := NewAdvancedQueryParams()
+		params.AddSearchGroup(LogicOr, []SearchCriteria{CreateSearchCondition("a", OpEqual, 1)})
+		params.AddNestedSearchGroup(0, "XOR", []SearchCriteria{CreateSearchCondition("b", OpEqual, 2)})
+
+		err := params.Validate()
+		assert.ErrorIs(t, err, ErrInvalidGroupOperator)
+		assert.Contains(t, err.Error(), `"XOR"`)
+
+		builder := NewSQLBuilder()
+		params.ApplyAdvancedSearch(builder)
+		assert.ErrorIs(t, builder.Err(), ErrInvalidGroupOperator)
+		assert.Equal(t, "WHERE 1 = 0", builder.GetWhereClause())
+		assert.Empty(t, builder.GetParams())
+	})
+
+	t.Run("invalid group fails closed", func(t *testing.T) {
+		builder := NewSQLBuilder()
+		result := builder.BuildAdvancedSearchConditions([]LogicalGroup{
+			CreateSearchGroup(LogicAnd, CreateSearchCondition("tenant_id", OpEqual, 7)),
+			{Operator: LogicOr, Not: true, Groups: []LogicalGroup{CreateSearchGroup("XOR", CreateSearchCondition("b", OpEqual, 2))}},
+		})
+		assert.Equal(t, "(tenant_id = ?) AND 1 = 0", result)
+		assert.ErrorIs(t, builder.Err(), ErrInvalidGroupOperator)
+		assert.Equal(t, "1 = 0", builder.Render(Or(Not(LogicalGroup{Operator: "XOR"}.Expr()), Cmp("a", OpEqual, 1))))
+	})
+
+	t.Run("operator text never reaches the SQL", func(t *testing.T) {
+		builder := NewSQLBuilder()
+		result := builder.BuildAdvancedSearchConditions([]LogicalGroup{
+			CreateSearchGroup("1=1) OR (1", CreateSearchCondition("a", OpEqual, 1), CreateSearchCondition("b", OpEqual, 2)),
+		})
+		assert.Equal(t, "1 = 0", result)
+		assert.ErrorIs(t, builder.Err(), ErrInvalidGroupOperator)
+	})
+}
+
+// Test multi-field search criteria
+func TestSearchCriteria_MultiField(t *testing.T) {
+	t.Run("fields expand to OR", func(t *testing.T) {
+		builder := NewSQLBuilder()
+		result := builder.BuildSearchConditions([]SearchCriteria{
+			CreateMultiFieldSearchCondition(OpContains, "john", false, "name", "email", "phone"),
+			CreateSearchCondition("status", OpEqual, "active"),
+		})
+		assert.Equal(t, "(((name LIKE ? OR email LIKE ? OR phone LIKE ?)) OR status = ?)", result)
+		assert.Equal(t, []any{"%john%", "%john%", "%john%", "active"}, builder.GetParams())
+	})
+
+	t.Run("tokenized words must each match a field", func(t *testing.T) {
+		builder := NewSQLBuilder()
+		criterion := CreateMultiFieldSearchCondition(OpIContains, "  john  smith ", true, "first_name", "last_name")
+		result := builder.Render(criterion.Expr())
+		assert.Equal(t, "(((LOWER(first_name) LIKE LOWER(?) OR LOWER(last_name) LIKE LOWER(?))) AND ((LOWER(first_name) LIKE LOWER(?) OR LOWER(last_name) LIKE LOWER(?))))", result)
+		assert.Equal(t, []any{"%john%", "%john%", "%smith%", "%smith%"}, builder.GetParams())
+	})
+
+	t.Run("tokenize on a single field", func(t *testing.T) {
+		builder := NewSQLBuilder()
+		criterion := SearchCriteria{Field: "title", Operator: OpContains, Value: "go sql", Tokenize: true}
+		assert.Equal(t, "(((title LIKE ?)) AND ((title LIKE ?)))", builder.Render(criterion.Expr()))
+	})
+
+	t.Run("empty tokenized value renders nothing", func(t *testing.T) {
+		builder := NewSQLBuilder()
+		criterion := CreateMultiFieldSearchCondition(OpContains, "   ", true, "name", "email")
+		assert.Equal(t, "", builder.Render(criterion.Expr()))
+	})
+
+	t.Run("schema field group", func(t *testing.T) {
+		builder := NewSQLBuilder()
+		builder.SetSchema(NewSchema().AddFieldGroup("contact", "name", "email", "phone"))
+
+		params := NewQueryParams()
+		params.AddSearch("contact", OpStartsWith, "jo")
+		params.ApplySearch(builder)
+		assert.Equal(t, "WHERE ((name LIKE ? OR email LIKE ? OR phone LIKE ?))", builder.GetWhereClause())
+		assert.Equal(t, []any{"jo%", "jo%", "jo%"}, builder.GetParams())
+	})
+
+	t.Run("field groups expand one level", func(t *testing.T) {
+		builder := NewSQLBuilder()
+		builder.SetSchema(NewSchema().
+			AddFieldGroup("name", "name", "title").
+			AddFieldGroup("a", "b").
+			AddFieldGroup("b", "a"))
+
+		assert.Equal(t, "(name = ? OR title = ?)", builder.Render(Cmp("name", OpEqual, "x")))
+		assert.Equal(t, "(b = ?)", builder.Render(Cmp("a", OpEqual, 1)))
+		assert.NoError(t, builder.Err())
+	})
+
+	t.Run("field group in list", func(t *testing.T) {
+		builder := NewSQLBuilder()
+		builder.SetSchema(NewSchema().AddFieldGroup("contact", "email", "phone"))
+
+		assert.Equal(t, "(email IN (?, ?) OR phone IN (?, ?))", builder.Render(In("contact", "a", "b")))
+		assert.Equal(t, "(email NOT IN (?) OR phone NOT IN (?))", builder.Render(CreateSearchCondition("contact", OpNotIn, []any{"a"}).Expr()))
+		assert.Equal(t, []any{"a", "b", "a", "b", "a", "a"}, builder.GetParams())
+	})
+}
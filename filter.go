@@ -0,0 +1,427 @@
+package sqlbuilder
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// filterNumberPattern matches bare values parsed as numbers
+var filterNumberPattern = regexp.MustCompile(`^-?[0-9]+(\.[0-9]+)?$`)
+
+// filterBarePattern matches string values that can be written without quotes
+var filterBarePattern = regexp.MustCompile(`^[^\s()"\[\],]+$`)
+
+// filterFieldPattern matches a single field of a filter expression
+var filterFieldPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_.]*$`)
+
+// structValueOperators take struct values, which cannot be written as text or decoded JSON
+var structValueOperators = map[string]bool{
+	OpArrayLength:  true,
+	OpWithinRadius: true,
+	OpWithinBBox:   true,
+}
+
+// ParseFilter parses a compact filter expression into AdvancedQueryParams
+// A condition is field:operator:value, e.g. status:eq:active AND (age:gte:18 OR tags:in:[a,b]).
+// Conditions are combined with AND, OR, NOT and parentheses, AND binding tighter than OR.
+// The null operators take no value (vip:is_not_null), name|email:icontains:bob matches any of
+// the fields. Bare values that look like numbers, true, false or null are typed accordingly;
+// "double quoted" values are always strings and may contain spaces, \" and \\.
+//
+// The expression becomes the only search group of the returned params, syntax errors wrap ErrSyntax
+// and report the byte offset of the problem.
+func ParseFilter(expr string) (*AdvancedQueryParams, error) {
+	params := NewAdvancedQueryParams()
+	p := &filterParser{input: expr}
+	if p.skipSpace(); p.pos == len(p.input) {
+		return params, nil
+	}
+
+	node, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.skipSpace(); p.pos < len(p.input) {
+		return nil, p.errorf(p.pos, "unexpected %s", p.describe())
+	}
+
+	group := CreateSearchGroup(LogicAnd)
+	if node.group != nil {
+		group = *node.group
+	} else {
+		addWhereNode(&group, node)
+	}
+	params.SearchGroups = append(params.SearchGroups, group)
+	return params, nil
+}
+
+// FormatFilter prints a group in the syntax accepted by ParseFilter
+// Nested groups are parenthesized and empty groups are left out. Criteria that cannot be
+// expressed, such as tokenized search or struct values, return ErrInvalidValue.
+func FormatFilter(group LogicalGroup) (string, error) {
+	return formatFilterGroup(group, false)
+}
+
+// filterParser is a recursive descent parser over the raw expression
+type filterParser struct {
+	input string
+	pos   int
+}
+
+func (p *filterParser) skipSpace() {
+	for p.pos < len(p.input) {
+		r, size := p.peek()
+		if !unicode.IsSpace(r) {
+			return
+		}
+		p.pos += size
+	}
+}
+
+// peek decodes the rune at the current position, utf8.RuneError for invalid UTF-8
+func (p *filterParser) peek() (rune, int) {
+	return utf8.DecodeRuneInString(p.input[p.pos:])
+}
+
+func (p *filterParser) errorf(pos int, format string, args ...any) error {
+	return fmt.Errorf("%w at offset %d: %s", ErrSyntax, pos, fmt.Sprintf(format, args...))
+}
+
+// describe describes the input at the current position in error messages
+func (p *filterParser) describe() string {
+	if p.pos >= len(p.input) {
+		return "end of input"
+	}
+	r, _ := p.peek()
+	return strconv.QuoteRune(r)
+}
+
+// word returns the identifier at the current position without consuming it
+func (p *filterParser) word() string {
+	end := p.pos
+	for end < len(p.input) {
+		r, size := utf8.DecodeRuneInString(p.input[end:])
+		if r != '_' && !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			break
+		}
+		end += size
+	}
+	return p.input[p.pos:end]
+}
+
+// acceptKeyword consumes AND, OR or NOT unless it is the field of a condition
+func (p *filterParser) acceptKeyword(keyword string) bool {
+	p.skipSpace()
+	word := p.word()
+	end := p.pos + len(word)
+	if !strings.EqualFold(word, keyword) || end < len(p.input) && strings.ContainsRune(":.|", rune(p.input[end])) {
+		return false
+	}
+	p.pos = end
+	return true
+}
+
+func (p *filterParser) expect(c byte) error {
+	p.skipSpace()
+	if p.pos >= len(p.input) || p.input[p.pos] != c {
+		return p.errorf(p.pos, "expected %q, got %s", c, p.describe())
+	}
+	p.pos++
+	return nil
+}
+
+func (p *filterParser) parseOr() (whereNode, error) {
+	return parseChain(LogicOr, p.acceptKeyword, p.parseAnd)
+}
+
+func (p *filterParser) parseAnd() (whereNode, error) {
+	return parseChain(LogicAnd, p.acceptKeyword, p.parseUnary)
+}
+
+// parseUnary parses NOT prefixes, parentheses and conditions
+func (p *filterParser) parseUnary() (whereNode, error) {
+	if p.acceptKeyword("NOT") {
+		node, err := p.parseUnary()
+		if err != nil {
+			return whereNode{}, err
+		}
+		return negateNode(node), nil
+	}
+
+	if p.skipSpace(); p.pos < len(p.input) && p.input[p.pos] == '(' {
+		p.pos++
+		node, err := p.parseOr()
+		if err != nil {
+			return whereNode{}, err
+		}
+		return node, p.expect(')')
+	}
+
+	criterion, err := p.parseCondition()
+	if err != nil {
+		return whereNode{}, err
+	}
+	return whereNode{criterion: &criterion}, nil
+}
+
+// parseCondition parses field:operator[:value]
+func (p *filterParser) parseCondition() (SearchCriteria, error) {
+	var fields []string
+	for {
+		start := p.pos
+		for p.pos < len(p.input) {
+			r, size := p.peek()
+			if r != '.' && r != '_' && !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+				break
+			}
+			p.pos += size
+		}
+		if !filterFieldPattern.MatchString(p.input[start:p.pos]) {
+			p.pos = start
+			return SearchCriteria{}, p.errorf(start, "expected field, got %s", p.describe())
+		}
+		fields = append(fields, p.input[start:p.pos])
+		if p.pos >= len(p.input) || p.input[p.pos] != '|' {
+			break
+		}
+		p.pos++
+	}
+
+	if p.pos >= len(p.input) || p.input[p.pos] != ':' {
+		return SearchCriteria{}, p.errorf(p.pos, "expected ':' after field, got %s", p.describe())
+	}
+	p.pos++
+
+	start := p.pos
+	operator := p.word()
+	p.pos += len(operator)
+	switch {
+	case !IsOperator(operator):
+		return SearchCriteria{}, p.errorf(start, "unknown operator %q", operator)
+	case structValueOperators[operator]:
+		return SearchCriteria{}, p.errorf(start, "operator %s is not supported in filter expressions", operator)
+	}
+
+	criterion := SearchCriteria{Operator: operator}
+	if len(fields) == 1 {
+		criterion.Field = fields[0]
+	} else {
+		criterion.Fields = fields
+	}
+	if operator == OpIsNull || operator == OpIsNotNull {
+		return criterion, nil
+	}
+
+	if p.pos >= len(p.input) || p.input[p.pos] != ':' {
+		return SearchCriteria{}, p.errorf(p.pos, "expected ':' and a value after %s, got %s", operator, p.describe())
+	}
+	p.pos++
+
+	start = p.pos
+	value, err := p.parseValue(true)
+	if err != nil {
+		return SearchCriteria{}, err
+	}
+	if _, ok := value.([]any); !ok && (operator == OpIn || operator == OpNotIn) {
+		return SearchCriteria{}, p.errorf(start, "operator %s expects a [list]", operator)
+	}
+	criterion.Value = value
+	return criterion, nil
+}
+
+// parseValue parses a quoted string, a bare value or, if allowed, a [list] of values
+func (p *filterParser) parseValue(allowList bool) (any, error) {
+	start := p.pos
+	if p.pos >= len(p.input) {
+		return nil, p.errorf(start, "expected value, got end of input")
+	}
+
+	switch p.input[p.pos] {
+	case '"':
+		var text strings.Builder
+		for p.pos++; p.pos < len(p.input); p.pos++ {
+			switch c := p.input[p.pos]; {
+			case c == '"':
+				p.pos++
+				return text.String(), nil
+			case c == '\\' && p.pos+1 < len(p.input):
+				p.pos++
+				text.WriteByte(p.input[p.pos])
+			default:
+				text.WriteByte(c)
+			}
+		}
+		return nil, p.errorf(start, "unterminated string")
+	case '[':
+		if !allowList {
+			return nil, p.errorf(start, "nested lists are not supported")
+		}
+		p.pos++
+		values := make([]any, 0)
+		if p.skipSpace(); p.pos < len(p.input) && p.input[p.pos] == ']' {
+			p.pos++
+			return values, nil
+		}
+		for {
+			p.skipSpace()
+			value, err := p.parseValue(false)
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, value)
+			if p.skipSpace(); p.pos < len(p.input) && p.input[p.pos] == ',' {
+				p.pos++
+				continue
+			}
+			return values, p.expect(']')
+		}
+	}
+
+	for p.pos < len(p.input) {
+		r, size := p.peek()
+		if unicode.IsSpace(r) || strings.ContainsRune(`()"[],`, r) {
+			break
+		}
+		p.pos += size
+	}
+	if p.pos == start {
+		return nil, p.errorf(start, "expected value, got %s", p.describe())
+	}
+	return filterBareValue(p.input[start:p.pos]), nil
+}
+
+// filterBareValue types an unquoted value
+func filterBareValue(text string) any {
+	switch strings.ToLower(text) {
+	case "null":
+		return nil
+	case "true":
+		return true
+	case "false":
+		return false
+	}
+	if filterNumberPattern.MatchString(text) {
+		if n, err := strconv.ParseInt(text, 10, 64); err == nil {
+			return n
+		}
+		if f, err := strconv.ParseFloat(text, 64); err == nil {
+			return f
+		}
+	}
+	return text
+}
+
+// formatFilterGroup prints a group, parenthesizing nested groups with several parts
+func formatFilterGroup(group LogicalGroup, nested bool) (string, error) {
+	operator, err := groupOperator(group.Operator)
+	if err != nil {
+		return "", err
+	}
+
+	parts := make([]string, 0, len(group.Conditions)+len(group.Groups))
+	for _, criterion := range group.Conditions {
+		part, err := formatFilterCriterion(criterion)
+		if err != nil {
+			return "", err
+		}
+		parts = append(parts, part)
+	}
+	for _, nestedGroup := range group.Groups {
+		part, err := formatFilterGroup(nestedGroup, true)
+		if err != nil {
+			return "", err
+		}
+		if part != "" {
+			parts = append(parts, part)
+		}
+	}
+
+	joined := strings.Join(parts, " "+operator+" ")
+	switch {
+	case len(parts) == 0:
+		return "", nil
+	case group.Not:
+		return "NOT (" + joined + ")", nil
+	case nested && len(parts) > 1:
+		return "(" + joined + ")", nil
+	}
+	return joined, nil
+}
+
+// formatFilterCriterion prints field:operator[:value]
+func formatFilterCriterion(criterion SearchCriteria) (string, error) {
+	if criterion.Tokenize {
+		return "", fmt.Errorf("%w: tokenized criteria cannot be written as a filter expression", ErrInvalidValue)
+	}
+	if !IsOperator(criterion.Operator) || structValueOperators[criterion.Operator] {
+		return "", fmt.Errorf("%w: operator %q cannot be written as a filter expression", ErrInvalidValue, criterion.Operator)
+	}
+
+	fields := criterion.Fields
+	if len(fields) == 0 {
+		fields = []string{criterion.Field}
+	}
+	for _, field := range fields {
+		if !filterFieldPattern.MatchString(field) {
+			return "", fmt.Errorf("%w: field %q cannot be written as a filter expression", ErrInvalidValue, field)
+		}
+	}
+
+	condition := strings.Join(fields, "|") + ":" + criterion.Operator
+	if criterion.Operator == OpIsNull || criterion.Operator == OpIsNotNull {
+		return condition, nil
+	}
+	value, err := formatFilterValue(criterion.Value, true)
+	if err != nil {
+		return "", err
+	}
+	return condition + ":" + value, nil
+}
+
+// formatFilterValue prints a value so that ParseFilter reads it back with the same type
+func formatFilterValue(value any, allowList bool) (string, error) {
+	switch v := value.(type) {
+	case nil:
+		return "null", nil
+	case string:
+		if filterBarePattern.MatchString(v) && strings.IndexFunc(v, unicode.IsSpace) < 0 && filterBareValue(v) == v {
+			return v, nil
+		}
+		return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(v) + `"`, nil
+	case time.Time:
+		return formatFilterValue(v.Format(time.RFC3339Nano), false)
+	}
+
+	rv := reflect.ValueOf(value)
+	switch rv.Kind() {
+	case reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return fmt.Sprint(value), nil
+	case reflect.Float32, reflect.Float64:
+		// A whole float keeps its decimal point, so it is not read back as an integer
+		text := strconv.FormatFloat(rv.Float(), 'f', -1, 64)
+		if filterNumberPattern.MatchString(text) && !strings.Contains(text, ".") {
+			text += ".0"
+		}
+		return text, nil
+	case reflect.Slice, reflect.Array:
+		if allowList {
+			items := make([]string, rv.Len())
+			for i := range items {
+				item, err := formatFilterValue(rv.Index(i).Interface(), false)
+				if err != nil {
+					return "", err
+				}
+				items[i] = item
+			}
+			return "[" + strings.Join(items, ",") + "]", nil
+		}
+	}
+	return "", fmt.Errorf("%w: %T cannot be written as a filter expression", ErrInvalidValue, value)
+}
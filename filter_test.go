@@ -0,0 +1,223 @@
+package sqlbuilder
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Test ParseFilter
+func TestParseFilter(t *testing.T) {
+	tests := []struct {
+		name     string
+		expr     string
+		expected []LogicalGroup
+	}{
+		{
+			name:     "empty",
+			expr:     " ",
+			expected: []LogicalGroup{},
+		},
+		{
+			name: "single condition",
+			expr: "status:eq:active",
+			expected: []LogicalGroup{
+				CreateSearchGroup(LogicAnd, CreateSearchCondition("status", OpEqual, "active")),
+			},
+		},
+		{
+			name: "non-ASCII values",
+			expr: "name:eq:voilà AND city:in:[Zürich, 東京]",
+			expected: []LogicalGroup{
+				CreateSearchGroup(LogicAnd,
+					CreateSearchCondition("name", OpEqual, "voilà"),
+					CreateSearchCondition("city", OpIn, []any{"Zürich", "東京"}),
+				),
+			},
+		},
+		{
+			name: "precedence and lists",
+			expr: "status:eq:active AND (age:gte:18 OR tags:in:[a, \"b c\", 3])",
+			expected: []LogicalGroup{{
+				Operator:   LogicAnd,
+				Conditions: []SearchCriteria{CreateSearchCondition("status", OpEqual, "active")},
+				Groups: []LogicalGroup{
+					CreateSearchGroup(LogicOr,
+						CreateSearchCondition("age", OpGreaterThanEq, int64(18)),
+						CreateSearchCondition("tags", OpIn, []any{"a", "b c", int64(3)}),
+					),
+				},
+			}},
+		},
+		{
+			name: "typed values",
+			expr: `a:eq:true and b:ne:null and c:lt:-1.5 and d:eq:"42" and e:on_date:2024-01-31 and f:eq:10:30`,
+			expected: []LogicalGroup{
+				CreateSearchGroup(LogicAnd,
+					CreateSearchCondition("a", OpEqual, true),
+					CreateSearchCondition("b", OpNotEqual, nil),
+					CreateSearchCondition("c", OpLessThan, -1.5),
+					CreateSearchCondition("d", OpEqual, "42"),
+					CreateSearchCondition("e", OpOnDate, "2024-01-31"),
+					CreateSearchCondition("f", OpEqual, "10:30"),
+				),
+			},
+		},
+		{
+			name: "null operators, multi field and escapes",
+			expr: `vip:is_not_null OR name|u.email:icontains:"say \"hi\""`,
+			expected: []LogicalGroup{
+				CreateSearchGroup(LogicOr,
+					CreateSearchCondition("vip", OpIsNotNull, nil),
+					SearchCriteria{Fields: []string{"name", "u.email"}, Operator: OpIContains, Value: `say "hi"`},
+				),
+			},
+		},
+		{
+			name: "NOT and keyword named fields",
+			expr: "NOT(and:eq:1 OR not:eq:2) AND NOT or:is_null",
+			expected: []LogicalGroup{{
+				Operator: LogicAnd,
+				Groups: []LogicalGroup{
+					{
+						Operator: LogicOr,
+						Conditions: []SearchCriteria{
+							CreateSearchCondition("and", OpEqual, int64(1)),
+							CreateSearchCondition("not", OpEqual, int64(2)),
+						},
+						Groups: []LogicalGroup{},
+						Not:    true,
+					},
+					{
+						Operator:   LogicAnd,
+						Conditions: []SearchCriteria{CreateSearchCondition("or", OpIsNull, nil)},
+						Groups:     []LogicalGroup{},
+						Not:        true,
+					},
+				},
+			}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			params, err := ParseFilter(tt.expr)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, params.SearchGroups)
+			assert.Equal(t, NewPaginationParams(1, 10), params.Pagination)
+		})
+	}
+}
+
+// Test ParseFilter syntax errors
+func TestParseFilter_Errors(t *testing.T) {
+	tests := []struct {
+		name     string
+		expr     string
+		expected string
+	}{
+		{name: "missing value", expr: "status:eq", expected: `sqlbuilder: syntax error at offset 9: expected ':' and a value after eq, got end of input`},
+		{name: "unknown operator", expr: "a:eq:1 AND b:equals:2", expected: `sqlbuilder: syntax error at offset 13: unknown operator "equals"`},
+		{name: "struct operator", expr: "loc:within_radius:1", expected: `sqlbuilder: syntax error at offset 4: operator within_radius is not supported in filter expressions`},
+		{name: "missing field", expr: "a:eq:1 AND :eq:2", expected: `sqlbuilder: syntax error at offset 11: expected field, got ':'`},
+		{name: "missing colon", expr: "status = 1", expected: `sqlbuilder: syntax error at offset 6: expected ':' after field, got ' '`},
+		{name: "unbalanced", expr: "(a:eq:1 OR b:eq:2", expected: `sqlbuilder: syntax error at offset 17: expected ')', got end of input`},
+		{name: "trailing", expr: "a:eq:1 b:eq:2", expected: `sqlbuilder: syntax error at offset 7: unexpected 'b'`},
+		{name: "in without list", expr: "a:in:1", expected: `sqlbuilder: syntax error at offset 5: operator in expects a [list]`},
+		{name: "nested list", expr: "a:in:[[1]]", expected: `sqlbuilder: syntax error at offset 6: nested lists are not supported`},
+		{name: "unterminated string", expr: `a:eq:"x`, expected: `sqlbuilder: syntax error at offset 5: unterminated string`},
+		{name: "unterminated list", expr: "a:in:[1,2", expected: `sqlbuilder: syntax error at offset 9: expected ']', got end of input`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseFilter(tt.expr)
+			assert.ErrorIs(t, err, ErrSyntax)
+			assert.EqualError(t, err, tt.expected)
+		})
+	}
+}
+
+// Test FormatFilter output and that ParseFilter reads it back
+func TestFormatFilter(t *testing.T) {
+	group := LogicalGroup{
+		Operator: LogicOr,
+		Conditions: []SearchCriteria{
+			CreateSearchCondition("status", OpEqual, "active"),
+			CreateSearchCondition("title", OpIContains, "hello world"),
+			CreateSearchCondition("code", OpEqual, "007"),
+		},
+		Groups: []LogicalGroup{
+			CreateSearchGroup(LogicAnd,
+				CreateSearchCondition("age", OpGreaterThanEq, 18),
+				CreateSearchCondition("tags", OpNotIn, []string{"a", "b,c"}),
+			),
+			{Operator: LogicAnd, Not: true, Conditions: []SearchCriteria{CreateSearchCondition("deleted_at", OpIsNull, nil)}},
+			CreateSearchGroup(LogicAnd),
+		},
+	}
+
+	expr, err := FormatFilter(group)
+	assert.NoError(t, err)
+	assert.Equal(t, `status:eq:active OR title:icontains:"hello world" OR code:eq:"007" OR (age:gte:18 AND tags:not_in:[a,"b,c"]) OR NOT (deleted_at:is_null)`, expr)
+
+	params, err := ParseFilter(expr)
+	assert.NoError(t, err)
+	again, err := FormatFilter(params.SearchGroups[0])
+	assert.NoError(t, err)
+	assert.Equal(t, expr, again)
+
+	t.Run("values", func(t *testing.T) {
+		tests := []struct {
+			value    any
+			expected string
+		}{
+			{value: nil, expected: "null"},
+			{value: false, expected: "false"},
+			{value: 2.5, expected: "2.5"},
+			{value: 2.0, expected: "2.0"},
+			{value: float32(-3), expected: "-3.0"},
+			{value: "voilà", expected: "voilà"},
+			{value: "a\u00a0b", expected: "\"a\u00a0b\""},
+			{value: uint8(7), expected: "7"},
+			{value: "true", expected: `"true"`},
+			{value: `a\b`, expected: `a\b`},
+			{value: `"q"`, expected: `"\"q\""`},
+			{value: "", expected: `""`},
+			{value: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC), expected: "2024-01-02T03:04:05Z"},
+		}
+		for _, tt := range tests {
+			expr, err := FormatFilter(CreateSearchGroup(LogicAnd, CreateSearchCondition("f", OpEqual, tt.value)))
+			assert.NoError(t, err)
+			assert.Equal(t, "f:eq:"+tt.expected, expr)
+		}
+	})
+
+	t.Run("round trip", func(t *testing.T) {
+		for _, value := range []any{"voilà", "a\u00a0b", "东京", 2.0, int64(2), -0.5} {
+			group := CreateSearchGroup(LogicAnd, CreateSearchCondition("f", OpEqual, value))
+			expr, err := FormatFilter(group)
+			assert.NoError(t, err)
+			params, err := ParseFilter(expr)
+			assert.NoError(t, err)
+			assert.Equal(t, []LogicalGroup{group}, params.SearchGroups, expr)
+		}
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		groups := []LogicalGroup{
+			CreateSearchGroup(LogicAnd, CreateMultiFieldSearchCondition(OpIContains, "a b", true, "x", "y")),
+			CreateSearchGroup(LogicAnd, CreateSearchCondition("loc", OpWithinBBox, GeoBBox{})),
+			CreateSearchGroup(LogicAnd, CreateSearchCondition("a b", OpEqual, 1)),
+			CreateSearchGroup(LogicAnd, CreateSearchCondition("a", OpEqual, map[string]any{})),
+		}
+		for _, group := range groups {
+			_, err := FormatFilter(group)
+			assert.ErrorIs(t, err, ErrInvalidValue)
+		}
+
+		_, err := FormatFilter(LogicalGroup{Operator: "XOR"})
+		assert.ErrorIs(t, err, ErrInvalidGroupOperator)
+	})
+}
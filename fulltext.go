@@ -0,0 +1,173 @@
+package sqlbuilder
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Full-text search operators beyond OpFullText (natural language mode)
+// The criterion field may list several comma separated columns, e.g. "title,body",
+// which must match a FULLTEXT index on MySQL.
+const (
+	OpFullTextBoolean   = "full_text_boolean"   // BOOLEAN MODE, operators in the value are escaped
+	OpFullTextExpansion = "full_text_expansion" // WITH QUERY EXPANSION
+)
+
+// SortRelevance is the sort field that orders by the full-text relevance score
+const SortRelevance = "relevance"
+
+// booleanOperators are the characters with a special meaning in MySQL BOOLEAN MODE
+const booleanOperators = `+-*"~<>()@`
+
+// relevanceScore remembers the first full-text match built, so it can be selected and sorted on
+type relevanceScore struct {
+	sql  string
+	args []any
+}
+
+// RelevanceColumn returns a select expression for the relevance score of the first
+// full-text condition built, aliased as "relevance", and the arguments it needs
+// With ? placeholders the arguments belong before the WHERE parameters in the final
+// argument list. Numbered and named placeholders reuse the WHERE parameter, so no
+// extra arguments are returned. ok is false when no full-text condition was built.
+func (s *SQLBuilder) RelevanceColumn() (column string, args []any, ok bool) {
+	if s.relevance == nil {
+		return "", nil, false
+	}
+	return s.relevance.sql + " AS " + SortRelevance, s.relevance.args, true
+}
+
+// EscapeBooleanQuery makes free text safe to use with MySQL BOOLEAN MODE
+// Every word keeps an optional leading + or - and an optional trailing *, balanced
+// double quoted phrases are kept, and every other operator character is removed.
+func EscapeBooleanQuery(query string) string {
+	var terms []string
+
+	segments := []string{query}
+	quoted := strings.Count(query, `"`)%2 == 0
+	if quoted {
+		segments = strings.Split(query, `"`)
+	}
+
+	for i, segment := range segments {
+		if quoted && i%2 == 1 {
+			// Inside a phrase, operators are only separators
+			if phrase := strings.Join(strings.Fields(stripBooleanOperators(segment)), " "); phrase != "" {
+				terms = append(terms, phrasePrefix(segments[i-1])+`"`+phrase+`"`)
+			}
+			continue
+		}
+		if quoted && i+1 < len(segments) {
+			// A trailing + or - belongs to the following phrase
+			segment = strings.TrimRight(segment, "+-")
+		}
+		for _, field := range strings.Fields(segment) {
+			if term := escapeBooleanTerm(field); term != "" {
+				terms = append(terms, term)
+			}
+		}
+	}
+
+	return strings.Join(terms, " ")
+}
+
+// buildFullTextModeCondition builds a full-text match for the given operator and builder dialect
+func (s *SQLBuilder) buildFullTextModeCondition(field, operator string, value any) string {
+	columns := fullTextColumns(field)
+	if len(columns) == 0 {
+		return ""
+	}
+
+	if operator == OpFullTextBoolean {
+		value = EscapeBooleanQuery(fmt.Sprint(value))
+	}
+	placeholder := s.bind(columns[0], value)
+
+	if s.dialect == DialectPostgres {
+		document := columns[0]
+		if len(columns) > 1 {
+			document = "concat_ws(' ', " + strings.Join(columns, ", ") + ")"
+		}
+		query := "plainto_tsquery(" + placeholder + ")"
+		if operator == OpFullTextBoolean {
+			query = "websearch_to_tsquery(" + placeholder + ")"
+		}
+		s.rememberRelevance(fmt.Sprintf("ts_rank(to_tsvector(%s), %s)", document, query), placeholder, value)
+		return fmt.Sprintf("to_tsvector(%s) @@ %s", document, query)
+	}
+
+	mode := "IN NATURAL LANGUAGE MODE"
+	switch operator {
+	case OpFullTextBoolean:
+		mode = "IN BOOLEAN MODE"
+	case OpFullTextExpansion:
+		mode = "WITH QUERY EXPANSION"
+	}
+	match := fmt.Sprintf("MATCH(%s) AGAINST(%s %s)", strings.Join(columns, ", "), placeholder, mode)
+	s.rememberRelevance(match, placeholder, value)
+	return match
+}
+
+// rememberRelevance stores the relevance expression of the first full-text condition
+func (s *SQLBuilder) rememberRelevance(expression, placeholder string, value any) {
+	if s.relevance != nil {
+		return
+	}
+	score := &relevanceScore{sql: expression}
+	if placeholder == "?" {
+		score.args = []any{value}
+	}
+	s.relevance = score
+}
+
+// fullTextColumns splits a comma separated column list
+func fullTextColumns(field string) []string {
+	var columns []string
+	for _, column := range strings.Split(field, ",") {
+		if column = strings.TrimSpace(column); column != "" {
+			columns = append(columns, column)
+		}
+	}
+	return columns
+}
+
+// escapeBooleanTerm keeps a single leading +/- and trailing * around the operator free word
+func escapeBooleanTerm(term string) string {
+	prefix := ""
+	if term[0] == '+' || term[0] == '-' {
+		prefix = term[:1]
+	}
+	suffix := ""
+	if strings.HasSuffix(term, "*") {
+		suffix = "*"
+	}
+
+	words := strings.Fields(stripBooleanOperators(term))
+	if len(words) == 0 {
+		return ""
+	}
+	words[0] = prefix + words[0]
+	words[len(words)-1] += suffix
+	return strings.Join(words, " ")
+}
+
+// stripBooleanOperators replaces boolean mode operator characters with spaces
+func stripBooleanOperators(text string) string {
+	return strings.Map(func(r rune) rune {
+		if strings.ContainsRune(booleanOperators, r) {
+			return ' '
+		}
+		return r
+	}, text)
+}
+
+// phrasePrefix returns the + or - directly in front of a phrase
+func phrasePrefix(before string) string {
+	if strings.HasSuffix(before, "+") {
+		return "+"
+	}
+	if strings.HasSuffix(before, "-") {
+		return "-"
+	}
+	return ""
+}
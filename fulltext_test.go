@@ -0,0 +1,135 @@
+package sqlbuilder
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Test full-text modes per dialect
+func TestSQLBuilder_FullTextModes(t *testing.T) {
+	tests := []struct {
+		name           string
+		dialect        Dialect
+		field          string
+		operator       string
+		value          string
+		expectedSQL    string
+		expectedParams []any
+	}{
+		{
+			name:           "natural language multi column",
+			field:          "title,body",
+			operator:       OpFullText,
+			value:          "golang tips",
+			expectedSQL:    "MATCH(title, body) AGAINST(? IN NATURAL LANGUAGE MODE)",
+			expectedParams: []any{"golang tips"},
+		},
+		{
+			name:           "boolean mode escapes operators",
+			field:          "title, body",
+			operator:       OpFullTextBoolean,
+			value:          `+go -java ~(rust) "web  server"`,
+			expectedSQL:    "MATCH(title, body) AGAINST(? IN BOOLEAN MODE)",
+			expectedParams: []any{`+go -java rust "web server"`},
+		},
+		{
+			name:           "query expansion",
+			field:          "title",
+			operator:       OpFullTextExpansion,
+			value:          "database",
+			expectedSQL:    "MATCH(title) AGAINST(? WITH QUERY EXPANSION)",
+			expectedParams: []any{"database"},
+		},
+		{
+			name:           "postgres boolean",
+			dialect:        DialectPostgres,
+			field:          "title,body",
+			operator:       OpFullTextBoolean,
+			value:          "+go -java",
+			expectedSQL:    "to_tsvector(concat_ws(' ', title, body)) @@ websearch_to_tsquery($1)",
+			expectedParams: []any{"+go -java"},
+		},
+		{
+			name:           "empty field",
+			field:          " , ",
+			operator:       OpFullText,
+			value:          "go",
+			expectedSQL:    "",
+			expectedParams: []any{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			builder := NewSQLBuilder()
+			builder.SetDialect(tt.dialect)
+			assert.Equal(t, tt.expectedSQL, builder.buildCondition(tt.field, tt.operator, tt.value))
+			assert.Equal(t, tt.expectedParams, builder.GetParams())
+		})
+	}
+}
+
+// Test EscapeBooleanQuery
+func TestEscapeBooleanQuery(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{input: "plain words", expected: "plain words"},
+		{input: "+required -excluded optional*", expected: "+required -excluded optional*"},
+		{input: "++double --minus **", expected: "+double -minus"},
+		{input: "e-mail >boost <less @distance", expected: "e mail boost less distance"},
+		{input: `+"exact phrase" rest`, expected: `+"exact phrase" rest`},
+		{input: `unbalanced "quote`, expected: "unbalanced quote"},
+		{input: `"(nested) +ops*"`, expected: `"nested ops"`},
+		{input: `+ - * " ~`, expected: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			assert.Equal(t, tt.expected, EscapeBooleanQuery(tt.input))
+		})
+	}
+}
+
+// Test selecting and sorting by relevance
+func TestSQLBuilder_Relevance(t *testing.T) {
+	t.Run("mysql", func(t *testing.T) {
+		params := NewQueryParams()
+		params.AddSearch("title,body", OpFullTextBoolean, "+go")
+		params.AddSort(SortRelevance, "")
+		params.AddSort("id", SortAsc)
+
+		builder := NewSQLBuilder()
+		params.ApplySearch(builder)
+
+		column, args, ok := builder.RelevanceColumn()
+		assert.True(t, ok)
+		assert.Equal(t, "MATCH(title, body) AGAINST(? IN BOOLEAN MODE) AS relevance", column)
+		assert.Equal(t, []any{"+go"}, args)
+		assert.Equal(t, "ORDER BY relevance DESC, id ASC", params.ApplySort(builder))
+	})
+
+	t.Run("postgres reuses the where parameter", func(t *testing.T) {
+		builder := NewSQLBuilder()
+		builder.SetDialect(DialectPostgres)
+		builder.AddWhereCondition(builder.BuildFilterConditions([]FilterCriteria{
+			{Field: "status", Operator: OpEqual, Value: "published"},
+			{Field: "body", Operator: OpFullText, Value: "go"},
+		}))
+
+		column, args, ok := builder.RelevanceColumn()
+		assert.True(t, ok)
+		assert.Equal(t, "ts_rank(to_tsvector(body), plainto_tsquery($2)) AS relevance", column)
+		assert.Nil(t, args)
+	})
+
+	t.Run("relevance sort is dropped without full-text", func(t *testing.T) {
+		builder := NewSQLBuilder()
+		_, _, ok := builder.RelevanceColumn()
+		assert.False(t, ok)
+		assert.Equal(t, "", builder.BuildOrderBy([]SortCriteria{{Field: SortRelevance, Order: SortDesc}}))
+		assert.Equal(t, "ORDER BY id DESC", builder.BuildOrderBy([]SortCriteria{{Field: SortRelevance}, {Field: "id", Order: SortDesc}}))
+	})
+}
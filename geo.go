@@ -0,0 +1,212 @@
+package sqlbuilder
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Geospatial operators, the field is a POINT (MySQL) or geometry/geography (PostGIS) column
+const (
+	OpWithinRadius = "within_radius" // Value is GeoRadius
+	OpWithinBBox   = "within_bbox"   // Value is GeoBBox
+)
+
+// GeoPoint is a WGS84 coordinate
+type GeoPoint struct {
+	Lat float64 `json:"lat"`
+	Lng float64 `json:"lng"`
+}
+
+// GeoRadius is the value of OpWithinRadius
+type GeoRadius struct {
+	Lat      float64 `json:"lat"`
+	Lng      float64 `json:"lng"`
+	RadiusKm float64 `json:"radius_km"`
+}
+
+// GeoBBox is the value of OpWithinBBox
+type GeoBBox struct {
+	MinLat float64 `json:"min_lat"`
+	MinLng float64 `json:"min_lng"`
+	MaxLat float64 `json:"max_lat"`
+	MaxLng float64 `json:"max_lng"`
+}
+
+// Validate checks the coordinates
+func (p GeoPoint) Validate() error {
+	if p.Lat < -90 || p.Lat > 90 || p.Lng < -180 || p.Lng > 180 {
+		return fmt.Errorf("%w: coordinate %v,%v out of range", ErrInvalidValue, p.Lat, p.Lng)
+	}
+	return nil
+}
+
+// Validate checks the center and radius
+func (r GeoRadius) Validate() error {
+	if err := (GeoPoint{Lat: r.Lat, Lng: r.Lng}).Validate(); err != nil {
+		return err
+	}
+	if r.RadiusKm <= 0 {
+		return fmt.Errorf("%w: radius must be positive", ErrInvalidValue)
+	}
+	return nil
+}
+
+// Validate checks the corners
+func (b GeoBBox) Validate() error {
+	if err := (GeoPoint{Lat: b.MinLat, Lng: b.MinLng}).Validate(); err != nil {
+		return err
+	}
+	if err := (GeoPoint{Lat: b.MaxLat, Lng: b.MaxLng}).Validate(); err != nil {
+		return err
+	}
+	if b.MinLat > b.MaxLat || b.MinLng > b.MaxLng {
+		return fmt.Errorf("%w: bounding box minimum exceeds maximum", ErrInvalidValue)
+	}
+	return nil
+}
+
+// buildGeoCondition builds the geospatial operators for the builder dialect
+func (s *SQLBuilder) buildGeoCondition(column, field, operator string, value any) string {
+	switch operator {
+	case OpWithinRadius:
+		radius, ok := structValue[GeoRadius](value)
+		if !ok {
+			s.setErr(fmt.Errorf("%w: %s expects a GeoRadius, got %T", ErrInvalidValue, operator, value))
+			return ""
+		}
+		if err := radius.Validate(); err != nil {
+			s.setErr(err)
+			return ""
+		}
+		point := s.geoPoint(field, GeoPoint{Lat: radius.Lat, Lng: radius.Lng})
+		meters := s.bind(field, radius.RadiusKm*1000)
+		if s.dialect == DialectPostgres {
+			return fmt.Sprintf("ST_DWithin(%s::geography, %s::geography, %s)", column, point, meters)
+		}
+		return fmt.Sprintf("ST_Distance_Sphere(%s, %s) <= %s", column, point, meters)
+	case OpWithinBBox:
+		box, ok := structValue[GeoBBox](value)
+		if !ok {
+			s.setErr(fmt.Errorf("%w: %s expects a GeoBBox, got %T", ErrInvalidValue, operator, value))
+			return ""
+		}
+		if err := box.Validate(); err != nil {
+			s.setErr(err)
+			return ""
+		}
+		if s.dialect == DialectPostgres {
+			return fmt.Sprintf("ST_Within(%s::geometry, ST_MakeEnvelope(%s, %s, %s, %s, 4326))", column,
+				s.bind(field, box.MinLng), s.bind(field, box.MinLat), s.bind(field, box.MaxLng), s.bind(field, box.MaxLat))
+		}
+		// ST_MakeEnvelope is not implemented for geographic SRSs, so the box is bound as a polygon
+		return fmt.Sprintf("MBRContains(ST_GeomFromText(%s, 4326, '%s'), %s)", s.bind(field, bboxPolygon(box)), mysqlAxisOrder, column)
+	}
+	return ""
+}
+
+// mysqlAxisOrder makes MySQL read WKT longitude first, as SRID 4326 is latitude first otherwise
+const mysqlAxisOrder = "axis-order=long-lat"
+
+// bboxPolygon returns the WKT polygon of box, counter-clockwise from its south-west corner
+func bboxPolygon(box GeoBBox) string {
+	corners := [][2]float64{
+		{box.MinLng, box.MinLat}, {box.MaxLng, box.MinLat}, {box.MaxLng, box.MaxLat}, {box.MinLng, box.MaxLat}, {box.MinLng, box.MinLat},
+	}
+	points := make([]string, len(corners))
+	for i, corner := range corners {
+		points[i] = strconv.FormatFloat(corner[0], 'f', -1, 64) + " " + strconv.FormatFloat(corner[1], 'f', -1, 64)
+	}
+	return "POLYGON((" + strings.Join(points, ", ") + "))"
+}
+
+// parseBBoxPolygon returns the box of a polygon written by bboxPolygon
+func parseBBoxPolygon(polygon string) (GeoBBox, bool) {
+	inner, ok := strings.CutPrefix(polygon, "POLYGON((")
+	if inner, ok = strings.CutSuffix(inner, "))"); !ok {
+		return GeoBBox{}, false
+	}
+	points := strings.Split(inner, ",")
+	if len(points) != 5 {
+		return GeoBBox{}, false
+	}
+	var box GeoBBox
+	for i, point := range points {
+		coordinates := strings.Fields(point)
+		if len(coordinates) != 2 {
+			return GeoBBox{}, false
+		}
+		lng, lngErr := strconv.ParseFloat(coordinates[0], 64)
+		lat, latErr := strconv.ParseFloat(coordinates[1], 64)
+		if lngErr != nil || latErr != nil {
+			return GeoBBox{}, false
+		}
+		if i == 0 {
+			box = GeoBBox{MinLat: lat, MinLng: lng}
+		} else if i == 2 {
+			box.MaxLat, box.MaxLng = lat, lng
+		}
+	}
+	return box, bboxPolygon(box) == polygon
+}
+
+// buildDistanceOrder builds the distance expression used to sort by proximity to near
+func (s *SQLBuilder) buildDistanceOrder(field string, near GeoPoint) string {
+	if err := near.Validate(); err != nil {
+		s.setErr(err)
+		return ""
+	}
+	column, ok := s.resolveField(field, false)
+	if !ok {
+		return ""
+	}
+	point := s.geoPoint(field, near)
+	if s.dialect == DialectPostgres {
+		return fmt.Sprintf("ST_Distance(%s::geography, %s::geography)", column, point)
+	}
+	return fmt.Sprintf("ST_Distance_Sphere(%s, %s)", column, point)
+}
+
+// geoPoint binds a WGS84 point (SRID 4326), longitude first as both MySQL POINT and PostGIS expect
+// The SRID must match the column, MySQL refuses to compare a SRID 0 POINT with a SRID 4326 column.
+func (s *SQLBuilder) geoPoint(field string, point GeoPoint) string {
+	lng := s.bind(field, point.Lng)
+	lat := s.bind(field, point.Lat)
+	if s.dialect == DialectPostgres {
+		return fmt.Sprintf("ST_SetSRID(ST_MakePoint(%s, %s), 4326)", lng, lat)
+	}
+	return fmt.Sprintf("ST_SRID(POINT(%s, %s), 4326)", lng, lat)
+}
+
+// structValue accepts a struct value by value, by non-nil pointer or as a decoded JSON object
+// Objects such as {"lat": 52.5, "lng": 13.4, "radius_km": 5} must have every field of T.
+func structValue[T GeoRadius | GeoBBox | ArrayLength](value any) (T, bool) {
+	var zero T
+	switch v := value.(type) {
+	case T:
+		return v, true
+	case *T:
+		if v != nil {
+			return *v, true
+		}
+	case map[string]any:
+		if len(v) != reflect.TypeOf(zero).NumField() {
+			return zero, false
+		}
+		data, err := json.Marshal(v)
+		if err != nil {
+			return zero, false
+		}
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.DisallowUnknownFields()
+		var decoded T
+		if err := decoder.Decode(&decoded); err != nil {
+			return zero, false
+		}
+		return decoded, true
+	}
+	return zero, false
+}
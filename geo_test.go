@@ -0,0 +1,134 @@
+package sqlbuilder
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Test geospatial operators per dialect
+func TestSQLBuilder_GeoOperators(t *testing.T) {
+	tests := []struct {
+		name           string
+		dialect        Dialect
+		operator       string
+		value          any
+		expectedSQL    string
+		expectedParams []any
+	}{
+		{
+			name:           "mysql radius",
+			operator:       OpWithinRadius,
+			value:          GeoRadius{Lat: 52.52, Lng: 13.405, RadiusKm: 5},
+			expectedSQL:    "ST_Distance_Sphere(location, ST_SRID(POINT(?, ?), 4326)) <= ?",
+			expectedParams: []any{13.405, 52.52, 5000.0},
+		},
+		{
+			name:           "postgres radius",
+			dialect:        DialectPostgres,
+			operator:       OpWithinRadius,
+			value:          &GeoRadius{Lat: 52.52, Lng: 13.405, RadiusKm: 1.5},
+			expectedSQL:    "ST_DWithin(location::geography, ST_SetSRID(ST_MakePoint($1, $2), 4326)::geography, $3)",
+			expectedParams: []any{13.405, 52.52, 1500.0},
+		},
+		{
+			name:           "mysql bounding box",
+			operator:       OpWithinBBox,
+			value:          GeoBBox{MinLat: 52.3, MinLng: 13.0, MaxLat: 52.7, MaxLng: 13.8},
+			expectedSQL:    "MBRContains(ST_GeomFromText(?, 4326, 'axis-order=long-lat'), location)",
+			expectedParams: []any{"POLYGON((13 52.3, 13.8 52.3, 13.8 52.7, 13 52.7, 13 52.3))"},
+		},
+		{
+			name:           "decoded json radius",
+			operator:       OpWithinRadius,
+			value:          map[string]any{"lat": 52.52, "lng": 13.405, "radius_km": 5.0},
+			expectedSQL:    "ST_Distance_Sphere(location, ST_SRID(POINT(?, ?), 4326)) <= ?",
+			expectedParams: []any{13.405, 52.52, 5000.0},
+		},
+		{
+			name:           "decoded json bounding box",
+			dialect:        DialectPostgres,
+			operator:       OpWithinBBox,
+			value:          map[string]any{"min_lat": 52.3, "min_lng": 13.0, "max_lat": 52.7, "max_lng": 13.8},
+			expectedSQL:    "ST_Within(location::geometry, ST_MakeEnvelope($1, $2, $3, $4, 4326))",
+			expectedParams: []any{13.0, 52.3, 13.8, 52.7},
+		},
+		{
+			name:           "postgres bounding box",
+			dialect:        DialectPostgres,
+			operator:       OpWithinBBox,
+			value:          GeoBBox{MinLat: 52.3, MinLng: 13.0, MaxLat: 52.7, MaxLng: 13.8},
+			expectedSQL:    "ST_Within(location::geometry, ST_MakeEnvelope($1, $2, $3, $4, 4326))",
+			expectedParams: []any{13.0, 52.3, 13.8, 52.7},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			builder := NewSQLBuilder()
+			builder.SetDialect(tt.dialect)
+			assert.Equal(t, tt.expectedSQL, builder.buildCondition("location", tt.operator, tt.value))
+			assert.Equal(t, tt.expectedParams, builder.GetParams())
+			assert.NoError(t, builder.Err())
+		})
+	}
+}
+
+// Test invalid geospatial values
+func TestSQLBuilder_GeoOperatorsInvalid(t *testing.T) {
+	tests := []struct {
+		name     string
+		operator string
+		value    any
+	}{
+		{name: "wrong type", operator: OpWithinRadius, value: "52.5,13.4"},
+		{name: "nil pointer", operator: OpWithinBBox, value: (*GeoBBox)(nil)},
+		{name: "json missing field", operator: OpWithinRadius, value: map[string]any{"lat": 52.0, "lng": 13.0}},
+		{name: "json unknown field", operator: OpWithinRadius, value: map[string]any{"lat": 52.0, "lng": 13.0, "radius": 1.0}},
+		{name: "json wrong type", operator: OpWithinBBox, value: map[string]any{"min_lat": "52", "min_lng": 13.0, "max_lat": 53.0, "max_lng": 14.0}},
+		{name: "latitude out of range", operator: OpWithinRadius, value: GeoRadius{Lat: 95, Lng: 13, RadiusKm: 1}},
+		{name: "negative radius", operator: OpWithinRadius, value: GeoRadius{Lat: 52, Lng: 13, RadiusKm: -1}},
+		{name: "inverted box", operator: OpWithinBBox, value: GeoBBox{MinLat: 53, MinLng: 13, MaxLat: 52, MaxLng: 14}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			builder := NewSQLBuilder()
+			assert.Equal(t, "", builder.buildCondition("location", tt.operator, tt.value))
+			assert.Equal(t, 0, len(builder.GetParams()))
+			assert.ErrorIs(t, builder.Err(), ErrInvalidValue)
+		})
+	}
+}
+
+// Test sorting by distance
+func TestSQLBuilder_DistanceOrder(t *testing.T) {
+	t.Run("mysql", func(t *testing.T) {
+		params := NewQueryParams()
+		params.AddFilter("location", OpWithinRadius, GeoRadius{Lat: 52.52, Lng: 13.405, RadiusKm: 5})
+		params.Sort = append(params.Sort, SortCriteria{Field: "location", Order: SortAsc, Near: &GeoPoint{Lat: 52.52, Lng: 13.405}})
+		params.AddSort("name", SortAsc)
+
+		builder := NewSQLBuilder()
+		params.ApplyFilters(builder)
+		orderBy := params.ApplySort(builder)
+
+		assert.Equal(t, "ORDER BY ST_Distance_Sphere(location, ST_SRID(POINT(?, ?), 4326)) ASC, name ASC", orderBy)
+		assert.Equal(t, []any{13.405, 52.52, 5000.0, 13.405, 52.52}, builder.GetParams())
+	})
+
+	t.Run("postgres", func(t *testing.T) {
+		builder := NewSQLBuilder()
+		builder.SetDialect(DialectPostgres)
+		orderBy := builder.BuildOrderBy([]SortCriteria{{Field: "location", Near: &GeoPoint{Lat: 1, Lng: 2}}}, false)
+		assert.Equal(t, "ST_Distance(location::geography, ST_SetSRID(ST_MakePoint($1, $2), 4326)::geography) ASC", orderBy)
+		assert.Equal(t, []any{2.0, 1.0}, builder.GetParams())
+	})
+
+	t.Run("invalid point is skipped", func(t *testing.T) {
+		builder := NewSQLBuilder()
+		orderBy := builder.BuildOrderBy([]SortCriteria{{Field: "location", Near: &GeoPoint{Lat: 100}}, {Field: "id"}})
+		assert.Equal(t, "ORDER BY id ASC", orderBy)
+		assert.ErrorIs(t, builder.Err(), ErrInvalidValue)
+	})
+}
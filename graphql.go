@@ -0,0 +1,199 @@
+package sqlbuilder
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ErrInvalidWhere is returned when a GraphQL where input cannot be translated
+var ErrInvalidWhere = errors.New("sqlbuilder: invalid where input")
+
+// graphQLOperators maps Hasura comparison operators to search operators
+// _contains and _has_key are the jsonb operators, as in Hasura.
+var graphQLOperators = map[string]string{
+	"_eq":       OpEqual,
+	"_neq":      OpNotEqual,
+	"_gt":       OpGreaterThan,
+	"_gte":      OpGreaterThanEq,
+	"_lt":       OpLessThan,
+	"_lte":      OpLessThanEq,
+	"_in":       OpIn,
+	"_nin":      OpNotIn,
+	"_like":     OpLike,
+	"_nlike":    OpNotLike,
+	"_ilike":    OpILike,
+	"_nilike":   OpNotILike,
+	"_regex":    OpRegex,
+	"_nregex":   OpNotRegex,
+	"_iregex":   OpIRegex,
+	"_niregex":  OpNotIRegex,
+	"_contains": OpJSONContains,
+	"_has_key":  OpJSONHasKey,
+}
+
+// ParseGraphQLWhere translates a Hasura-style where input, as decoded from JSON, into a LogicalGroup
+// The input combines _and and _or lists, _not objects and column comparisons such as
+// {"status": {"_eq": "active"}}. Several keys in one object are ANDed, nested column objects
+// become dotted fields ({"author": {"name": {"_eq": "x"}}} compares "author.name") and
+// {"_is_null": true} maps to OpIsNull. Besides the Hasura operators, every other search operator
+// with a plain value is accepted with a leading underscore, e.g. _icontains or _within_last.
+//
+// Keys are translated in sorted order. Unknown operators, malformed values and an empty _or
+// or _in, which Hasura treats as false, return ErrInvalidWhere with the path of the offending
+// key. An empty _nin matches every row, as in Hasura.
+func ParseGraphQLWhere(where map[string]any) (LogicalGroup, error) {
+	node, err := graphQLObject(where, "where")
+	if err != nil {
+		return LogicalGroup{}, err
+	}
+	if node.group != nil {
+		return *node.group, nil
+	}
+	return CreateSearchGroup(LogicAnd, *node.criterion), nil
+}
+
+// graphQLObject translates a boolean expression object
+func graphQLObject(object map[string]any, path string) (whereNode, error) {
+	var nodes []whereNode
+	for _, key := range sortedKeys(object) {
+		value := object[key]
+		keyPath := path + "." + key
+
+		switch key {
+		case "_and", "_or":
+			items, ok := value.([]any)
+			if !ok {
+				return whereNode{}, fmt.Errorf("%w: %s expects a list, got %T", ErrInvalidWhere, keyPath, value)
+			}
+			if key == "_or" && len(items) == 0 {
+				return whereNode{}, fmt.Errorf("%w: %s is empty and would match nothing", ErrInvalidWhere, keyPath)
+			}
+			group := CreateSearchGroup(LogicAnd)
+			if key == "_or" {
+				group.Operator = LogicOr
+			}
+			for i, item := range items {
+				itemPath := fmt.Sprintf("%s[%d]", keyPath, i)
+				itemObject, ok := item.(map[string]any)
+				if !ok {
+					return whereNode{}, fmt.Errorf("%w: %s expects an object, got %T", ErrInvalidWhere, itemPath, item)
+				}
+				node, err := graphQLObject(itemObject, itemPath)
+				if err != nil {
+					return whereNode{}, err
+				}
+				addWhereNode(&group, node)
+			}
+			nodes = append(nodes, whereNode{group: &group})
+		case "_not":
+			notObject, ok := value.(map[string]any)
+			if !ok {
+				return whereNode{}, fmt.Errorf("%w: %s expects an object, got %T", ErrInvalidWhere, keyPath, value)
+			}
+			node, err := graphQLObject(notObject, keyPath)
+			if err != nil {
+				return whereNode{}, err
+			}
+			nodes = append(nodes, negateNode(node))
+		default:
+			fieldNodes, err := graphQLField(key, value, keyPath)
+			if err != nil {
+				return whereNode{}, err
+			}
+			nodes = append(nodes, fieldNodes...)
+		}
+	}
+
+	if len(nodes) == 1 {
+		return nodes[0], nil
+	}
+	group := CreateSearchGroup(LogicAnd)
+	for _, node := range nodes {
+		addWhereNode(&group, node)
+	}
+	return whereNode{group: &group}, nil
+}
+
+// graphQLField translates the comparison object of a field, recursing into nested fields
+func graphQLField(field string, value any, path string) ([]whereNode, error) {
+	if strings.HasPrefix(field, "_") {
+		return nil, fmt.Errorf("%w: unknown key %s", ErrInvalidWhere, path)
+	}
+	if !jsonSegmentPattern.MatchString(field) {
+		return nil, fmt.Errorf("%w: invalid field name %s", ErrInvalidWhere, path)
+	}
+	comparisons, ok := value.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s expects a comparison object, got %T", ErrInvalidWhere, path, value)
+	}
+
+	var nodes []whereNode
+	for _, key := range sortedKeys(comparisons) {
+		value := comparisons[key]
+		keyPath := path + "." + key
+
+		if !strings.HasPrefix(key, "_") {
+			nested, err := graphQLField(key, value, keyPath)
+			if err != nil {
+				return nil, err
+			}
+			for _, node := range nested {
+				node.criterion.Field = field + "." + node.criterion.Field
+				nodes = append(nodes, node)
+			}
+			continue
+		}
+
+		criterion, err := graphQLComparison(field, key, value, keyPath)
+		if err != nil {
+			return nil, err
+		}
+		nodes = append(nodes, whereNode{criterion: &criterion})
+	}
+	return nodes, nil
+}
+
+// graphQLComparison translates a single operator of a comparison object
+func graphQLComparison(field, key string, value any, path string) (SearchCriteria, error) {
+	if key == "_is_null" {
+		isNull, ok := value.(bool)
+		if !ok {
+			return SearchCriteria{}, fmt.Errorf("%w: %s expects a boolean, got %T", ErrInvalidWhere, path, value)
+		}
+		if isNull {
+			return CreateSearchCondition(field, OpIsNull, nil), nil
+		}
+		return CreateSearchCondition(field, OpIsNotNull, nil), nil
+	}
+
+	operator, ok := graphQLOperators[key]
+	if !ok {
+		operator = strings.TrimPrefix(key, "_")
+		if !IsOperator(operator) || structValueOperators[operator] || operator == OpIsNull || operator == OpIsNotNull {
+			return SearchCriteria{}, fmt.Errorf("%w: unknown operator %s", ErrInvalidWhere, path)
+		}
+	}
+
+	if operator == OpIn || operator == OpNotIn {
+		items, ok := value.([]any)
+		if !ok {
+			return SearchCriteria{}, fmt.Errorf("%w: %s expects a list, got %T", ErrInvalidWhere, path, value)
+		}
+		if operator == OpIn && len(items) == 0 {
+			return SearchCriteria{}, fmt.Errorf("%w: %s is empty and would match nothing", ErrInvalidWhere, path)
+		}
+	}
+	return CreateSearchCondition(field, operator, value), nil
+}
+
+// sortedKeys returns the keys of m in sorted order
+func sortedKeys(m map[string]any) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,141 @@
+package sqlbuilder
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Test ParseGraphQLWhere on inputs decoded from JSON
+func TestParseGraphQLWhere(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected LogicalGroup
+	}{
+		{
+			name:     "empty",
+			input:    `{}`,
+			expected: CreateSearchGroup(LogicAnd),
+		},
+		{
+			name:     "single comparison",
+			input:    `{"status": {"_eq": "active"}}`,
+			expected: CreateSearchGroup(LogicAnd, CreateSearchCondition("status", OpEqual, "active")),
+		},
+		{
+			name:  "and with nested or",
+			input: `{"_and": [{"status": {"_eq": "active"}}, {"_or": [{"age": {"_gte": 18}}, {"tags": {"_in": ["a", "b"]}}]}]}`,
+			expected: LogicalGroup{
+				Operator:   LogicAnd,
+				Conditions: []SearchCriteria{CreateSearchCondition("status", OpEqual, "active")},
+				Groups: []LogicalGroup{
+					CreateSearchGroup(LogicOr,
+						CreateSearchCondition("age", OpGreaterThanEq, float64(18)),
+						CreateSearchCondition("tags", OpIn, []any{"a", "b"}),
+					),
+				},
+			},
+		},
+		{
+			name:  "implicit and in sorted key order",
+			input: `{"price": {"_lt": 100, "_gt": 10}, "name": {"_ilike": "%go%"}, "deleted_at": {"_is_null": true}}`,
+			expected: CreateSearchGroup(LogicAnd,
+				CreateSearchCondition("deleted_at", OpIsNull, nil),
+				CreateSearchCondition("name", OpILike, "%go%"),
+				CreateSearchCondition("price", OpGreaterThan, float64(10)),
+				CreateSearchCondition("price", OpLessThan, float64(100)),
+			),
+		},
+		{
+			name:  "not",
+			input: `{"_not": {"_or": [{"role": {"_nin": ["bot"]}}, {"email": {"_is_null": false}}]}}`,
+			expected: LogicalGroup{
+				Operator: LogicOr,
+				Conditions: []SearchCriteria{
+					CreateSearchCondition("role", OpNotIn, []any{"bot"}),
+					CreateSearchCondition("email", OpIsNotNull, nil),
+				},
+				Groups: []LogicalGroup{},
+				Not:    true,
+			},
+		},
+		{
+			name:  "nested fields, jsonb and extra operators",
+			input: `{"author": {"profile": {"city": {"_neq": "Paris"}}, "name": {"_icontains": "ann"}}, "metadata": {"_contains": {"color": "red"}, "_has_key": "size"}, "created_at": {"_within_last": "7d"}}`,
+			expected: CreateSearchGroup(LogicAnd,
+				CreateSearchCondition("author.name", OpIContains, "ann"),
+				CreateSearchCondition("author.profile.city", OpNotEqual, "Paris"),
+				CreateSearchCondition("created_at", OpWithinLast, "7d"),
+				CreateSearchCondition("metadata", OpJSONContains, map[string]any{"color": "red"}),
+				CreateSearchCondition("metadata", OpJSONHasKey, "size"),
+			),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var input map[string]any
+			assert.NoError(t, json.Unmarshal([]byte(tt.input), &input))
+
+			group, err := ParseGraphQLWhere(input)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, group)
+		})
+	}
+}
+
+// Test ParseGraphQLWhere renders like the equivalent REST group
+func TestParseGraphQLWhere_SQL(t *testing.T) {
+	var input map[string]any
+	assert.NoError(t, json.Unmarshal([]byte(`{"status": {"_eq": "active"}, "_or": [{"age": {"_gte": 18}}, {"vip": {"_eq": true}}]}`), &input))
+
+	group, err := ParseGraphQLWhere(input)
+	assert.NoError(t, err)
+
+	builder := NewSQLBuilder()
+	assert.Equal(t, "(status = ? AND ((age >= ? OR vip = ?)))", builder.BuildAdvancedSearchConditions([]LogicalGroup{group}))
+	assert.Equal(t, []any{"active", float64(18), true}, builder.GetParams())
+
+	var empty map[string]any
+	assert.NoError(t, json.Unmarshal([]byte(`{"status": {"_eq": "active"}, "tenant": {"_nin": []}}`), &empty))
+	group, err = ParseGraphQLWhere(empty)
+	assert.NoError(t, err)
+
+	builder = NewSQLBuilder()
+	assert.Equal(t, "(status = ?)", builder.BuildAdvancedSearchConditions([]LogicalGroup{group}))
+	assert.NoError(t, builder.Err())
+}
+
+// Test ParseGraphQLWhere errors
+func TestParseGraphQLWhere_Errors(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{name: "unknown operator", input: `{"status": {"_equals": 1}}`, expected: "sqlbuilder: invalid where input: unknown operator where.status._equals"},
+		{name: "struct operator", input: `{"loc": {"_within_radius": {"lat": 1}}}`, expected: "sqlbuilder: invalid where input: unknown operator where.loc._within_radius"},
+		{name: "unknown key", input: `{"_xor": []}`, expected: "sqlbuilder: invalid where input: unknown key where._xor"},
+		{name: "invalid field", input: `{"a-b": {"_eq": 1}}`, expected: "sqlbuilder: invalid where input: invalid field name where.a-b"},
+		{name: "and not a list", input: `{"_and": {"a": {"_eq": 1}}}`, expected: "sqlbuilder: invalid where input: where._and expects a list, got map[string]interface {}"},
+		{name: "item not an object", input: `{"_or": [1]}`, expected: "sqlbuilder: invalid where input: where._or[0] expects an object, got float64"},
+		{name: "empty or", input: `{"_or": []}`, expected: "sqlbuilder: invalid where input: where._or is empty and would match nothing"},
+		{name: "comparison not an object", input: `{"status": "active"}`, expected: "sqlbuilder: invalid where input: where.status expects a comparison object, got string"},
+		{name: "in not a list", input: `{"_and": [{"id": {"_in": 1}}]}`, expected: "sqlbuilder: invalid where input: where._and[0].id._in expects a list, got float64"},
+		{name: "empty in", input: `{"tenant": {"_in": []}}`, expected: "sqlbuilder: invalid where input: where.tenant._in is empty and would match nothing"},
+		{name: "is_null not a boolean", input: `{"_not": {"id": {"_is_null": "yes"}}}`, expected: "sqlbuilder: invalid where input: where._not.id._is_null expects a boolean, got string"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var input map[string]any
+			assert.NoError(t, json.Unmarshal([]byte(tt.input), &input))
+
+			_, err := ParseGraphQLWhere(input)
+			assert.ErrorIs(t, err, ErrInvalidWhere)
+			assert.EqualError(t, err, tt.expected)
+		})
+	}
+}
@@ -0,0 +1,272 @@
+package sqlbuilder
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// JSON operators, the field names a JSON column or a path inside one
+const (
+	OpJSONContains = "json_contains" // The JSON document at field contains the value
+	OpJSONHasKey   = "json_has_key"  // The JSON object at field has the key given as value
+)
+
+// ErrJSONPathNotAllowed is returned for JSON paths that are not on the schema allow-list
+var ErrJSONPathNotAllowed = errors.New("sqlbuilder: JSON path not allowed")
+
+// jsonSegmentPattern restricts path segments to plain identifiers
+var jsonSegmentPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// jsonPath is a path inside a JSON column
+type jsonPath struct {
+	column   string
+	segments []string
+}
+
+// resolveField turns JSON path fields such as metadata.color into a dialect specific
+// extraction expression. Plain fields, including table qualified ones like u.name, are
+// returned unchanged. ok is false when the field is a JSON path that is not allowed.
+// A numeric extraction is cast to a number, so it compares as one instead of as text.
+func (s *SQLBuilder) resolveField(field string, numeric bool) (column string, ok bool) {
+	path, isJSON, err := s.parseJSONPath(field)
+	if err == nil && isJSON {
+		err = s.checkJSONPath(path)
+	}
+	if err != nil {
+		s.setErr(err)
+		return "", false
+	}
+	if !isJSON || len(path.segments) == 0 {
+		return field, true
+	}
+	if numeric {
+		return s.jsonNumber(path), true
+	}
+	return s.jsonText(path), true
+}
+
+// parseJSONPath splits a field into a JSON column and path, without checking the allow-list
+// The arrow form always denotes a JSON path. The dotted form is only a JSON path when
+// the part before the first dot is a JSON column of the schema.
+func (s *SQLBuilder) parseJSONPath(field string) (jsonPath, bool, error) {
+	arrow := strings.Contains(field, "->")
+	parts := strings.Split(strings.ReplaceAll(field, "->", "."), ".")
+	column := strings.TrimSpace(parts[0])
+
+	if !s.schema.IsJSONColumn(column) {
+		if arrow {
+			return jsonPath{}, false, fmt.Errorf("%w: %s is not a JSON column", ErrJSONPathNotAllowed, column)
+		}
+		return jsonPath{}, false, nil
+	}
+
+	path := jsonPath{column: column}
+	for _, segment := range parts[1:] {
+		segment = strings.TrimSpace(segment)
+		if !jsonSegmentPattern.MatchString(segment) {
+			return jsonPath{}, false, fmt.Errorf("%w: invalid segment %q in %s", ErrJSONPathNotAllowed, segment, field)
+		}
+		path.segments = append(path.segments, segment)
+	}
+
+	return path, true, nil
+}
+
+// checkJSONPath returns an error unless the path is on the allow-list of its column
+// The column itself, without a path, is always allowed.
+func (s *SQLBuilder) checkJSONPath(path jsonPath) error {
+	if len(path.segments) > 0 && !s.schema.jsonColumns[path.column][strings.Join(path.segments, ".")] {
+		return fmt.Errorf("%w: %s.%s", ErrJSONPathNotAllowed, path.column, strings.Join(path.segments, "."))
+	}
+	return nil
+}
+
+// documentPaths returns the key paths of an encoded JSON document, a and a.b for {"a": {"b": 1}}
+// Keys of objects inside arrays extend the path of the array. Keys that are not plain
+// identifiers are rejected, as they cannot be on the allow-list.
+func documentPaths(document []byte) ([][]string, error) {
+	decoder := json.NewDecoder(bytes.NewReader(document))
+	decoder.UseNumber()
+	var decoded any
+	if err := decoder.Decode(&decoded); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidValue, err)
+	}
+	var paths [][]string
+	var walk func(node any, prefix []string) error
+	walk = func(node any, prefix []string) error {
+		switch node := node.(type) {
+		case map[string]any:
+			for key, child := range node {
+				if !jsonSegmentPattern.MatchString(key) {
+					return fmt.Errorf("%w: invalid key %q", ErrJSONPathNotAllowed, key)
+				}
+				path := append(append([]string{}, prefix...), key)
+				paths = append(paths, path)
+				if err := walk(child, path); err != nil {
+					return err
+				}
+			}
+		case []any:
+			for _, child := range node {
+				if err := walk(child, prefix); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	}
+	if err := walk(decoded, nil); err != nil {
+		return nil, err
+	}
+	sort.Slice(paths, func(i, j int) bool { return strings.Join(paths[i], ".") < strings.Join(paths[j], ".") })
+	return paths, nil
+}
+
+// jsonValueFields returns the JSON paths a JSON operator reads besides field itself
+// json_has_key reads the key below field, json_contains every key path of its document.
+func jsonValueFields(field, operator string, value any) []string {
+	switch operator {
+	case OpJSONHasKey:
+		return []string{field + "." + fmt.Sprint(value)}
+	case OpJSONContains:
+		document, err := json.Marshal(value)
+		if err != nil {
+			return nil
+		}
+		keys, err := documentPaths(document)
+		if err != nil {
+			return nil
+		}
+		fields := make([]string, len(keys))
+		for i, key := range keys {
+			fields[i] = field + "." + strings.Join(key, ".")
+		}
+		return fields
+	}
+	return nil
+}
+
+// buildJSONCondition builds the JSON operators
+func (s *SQLBuilder) buildJSONCondition(field, operator string, value any) string {
+	path, isJSON, err := s.parseJSONPath(field)
+	if err == nil && !isJSON {
+		err = fmt.Errorf("%w: %s is not a JSON column", ErrJSONPathNotAllowed, field)
+	}
+	if err != nil {
+		s.setErr(err)
+		return ""
+	}
+
+	switch operator {
+	case OpJSONContains:
+		if err := s.checkJSONPath(path); err != nil {
+			s.setErr(err)
+			return ""
+		}
+		document, err := json.Marshal(value)
+		if err != nil {
+			s.setErr(fmt.Errorf("sqlbuilder: encode %s value: %w", field, err))
+			return ""
+		}
+		keys, err := documentPaths(document)
+		if err != nil {
+			s.setErr(err)
+			return ""
+		}
+		for _, key := range keys {
+			keyPath := jsonPath{column: path.column, segments: append(append([]string{}, path.segments...), key...)}
+			if err := s.checkJSONPath(keyPath); err != nil {
+				s.setErr(err)
+				return ""
+			}
+		}
+		placeholder := s.bind(field, string(document))
+		if s.dialect == DialectPostgres {
+			return fmt.Sprintf("%s @> %s::jsonb", s.jsonValue(path), placeholder)
+		}
+		if len(path.segments) == 0 {
+			return fmt.Sprintf("JSON_CONTAINS(%s, %s)", path.column, placeholder)
+		}
+		return fmt.Sprintf("JSON_CONTAINS(%s, %s, '%s')", path.column, placeholder, mysqlJSONPath(path.segments))
+	case OpJSONHasKey:
+		key := fmt.Sprint(value)
+		keyPath := jsonPath{column: path.column, segments: append(append([]string{}, path.segments...), key)}
+		if !jsonSegmentPattern.MatchString(key) {
+			s.setErr(fmt.Errorf("%w: invalid key %q", ErrJSONPathNotAllowed, key))
+			return ""
+		}
+		if err := s.checkJSONPath(keyPath); err != nil {
+			s.setErr(err)
+			return ""
+		}
+		if s.dialect == DialectPostgres {
+			return fmt.Sprintf("jsonb_exists(%s, %s)", s.jsonValue(path), s.bind(field, key))
+		}
+		return fmt.Sprintf("JSON_CONTAINS_PATH(%s, 'one', '%s')", path.column, mysqlJSONPath(keyPath.segments))
+	}
+	return ""
+}
+
+// jsonText extracts the path as text, suitable for the comparison operators
+func (s *SQLBuilder) jsonText(path jsonPath) string {
+	if s.dialect == DialectPostgres {
+		if len(path.segments) == 1 {
+			return fmt.Sprintf("%s->>'%s'", path.column, path.segments[0])
+		}
+		return fmt.Sprintf("%s#>>'{%s}'", path.column, strings.Join(path.segments, ","))
+	}
+	return fmt.Sprintf("JSON_UNQUOTE(JSON_EXTRACT(%s, '%s'))", path.column, mysqlJSONPath(path.segments))
+}
+
+// jsonNumber extracts the path as a number, for comparisons with numeric values
+// MySQL casts to DECIMAL(65, 30) as a bare DECIMAL would drop the fraction.
+func (s *SQLBuilder) jsonNumber(path jsonPath) string {
+	if s.dialect == DialectPostgres {
+		return fmt.Sprintf("(%s)::numeric", s.jsonText(path))
+	}
+	return fmt.Sprintf("CAST(%s AS DECIMAL(65, 30))", s.jsonText(path))
+}
+
+// numericComparison returns true if operator compares the field with numeric values only
+func numericComparison(operator string, value any) bool {
+	switch operator {
+	case OpEqual, OpNotEqual, OpGreaterThan, OpGreaterThanEq, OpLessThan, OpLessThanEq:
+		_, ok := numericValue(value)
+		return ok
+	case OpIn, OpNotIn:
+		values, ok := listValues(value)
+		return ok && numericValues(values)
+	}
+	return false
+}
+
+// numericValues returns true if values is not empty and holds Go numbers only
+func numericValues(values []any) bool {
+	for _, value := range values {
+		if _, ok := numericValue(value); !ok {
+			return false
+		}
+	}
+	return len(values) > 0
+}
+
+// jsonValue extracts the path as a JSON document (Postgres only)
+func (s *SQLBuilder) jsonValue(path jsonPath) string {
+	switch len(path.segments) {
+	case 0:
+		return path.column
+	case 1:
+		return fmt.Sprintf("%s->'%s'", path.column, path.segments[0])
+	}
+	return fmt.Sprintf("%s#>'{%s}'", path.column, strings.Join(path.segments, ","))
+}
+
+// mysqlJSONPath formats segments as a MySQL JSON path such as $.size.width
+func mysqlJSONPath(segments []string) string {
+	return "$." + strings.Join(segments, ".")
+}
@@ -0,0 +1,184 @@
+package sqlbuilder
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Test JSON path fields and operators
+func TestSQLBuilder_JSONPaths(t *testing.T) {
+	schema := NewSchema().
+		AllowJSONPaths("metadata", "color", "size.width", "tags")
+
+	tests := []struct {
+		name           string
+		dialect        Dialect
+		field          string
+		operator       string
+		value          any
+		expectedSQL    string
+		expectedParams []any
+	}{
+		{
+			name:           "dotted path",
+			field:          "metadata.color",
+			operator:       OpEqual,
+			value:          "red",
+			expectedSQL:    "JSON_UNQUOTE(JSON_EXTRACT(metadata, '$.color')) = ?",
+			expectedParams: []any{"red"},
+		},
+		{
+			name:           "arrow path",
+			field:          "metadata->size->width",
+			operator:       OpGreaterThan,
+			value:          10,
+			expectedSQL:    "CAST(JSON_UNQUOTE(JSON_EXTRACT(metadata, '$.size.width')) AS DECIMAL(65, 30)) > ?",
+			expectedParams: []any{10},
+		},
+		{
+			name:           "postgres numeric comparison",
+			dialect:        DialectPostgres,
+			field:          "metadata.size.width",
+			operator:       OpLessThanEq,
+			value:          2.5,
+			expectedSQL:    "(metadata#>>'{size,width}')::numeric <= $1",
+			expectedParams: []any{2.5},
+		},
+		{
+			name:           "numeric in list",
+			dialect:        DialectPostgres,
+			field:          "metadata.color",
+			operator:       OpIn,
+			value:          []any{1, 2},
+			expectedSQL:    "(metadata->>'color')::numeric IN ($1, $2)",
+			expectedParams: []any{1, 2},
+		},
+		{
+			name:           "mixed in list compares text",
+			field:          "metadata.color",
+			operator:       OpIn,
+			value:          []any{"red", 2},
+			expectedSQL:    "JSON_UNQUOTE(JSON_EXTRACT(metadata, '$.color')) IN (?, ?)",
+			expectedParams: []any{"red", 2},
+		},
+		{
+			name:           "numeric pattern stays text",
+			field:          "metadata.color",
+			operator:       OpContains,
+			value:          5,
+			expectedSQL:    "JSON_UNQUOTE(JSON_EXTRACT(metadata, '$.color')) LIKE ?",
+			expectedParams: []any{"%5%"},
+		},
+		{
+			name:           "postgres single segment",
+			dialect:        DialectPostgres,
+			field:          "metadata.color",
+			operator:       OpIContains,
+			value:          "re",
+			expectedSQL:    "LOWER(metadata->>'color') LIKE LOWER($1)",
+			expectedParams: []any{"%re%"},
+		},
+		{
+			name:           "postgres nested path",
+			dialect:        DialectPostgres,
+			field:          "metadata.size.width",
+			operator:       OpIsNotNull,
+			expectedSQL:    "metadata#>>'{size,width}' IS NOT NULL",
+			expectedParams: []any{},
+		},
+		{
+			name:           "table qualified column is untouched",
+			field:          "u.name",
+			operator:       OpEqual,
+			value:          "john",
+			expectedSQL:    "u.name = ?",
+			expectedParams: []any{"john"},
+		},
+		{
+			name:           "json contains",
+			field:          "metadata.tags",
+			operator:       OpJSONContains,
+			value:          []string{"sale"},
+			expectedSQL:    "JSON_CONTAINS(metadata, ?, '$.tags')",
+			expectedParams: []any{`["sale"]`},
+		},
+		{
+			name:           "json contains whole column on postgres",
+			dialect:        DialectPostgres,
+			field:          "metadata",
+			operator:       OpJSONContains,
+			value:          map[string]any{"color": "red"},
+			expectedSQL:    "metadata @> $1::jsonb",
+			expectedParams: []any{`{"color":"red"}`},
+		},
+		{
+			name:           "json has key",
+			field:          "metadata",
+			operator:       OpJSONHasKey,
+			value:          "color",
+			expectedSQL:    "JSON_CONTAINS_PATH(metadata, 'one', '$.color')",
+			expectedParams: []any{},
+		},
+		{
+			name:           "json has nested key on postgres",
+			dialect:        DialectPostgres,
+			field:          "metadata.size",
+			operator:       OpJSONHasKey,
+			value:          "width",
+			expectedSQL:    "jsonb_exists(metadata->'size', $1)",
+			expectedParams: []any{"width"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			builder := NewSQLBuilder()
+			builder.SetDialect(tt.dialect)
+			builder.SetSchema(schema)
+			assert.Equal(t, tt.expectedSQL, builder.buildCondition(tt.field, tt.operator, tt.value))
+			assert.Equal(t, tt.expectedParams, builder.GetParams())
+			assert.NoError(t, builder.Err())
+		})
+	}
+}
+
+// Test JSON path allow-list enforcement
+func TestSQLBuilder_JSONPathsRejected(t *testing.T) {
+	schema := NewSchema().AllowJSONPaths("metadata", "color")
+
+	tests := []struct {
+		name     string
+		schema   *Schema
+		field    string
+		operator string
+		value    any
+		result   string
+		err      error
+	}{
+		{name: "path not allowed", schema: schema, field: "metadata.secret", operator: OpEqual, value: "x"},
+		{name: "injection attempt", schema: schema, field: "metadata->color') OR 1=1 --", operator: OpEqual, value: "x", result: scopeNever, err: ErrInvalidField},
+		{name: "arrow on unknown column", schema: schema, field: "settings->color", operator: OpEqual, value: "x"},
+		{name: "arrow without schema", field: "metadata->color", operator: OpEqual, value: "x"},
+		{name: "in list", schema: schema, field: "metadata.size", operator: OpIn, value: []any{"s", "m"}},
+		{name: "has key not allowed", schema: schema, field: "metadata", operator: OpJSONHasKey, value: "secret"},
+		{name: "contains key not allowed", schema: schema, field: "metadata", operator: OpJSONContains, value: map[string]any{"color": "red", "secret": 1}},
+		{name: "contains nested key not allowed", schema: schema, field: "metadata", operator: OpJSONContains, value: map[string]any{"color": []any{map[string]any{"secret": 1}}}},
+		{name: "has key injection", schema: schema, field: "metadata", operator: OpJSONHasKey, value: "a' OR '1"},
+		{name: "contains on plain column", schema: schema, field: "title", operator: OpJSONContains, value: "x"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			builder := NewSQLBuilder()
+			builder.SetSchema(tt.schema)
+			result := builder.BuildFilterConditions([]FilterCriteria{{Field: tt.field, Operator: tt.operator, Value: tt.value}})
+			if tt.err == nil {
+				tt.err = ErrJSONPathNotAllowed
+			}
+			assert.Equal(t, tt.result, result)
+			assert.Equal(t, 0, len(builder.GetParams()))
+			assert.ErrorIs(t, builder.Err(), tt.err)
+		})
+	}
+}
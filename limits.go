@@ -0,0 +1,234 @@
+package sqlbuilder
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// ErrLimitExceeded is matched by every LimitError
+var ErrLimitExceeded = errors.New("sqlbuilder: query limit exceeded")
+
+// Limits reported by LimitError
+const (
+	LimitDepth       = "depth"
+	LimitConditions  = "conditions"
+	LimitInValues    = "in_values"
+	LimitValueLength = "value_length"
+	LimitRegexLength = "regex_length"
+	LimitSortKeys    = "sort_keys"
+)
+
+// QueryLimits bounds the size of client-supplied queries, a zero field means unlimited
+type QueryLimits struct {
+	MaxDepth       int // Nesting of search groups, a top-level group has depth 1
+	MaxConditions  int // Rendered conditions across search, search groups and filters, see CheckLimits
+	MaxInValues    int // Values in the list of an in, not_in or array operator
+	MaxValueLength int // Bytes of a string value, including list items
+	MaxRegexLength int // Bytes of a regex operator pattern
+	MaxSortKeys    int // Sort criteria
+}
+
+// DefaultQueryLimits returns limits suitable for public endpoints
+func DefaultQueryLimits() QueryLimits {
+	return QueryLimits{
+		MaxDepth:       5,
+		MaxConditions:  50,
+		MaxInValues:    100,
+		MaxValueLength: 1024,
+		MaxRegexLength: 256,
+		MaxSortKeys:    5,
+	}
+}
+
+// LimitError reports the first limit a query exceeds
+type LimitError struct {
+	Limit  string // One of the Limit constants
+	Max    int
+	Actual int
+	Field  string // Field of the offending criterion, empty for depth, conditions and sort keys
+}
+
+// Error describes the exceeded limit
+func (e *LimitError) Error() string {
+	if e.Field != "" {
+		return fmt.Sprintf("%s: %s of %s is %d, max %d", ErrLimitExceeded, e.Limit, e.Field, e.Actual, e.Max)
+	}
+	return fmt.Sprintf("%s: %s is %d, max %d", ErrLimitExceeded, e.Limit, e.Actual, e.Max)
+}
+
+// Is makes errors.Is(err, ErrLimitExceeded) match any LimitError
+func (e *LimitError) Is(target error) bool {
+	return target == ErrLimitExceeded
+}
+
+// SetQueryLimits enables complexity limits on the criteria built afterwards
+// Passing nil disables the limits, which is the default. The criteria of a build call are
+// checked before any SQL is generated; if they exceed a limit nothing is built and Err
+// returns a *LimitError. Conditions are counted across all build calls of the builder.
+func (s *SQLBuilder) SetQueryLimits(limits *QueryLimits) {
+	s.queryLimits = limits
+}
+
+// CheckLimits returns a *LimitError if the query exceeds limits
+// Conditions are counted as they render: a criterion counts once per field and, with Tokenize,
+// once per word of its value. Field groups of schema count once per member, schema may be nil.
+func (q *QueryParams) CheckLimits(limits QueryLimits, schema *Schema) error {
+	checker := &limitChecker{limits: limits, schema: schema}
+	if err := checker.search(q.Search); err != nil {
+		return err
+	}
+	if err := checker.filters(q.Filters); err != nil {
+		return err
+	}
+	if err := checker.sort(q.Sort); err != nil {
+		return err
+	}
+	return checker.total()
+}
+
+// CheckLimits returns a *LimitError if the query exceeds limits, counted as in QueryParams.CheckLimits
+func (q *AdvancedQueryParams) CheckLimits(limits QueryLimits, schema *Schema) error {
+	checker := &limitChecker{limits: limits, schema: schema}
+	if err := checker.groups(q.SearchGroups, 1); err != nil {
+		return err
+	}
+	if err := checker.filters(q.Filters); err != nil {
+		return err
+	}
+	if err := checker.sort(q.Sort); err != nil {
+		return err
+	}
+	return checker.total()
+}
+
+// checkLimits runs check against the builder limits and keeps the condition count on success
+func (s *SQLBuilder) checkLimits(check func(checker *limitChecker) error) error {
+	if s.queryLimits == nil {
+		return nil
+	}
+
+	checker := &limitChecker{limits: *s.queryLimits, schema: s.schema, conditions: s.limitConditions}
+	if err := check(checker); err != nil {
+		return err
+	}
+	if err := checker.total(); err != nil {
+		return err
+	}
+	s.limitConditions = checker.conditions
+	return nil
+}
+
+// limitChecker walks criteria and counts conditions against QueryLimits
+type limitChecker struct {
+	limits     QueryLimits
+	schema     *Schema
+	conditions int
+}
+
+// exceeds returns true if actual is above a non-zero limit
+func exceeds(actual, limit int) bool {
+	return limit > 0 && actual > limit
+}
+
+// total checks the number of conditions seen so far
+func (c *limitChecker) total() error {
+	if exceeds(c.conditions, c.limits.MaxConditions) {
+		return &LimitError{Limit: LimitConditions, Max: c.limits.MaxConditions, Actual: c.conditions}
+	}
+	return nil
+}
+
+// search checks search criteria, counting every field and word they render
+// The count is checked after each condition, so hostile field lists are never fully walked.
+func (c *limitChecker) search(criteria []SearchCriteria) error {
+	for _, criterion := range criteria {
+		values := criterion.renderedValues()
+		for _, field := range c.schema.expandFields(criterion.renderedFields()) {
+			for _, value := range values {
+				if err := c.criterion(field, criterion.Operator, value); err != nil {
+					return err
+				}
+				if err := c.total(); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// filters checks filter criteria, a field group counting once per member
+func (c *limitChecker) filters(filters []FilterCriteria) error {
+	for _, filter := range filters {
+		for _, field := range c.schema.expandFields([]string{filter.Field}) {
+			if err := c.criterion(field, filter.Operator, filter.Value); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// groups checks groups at depth and their nested groups
+// The walk stops as soon as a limit is exceeded, so hostile nesting is never fully traversed.
+func (c *limitChecker) groups(groups []LogicalGroup, depth int) error {
+	for _, group := range groups {
+		if exceeds(depth, c.limits.MaxDepth) {
+			return &LimitError{Limit: LimitDepth, Max: c.limits.MaxDepth, Actual: depth}
+		}
+		if err := c.search(group.Conditions); err != nil {
+			return err
+		}
+		if err := c.total(); err != nil {
+			return err
+		}
+		if err := c.groups(group.Groups, depth+1); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// sort checks the number of sort keys
+func (c *limitChecker) sort(sort []SortCriteria) error {
+	if exceeds(len(sort), c.limits.MaxSortKeys) {
+		return &LimitError{Limit: LimitSortKeys, Max: c.limits.MaxSortKeys, Actual: len(sort)}
+	}
+	return nil
+}
+
+// criterion counts a condition and checks the size of its value
+func (c *limitChecker) criterion(field, operator string, value any) error {
+	c.conditions++
+
+	switch operator {
+	case OpRegex, OpIRegex, OpNotRegex, OpNotIRegex:
+		if pattern, ok := value.(string); ok && exceeds(len(pattern), c.limits.MaxRegexLength) {
+			return &LimitError{Limit: LimitRegexLength, Max: c.limits.MaxRegexLength, Actual: len(pattern), Field: field}
+		}
+	}
+
+	if !isSliceValue(value) {
+		return c.value(field, value)
+	}
+
+	list := reflect.ValueOf(value)
+	if exceeds(list.Len(), c.limits.MaxInValues) {
+		return &LimitError{Limit: LimitInValues, Max: c.limits.MaxInValues, Actual: list.Len(), Field: field}
+	}
+	for i := 0; i < list.Len(); i++ {
+		if err := c.value(field, list.Index(i).Interface()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// value checks the length of a string value
+func (c *limitChecker) value(field string, value any) error {
+	if text, ok := value.(string); ok && exceeds(len(text), c.limits.MaxValueLength) {
+		return &LimitError{Limit: LimitValueLength, Max: c.limits.MaxValueLength, Actual: len(text), Field: field}
+	}
+	return nil
+}
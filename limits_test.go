@@ -0,0 +1,214 @@
+package sqlbuilder
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// nestedGroups returns a chain of depth groups, each holding one condition
+func nestedGroups(depth int) LogicalGroup {
+	group := CreateSearchGroup(LogicAnd, CreateSearchCondition("a", OpEqual, 1))
+	for i := 1; i < depth; i++ {
+		group = LogicalGroup{Operator: LogicOr, Groups: []LogicalGroup{group}}
+	}
+	return group
+}
+
+// Test CheckLimits on QueryParams and AdvancedQueryParams
+func TestCheckLimits(t *testing.T) {
+	limits := QueryLimits{MaxDepth: 3, MaxConditions: 4, MaxInValues: 3, MaxValueLength: 8, MaxRegexLength: 5, MaxSortKeys: 2}
+
+	tests := []struct {
+		name     string
+		query    *QueryParams
+		advanced *AdvancedQueryParams
+		schema   *Schema
+		expected *LimitError
+		message  string
+	}{
+		{
+			name:  "within limits",
+			query: &QueryParams{Search: []SearchCriteria{CreateSearchCondition("id", OpIn, []int{1, 2, 3})}, Sort: []SortCriteria{{Field: "a"}, {Field: "b"}}},
+		},
+		{
+			name:     "depth",
+			advanced: &AdvancedQueryParams{SearchGroups: []LogicalGroup{nestedGroups(4)}},
+			expected: &LimitError{Limit: LimitDepth, Max: 3, Actual: 4},
+			message:  "sqlbuilder: query limit exceeded: depth is 4, max 3",
+		},
+		{
+			name:     "conditions",
+			query:    &QueryParams{Search: []SearchCriteria{CreateSearchCondition("a", OpEqual, 1), CreateMultiFieldSearchCondition(OpContains, "x", false, "b", "c", "d", "e")}},
+			expected: &LimitError{Limit: LimitConditions, Max: 4, Actual: 5},
+			message:  "sqlbuilder: query limit exceeded: conditions is 5, max 4",
+		},
+		{
+			name:     "fields take precedence over field",
+			query:    &QueryParams{Search: []SearchCriteria{{Field: "name", Fields: []string{"a", "b", "c", "d", "e"}, Operator: OpEqual, Value: 1}}},
+			expected: &LimitError{Limit: LimitConditions, Max: 4, Actual: 5},
+		},
+		{
+			name:     "tokenized words",
+			query:    &QueryParams{Search: []SearchCriteria{CreateMultiFieldSearchCondition(OpContains, "a b c", true, "x", "y")}},
+			expected: &LimitError{Limit: LimitConditions, Max: 4, Actual: 5},
+		},
+		{
+			name:     "field group members",
+			query:    &QueryParams{Filters: []FilterCriteria{CreateFilterCondition("contact", OpEqual, "x"), CreateFilterCondition("a", OpEqual, 1)}},
+			schema:   NewSchema().AddFieldGroup("contact", "name", "email", "phone", "address"),
+			expected: &LimitError{Limit: LimitConditions, Max: 4, Actual: 5},
+		},
+		{
+			name: "conditions across groups and filters",
+			advanced: &AdvancedQueryParams{
+				SearchGroups: []LogicalGroup{nestedGroups(3), nestedGroups(2)},
+				Filters:      []FilterCriteria{CreateFilterCondition("a", OpEqual, 1), CreateFilterCondition("b", OpEqual, 2), CreateFilterCondition("c", OpEqual, 3)},
+			},
+			expected: &LimitError{Limit: LimitConditions, Max: 4, Actual: 5},
+		},
+		{
+			name:     "in values",
+			query:    &QueryParams{Filters: []FilterCriteria{CreateFilterCondition("id", OpNotIn, []any{1, 2, 3, 4})}},
+			expected: &LimitError{Limit: LimitInValues, Max: 3, Actual: 4, Field: "id"},
+			message:  "sqlbuilder: query limit exceeded: in_values of id is 4, max 3",
+		},
+		{
+			name:     "value length",
+			query:    &QueryParams{Search: []SearchCriteria{CreateSearchCondition("name", OpContains, "too long value")}},
+			expected: &LimitError{Limit: LimitValueLength, Max: 8, Actual: 14, Field: "name"},
+		},
+		{
+			name:     "list item length",
+			query:    &QueryParams{Filters: []FilterCriteria{CreateFilterCondition("tag", OpIn, []string{"ok", "too long value"})}},
+			expected: &LimitError{Limit: LimitValueLength, Max: 8, Actual: 14, Field: "tag"},
+		},
+		{
+			name:     "regex length",
+			query:    &QueryParams{Filters: []FilterCriteria{CreateFilterCondition("code", OpRegex, "^a.*z$")}},
+			expected: &LimitError{Limit: LimitRegexLength, Max: 5, Actual: 6, Field: "code"},
+		},
+		{
+			name:     "sort keys",
+			advanced: &AdvancedQueryParams{Sort: []SortCriteria{{Field: "a"}, {Field: "b"}, {Field: "c"}}},
+			expected: &LimitError{Limit: LimitSortKeys, Max: 2, Actual: 3},
+			message:  "sqlbuilder: query limit exceeded: sort_keys is 3, max 2",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var err error
+			if tt.advanced != nil {
+				err = tt.advanced.CheckLimits(limits, tt.schema)
+			} else {
+				err = tt.query.CheckLimits(limits, tt.schema)
+			}
+
+			if tt.expected == nil {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorIs(t, err, ErrLimitExceeded)
+			var limitErr *LimitError
+			assert.True(t, errors.As(err, &limitErr))
+			assert.Equal(t, tt.expected, limitErr)
+			if tt.message != "" {
+				assert.EqualError(t, err, tt.message)
+			}
+		})
+	}
+
+	assert.NoError(t, (&AdvancedQueryParams{SearchGroups: []LogicalGroup{nestedGroups(100)}}).CheckLimits(QueryLimits{}, nil))
+}
+
+// Test the builder refuses to build criteria over its limits
+func TestSQLBuilder_QueryLimits(t *testing.T) {
+	builder := NewSQLBuilder()
+	builder.SetQueryLimits(&QueryLimits{MaxDepth: 2, MaxConditions: 3, MaxValueLength: 16})
+
+	assert.Equal(t, "a = ? AND b = ?", builder.BuildFilterConditions([]FilterCriteria{
+		CreateFilterCondition("a", OpEqual, 1),
+		CreateFilterCondition("b", OpEqual, 2),
+	}))
+	assert.NoError(t, builder.Err())
+
+	// A deep tree is rejected before compiling it
+	assert.Equal(t, "", builder.BuildAdvancedSearchConditions([]LogicalGroup{nestedGroups(10000)}))
+	assert.ErrorIs(t, builder.Err(), ErrLimitExceeded)
+	assert.EqualError(t, builder.Err(), "sqlbuilder: query limit exceeded: depth is 3, max 2")
+	assert.Equal(t, []any{1, 2}, builder.GetParams())
+
+	// Conditions count across build calls
+	builder = NewSQLBuilder()
+	builder.SetQueryLimits(&QueryLimits{MaxConditions: 3})
+	builder.BuildSearchConditions([]SearchCriteria{CreateSearchCondition("a", OpEqual, 1), CreateSearchCondition("b", OpEqual, 2)})
+	assert.NoError(t, builder.Err())
+	assert.Equal(t, "", builder.BuildFilterConditions([]FilterCriteria{CreateFilterCondition("c", OpEqual, 3), CreateFilterCondition("d", OpEqual, 4)}))
+	assert.EqualError(t, builder.Err(), "sqlbuilder: query limit exceeded: conditions is 4, max 3")
+	assert.Equal(t, []any{1, 2}, builder.GetParams())
+
+	builder = NewSQLBuilder()
+	builder.SetQueryLimits(&QueryLimits{MaxSortKeys: 1, MaxValueLength: 4})
+	assert.Equal(t, "", builder.BuildOrderBy([]SortCriteria{{Field: "a"}, {Field: "b"}}))
+	assert.ErrorIs(t, builder.Err(), ErrLimitExceeded)
+	assert.Equal(t, "", builder.BuildSearchConditions([]SearchCriteria{CreateSearchCondition("a", OpEqual, strings.Repeat("x", 5))}))
+
+	limits := DefaultQueryLimits()
+	assert.Equal(t, 5, limits.MaxDepth)
+
+	// Every rendered field and word counts
+	fields := make([]string, 200)
+	for i := range fields {
+		fields[i] = "c" + strings.Repeat("x", i%10)
+	}
+	builder = NewSQLBuilder()
+	builder.SetQueryLimits(&limits)
+	assert.Equal(t, "", builder.BuildSearchConditions([]SearchCriteria{{Field: "name", Fields: fields, Operator: OpEqual, Value: 1}}))
+	assert.EqualError(t, builder.Err(), "sqlbuilder: query limit exceeded: conditions is 51, max 50")
+
+	builder = NewSQLBuilder()
+	builder.SetQueryLimits(&limits)
+	assert.Equal(t, "", builder.BuildSearchConditions([]SearchCriteria{CreateMultiFieldSearchCondition(OpContains, strings.Repeat("w ", 52), true, "name")}))
+	assert.EqualError(t, builder.Err(), "sqlbuilder: query limit exceeded: conditions is 51, max 50")
+}
+
+// Test applying query params checks the limits before the permissions
+func TestApply_LimitsBeforePermissions(t *testing.T) {
+	forbidden := CreateSearchGroup(LogicAnd, CreateSearchCondition("salary", OpGreaterThan, 1))
+	deep := nestedGroups(10)
+	deep.Groups = append(deep.Groups, forbidden)
+
+	builder := NewSQLBuilder()
+	builder.SetSchema(permissionTestSchema())
+	builder.SetQueryLimits(&QueryLimits{MaxDepth: 2})
+	params := &AdvancedQueryParams{SearchGroups: []LogicalGroup{forbidden, deep}}
+	params.ApplyAdvancedSearch(builder)
+	assert.ErrorIs(t, builder.Err(), ErrLimitExceeded)
+	assert.NotErrorIs(t, builder.Err(), ErrForbidden)
+	assert.Equal(t, "", builder.GetWhereClause())
+
+	builder = NewSQLBuilder()
+	builder.SetSchema(permissionTestSchema())
+	builder.SetQueryLimits(&QueryLimits{MaxConditions: 1})
+	query := &QueryParams{Search: []SearchCriteria{
+		CreateSearchCondition("salary", OpGreaterThan, 1),
+		CreateSearchCondition("name", OpEqual, "a"),
+	}}
+	query.ApplySearch(builder)
+	assert.EqualError(t, builder.Err(), "sqlbuilder: query limit exceeded: conditions is 2, max 1")
+
+	// Conditions within the limits are counted once
+	builder = NewSQLBuilder()
+	builder.SetSchema(permissionTestSchema())
+	builder.SetQueryLimits(&QueryLimits{MaxConditions: 2})
+	query = &QueryParams{Filters: []FilterCriteria{
+		CreateFilterCondition("name", OpEqual, "a"),
+		CreateFilterCondition("age", OpEqual, 1),
+	}}
+	query.ApplyFilters(builder)
+	assert.NoError(t, builder.Err())
+	assert.Equal(t, "WHERE name = ? AND age = ?", builder.GetWhereClause())
+}
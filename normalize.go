@@ -0,0 +1,250 @@
+package sqlbuilder
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// NormalizeGroup returns the canonical form of a group
+// Operators are upper-cased (empty means AND), empty groups are removed, nested groups with
+// the same operator and single-member groups are flattened into their parent, and conditions
+// and groups are sorted and deduplicated. in/not_in lists and multi-field lists are sorted too.
+// The canonical form matches the same rows as the original group.
+func NormalizeGroup(group LogicalGroup) LogicalGroup {
+	operator := strings.ToUpper(group.Operator)
+	if operator == "" {
+		operator = LogicAnd
+	}
+
+	conditions := make([]SearchCriteria, 0, len(group.Conditions))
+	for _, criterion := range group.Conditions {
+		conditions = append(conditions, normalizeSearchCriterion(criterion))
+	}
+	groups := make([]LogicalGroup, 0, len(group.Groups))
+	for _, nestedGroup := range group.Groups {
+		nestedGroup = NormalizeGroup(nestedGroup)
+		switch {
+		case len(nestedGroup.Conditions) == 0 && len(nestedGroup.Groups) == 0:
+		case !nestedGroup.Not && (nestedGroup.Operator == operator || len(nestedGroup.Conditions)+len(nestedGroup.Groups) == 1):
+			conditions = append(conditions, nestedGroup.Conditions...)
+			groups = append(groups, nestedGroup.Groups...)
+		default:
+			groups = append(groups, nestedGroup)
+		}
+	}
+
+	normalized := LogicalGroup{
+		Operator:   operator,
+		Conditions: sortUnique(conditions),
+		Groups:     sortUnique(groups),
+		Not:        group.Not,
+	}
+	// A group holding nothing but one group is that group
+	if len(normalized.Conditions) == 0 && len(normalized.Groups) == 1 {
+		inner := normalized.Groups[0]
+		inner.Not = inner.Not != normalized.Not
+		return inner
+	}
+	return normalized
+}
+
+// Normalize returns a canonical copy of q
+// Search criteria and filters are sorted and deduplicated, sort criteria keep their order.
+func (q *QueryParams) Normalize() *QueryParams {
+	normalized := &QueryParams{
+		Search:     make([]SearchCriteria, 0, len(q.Search)),
+		SearchMode: q.SearchMode,
+		Filters:    normalizeFilters(q.Filters),
+		Sort:       append([]SortCriteria{}, q.Sort...),
+		Pagination: NewPaginationParams(q.Pagination.Page, q.Pagination.Limit),
+	}
+	for _, criterion := range q.Search {
+		normalized.Search = append(normalized.Search, normalizeSearchCriterion(criterion))
+	}
+	normalized.Search = sortUnique(normalized.Search)
+	return normalized
+}
+
+// Normalize returns a canonical copy of q
+// Every search group is normalized with NormalizeGroup, then empty groups are removed and
+// the groups and filters are sorted and deduplicated. Sort criteria keep their order.
+func (q *AdvancedQueryParams) Normalize() *AdvancedQueryParams {
+	normalized := &AdvancedQueryParams{
+		SearchGroups: make([]LogicalGroup, 0, len(q.SearchGroups)),
+		Filters:      normalizeFilters(q.Filters),
+		Sort:         append([]SortCriteria{}, q.Sort...),
+		Pagination:   NewPaginationParams(q.Pagination.Page, q.Pagination.Limit),
+	}
+	for _, group := range q.SearchGroups {
+		group = NormalizeGroup(group)
+		if len(group.Conditions) > 0 || len(group.Groups) > 0 {
+			normalized.SearchGroups = append(normalized.SearchGroups, group)
+		}
+	}
+	normalized.SearchGroups = sortUnique(normalized.SearchGroups)
+	return normalized
+}
+
+// Fingerprint returns a stable hash of the normalized query, including values and pagination
+// Queries that only differ in condition order or redundant nesting share a fingerprint.
+func (q *QueryParams) Fingerprint() string {
+	return fingerprint("query", q.Normalize())
+}
+
+// ShapeFingerprint returns a stable hash of the normalized query without its values
+// Values are replaced by placeholders (lists keep their length and tokenized values their
+// word count, as both change the SQL) and pagination is left out, so queries producing the
+// same statement share a shape. Field and Fields are both kept.
+func (q *QueryParams) ShapeFingerprint() string {
+	shape := q.Normalize()
+	for i := range shape.Search {
+		shapeSearch(&shape.Search[i])
+	}
+	shapeFilters(shape.Filters)
+	shapeSort(shape.Sort)
+	shape.Pagination = PaginationParams{}
+	return fingerprint("query-shape", shape)
+}
+
+// Fingerprint returns a stable hash of the normalized query, including values and pagination
+func (q *AdvancedQueryParams) Fingerprint() string {
+	return fingerprint("advanced", q.Normalize())
+}
+
+// ShapeFingerprint returns a stable hash of the normalized query without its values
+func (q *AdvancedQueryParams) ShapeFingerprint() string {
+	shape := q.Normalize()
+	for i := range shape.SearchGroups {
+		shapeGroup(&shape.SearchGroups[i])
+	}
+	shapeFilters(shape.Filters)
+	shapeSort(shape.Sort)
+	shape.Pagination = PaginationParams{}
+	return fingerprint("advanced-shape", shape)
+}
+
+// normalizeSearchCriterion sorts the fields and in/not_in values of a criterion
+func normalizeSearchCriterion(criterion SearchCriteria) SearchCriteria {
+	if len(criterion.Fields) > 0 {
+		criterion.Fields = sortUnique(criterion.Fields)
+	}
+	criterion.Value = normalizeList(criterion.Operator, criterion.Value)
+	return criterion
+}
+
+// normalizeFilters sorts and deduplicates filters
+func normalizeFilters(filters []FilterCriteria) []FilterCriteria {
+	normalized := make([]FilterCriteria, 0, len(filters))
+	for _, filter := range filters {
+		filter.Value = normalizeList(filter.Operator, filter.Value)
+		normalized = append(normalized, filter)
+	}
+	return sortUnique(normalized)
+}
+
+// normalizeList sorts and deduplicates the list of an in or not_in operator
+// Typed slices such as []int are converted to []any, as the builder renders both alike.
+func normalizeList(operator string, value any) any {
+	if operator != OpIn && operator != OpNotIn {
+		return value
+	}
+	values, ok := listValues(value)
+	if !ok {
+		return value
+	}
+	return sortUnique(values)
+}
+
+// sortUnique returns a copy of items sorted by their canonical key, without duplicates
+func sortUnique[T any](items []T) []T {
+	keys := make(map[string]T, len(items))
+	for _, item := range items {
+		keys[canonicalKey(item)] = item
+	}
+
+	sorted := make([]string, 0, len(keys))
+	for key := range keys {
+		sorted = append(sorted, key)
+	}
+	sort.Strings(sorted)
+
+	unique := make([]T, 0, len(keys))
+	for _, key := range sorted {
+		unique = append(unique, keys[key])
+	}
+	return unique
+}
+
+// canonicalKey returns the JSON encoding of v, used to compare and order query parts
+// Map keys are sorted by encoding/json, so equal values always have equal keys.
+func canonicalKey(v any) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprintf("%#v", v)
+	}
+	return string(data)
+}
+
+// fingerprint hashes the canonical key of v, prefixed with kind
+func fingerprint(kind string, v any) string {
+	sum := sha256.Sum256([]byte(kind + ":" + canonicalKey(v)))
+	return hex.EncodeToString(sum[:])
+}
+
+// shapeValue replaces a value by a placeholder of the same kind
+// Numbers have their own placeholder, as JSON paths compare them as numbers and other values as text.
+func shapeValue(value any) any {
+	if value == nil {
+		return nil
+	}
+	if values, ok := listValues(value); ok {
+		if numericValues(values) {
+			return fmt.Sprintf("[%d numbers]", len(values))
+		}
+		return fmt.Sprintf("[%d]", len(values))
+	}
+	if _, ok := numericValue(value); ok {
+		return "?number"
+	}
+	return "?"
+}
+
+// shapeSearch replaces the value of a search criterion by a placeholder
+// A tokenized value renders one condition per word, so its placeholder keeps the word count.
+func shapeSearch(criterion *SearchCriteria) {
+	if criterion.Tokenize {
+		criterion.Value = fmt.Sprintf("?x%d", len(criterion.renderedValues()))
+		return
+	}
+	criterion.Value = shapeValue(criterion.Value)
+}
+
+// shapeGroup replaces the values of a group and its nested groups
+func shapeGroup(group *LogicalGroup) {
+	for i := range group.Conditions {
+		shapeSearch(&group.Conditions[i])
+	}
+	for i := range group.Groups {
+		shapeGroup(&group.Groups[i])
+	}
+}
+
+// shapeFilters replaces the values of filters
+func shapeFilters(filters []FilterCriteria) {
+	for i := range filters {
+		filters[i].Value = shapeValue(filters[i].Value)
+	}
+}
+
+// shapeSort replaces the points of distance sorts
+func shapeSort(sortCriteria []SortCriteria) {
+	for i := range sortCriteria {
+		if sortCriteria[i].Near != nil {
+			sortCriteria[i].Near = &GeoPoint{}
+		}
+	}
+}
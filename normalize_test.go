@@ -0,0 +1,242 @@
+package sqlbuilder
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Test NormalizeGroup flattening, sorting and deduplication
+func TestNormalizeGroup(t *testing.T) {
+	a := CreateSearchCondition("a", OpEqual, 1)
+	b := CreateSearchCondition("b", OpEqual, 2)
+	c := CreateSearchCondition("c", OpEqual, 3)
+
+	tests := []struct {
+		name     string
+		input    LogicalGroup
+		expected LogicalGroup
+	}{
+		{
+			name:     "sort and dedupe",
+			input:    CreateSearchGroup("or", c, a, c, b),
+			expected: CreateSearchGroup(LogicOr, a, b, c),
+		},
+		{
+			name: "flatten same operator",
+			input: LogicalGroup{
+				Operator:   LogicAnd,
+				Conditions: []SearchCriteria{c},
+				Groups:     []LogicalGroup{CreateSearchGroup("", b, a)},
+			},
+			expected: CreateSearchGroup(LogicAnd, a, b, c),
+		},
+		{
+			name: "keep other operator",
+			input: LogicalGroup{
+				Operator:   LogicAnd,
+				Conditions: []SearchCriteria{c},
+				Groups:     []LogicalGroup{CreateSearchGroup(LogicOr, b, a)},
+			},
+			expected: LogicalGroup{
+				Operator:   LogicAnd,
+				Conditions: []SearchCriteria{c},
+				Groups:     []LogicalGroup{CreateSearchGroup(LogicOr, a, b)},
+			},
+		},
+		{
+			name: "remove empty and single-member groups",
+			input: LogicalGroup{
+				Operator:   LogicOr,
+				Conditions: []SearchCriteria{b},
+				Groups:     []LogicalGroup{CreateSearchGroup(LogicAnd), CreateSearchGroup(LogicAnd, a)},
+			},
+			expected: CreateSearchGroup(LogicOr, a, b),
+		},
+		{
+			name: "negated group is not flattened",
+			input: LogicalGroup{
+				Operator:   LogicAnd,
+				Conditions: []SearchCriteria{c},
+				Groups:     []LogicalGroup{{Operator: LogicAnd, Conditions: []SearchCriteria{a, b}, Not: true}},
+			},
+			expected: LogicalGroup{
+				Operator:   LogicAnd,
+				Conditions: []SearchCriteria{c},
+				Groups:     []LogicalGroup{{Operator: LogicAnd, Conditions: []SearchCriteria{a, b}, Groups: []LogicalGroup{}, Not: true}},
+			},
+		},
+		{
+			name: "wrapper group is unwrapped",
+			input: LogicalGroup{
+				Operator: LogicAnd,
+				Groups:   []LogicalGroup{CreateSearchGroup(LogicOr, b, a)},
+				Not:      true,
+			},
+			expected: LogicalGroup{Operator: LogicOr, Conditions: []SearchCriteria{a, b}, Groups: []LogicalGroup{}, Not: true},
+		},
+		{
+			name:  "in lists and fields",
+			input: CreateSearchGroup(LogicAnd, CreateSearchCondition("id", OpIn, []any{3, 1, 3, 2}), CreateMultiFieldSearchCondition(OpContains, "x", false, "name", "email", "name")),
+			expected: CreateSearchGroup(LogicAnd,
+				CreateMultiFieldSearchCondition(OpContains, "x", false, "email", "name"),
+				CreateSearchCondition("id", OpIn, []any{1, 2, 3}),
+			),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			normalized := NormalizeGroup(tt.input)
+			assert.Equal(t, canonicalKey(tt.expected), canonicalKey(normalized))
+			assert.Equal(t, canonicalKey(normalized), canonicalKey(NormalizeGroup(normalized)))
+
+			// The canonical form matches the same rows
+			items := []map[string]any{
+				{"a": 1, "b": 2, "c": 3, "id": 1, "name": "x", "email": ""},
+				{"a": 1, "b": 0, "c": 3, "id": 4, "name": "", "email": "x"},
+				{"a": 0, "b": 2, "c": 0, "id": 2, "name": "", "email": ""},
+				{"a": 0, "b": 0, "c": 0, "id": 9, "name": "", "email": ""},
+			}
+			before, _, err := Evaluate(items, &AdvancedQueryParams{SearchGroups: []LogicalGroup{tt.input}})
+			assert.NoError(t, err)
+			after, _, err := Evaluate(items, &AdvancedQueryParams{SearchGroups: []LogicalGroup{normalized}})
+			assert.NoError(t, err)
+			assert.Equal(t, before, after)
+		})
+	}
+}
+
+// Test fingerprints ignore order and nesting but not values
+func TestQueryParams_Fingerprint(t *testing.T) {
+	first := NewQueryParams()
+	first.AddFilter("status", OpEqual, "active")
+	first.AddFilter("age", OpIn, []any{21, 18})
+	first.AddSort("name", SortAsc)
+
+	second := NewQueryParams()
+	second.AddFilter("age", OpIn, []any{18, 21, 18})
+	second.AddFilter("status", OpEqual, "active")
+	second.AddFilter("status", OpEqual, "active")
+	second.AddSort("name", SortAsc)
+
+	assert.Len(t, first.Fingerprint(), 64)
+	assert.Equal(t, first.Fingerprint(), second.Fingerprint())
+	assert.Equal(t, first.ShapeFingerprint(), second.ShapeFingerprint())
+
+	other := NewQueryParams()
+	other.AddFilter("status", OpEqual, "archived")
+	other.AddFilter("age", OpIn, []any{30, 40})
+	other.AddSort("name", SortAsc)
+	other.SetPagination(4, 10)
+	assert.NotEqual(t, first.Fingerprint(), other.Fingerprint())
+	assert.Equal(t, first.ShapeFingerprint(), other.ShapeFingerprint())
+
+	other.AddSort("age", SortDesc)
+	assert.NotEqual(t, first.ShapeFingerprint(), other.ShapeFingerprint())
+
+	longer := NewQueryParams()
+	longer.AddFilter("status", OpEqual, "active")
+	longer.AddFilter("age", OpIn, []any{18, 21, 30})
+	longer.AddSort("name", SortAsc)
+	assert.NotEqual(t, first.ShapeFingerprint(), longer.ShapeFingerprint())
+
+	// Normalizing does not modify the original
+	assert.Equal(t, []any{18, 21, 18}, second.Filters[0].Value)
+
+	typed := NewQueryParams()
+	typed.AddFilter("status", OpEqual, "active")
+	typed.AddFilter("age", OpIn, []int{21, 18, 21})
+	typed.AddSort("name", SortAsc)
+	assert.Equal(t, first.Fingerprint(), typed.Fingerprint())
+	assert.Equal(t, []any{18, 21}, typed.Normalize().Filters[0].Value)
+
+	// Typed lists render like []any lists
+	for _, query := range []*QueryParams{first, typed} {
+		builder := NewSQLBuilder()
+		query.Normalize().ApplyFilters(builder)
+		assert.Equal(t, "WHERE age IN (?, ?) AND status = ?", builder.GetWhereClause())
+		assert.Equal(t, []any{18, 21, "active"}, builder.GetParams())
+	}
+}
+
+// Test shapes tell numbers from other values, as JSON paths cast only numbers
+func TestQueryParams_ShapeFingerprintKinds(t *testing.T) {
+	schema := NewSchema().AllowJSONPaths("metadata", "size")
+	query := func(value any) *QueryParams {
+		q := NewQueryParams()
+		q.AddFilter("metadata.size", OpEqual, value)
+		return q
+	}
+	render := func(q *QueryParams) string {
+		builder := NewSQLBuilder()
+		builder.SetSchema(schema)
+		q.ApplyFilters(builder)
+		return builder.GetWhereClause()
+	}
+
+	assert.NotEqual(t, render(query(5)), render(query("5")))
+	assert.NotEqual(t, query(5).ShapeFingerprint(), query("5").ShapeFingerprint())
+	assert.Equal(t, query(5).ShapeFingerprint(), query(7.5).ShapeFingerprint())
+	assert.Equal(t, query("5").ShapeFingerprint(), query("x").ShapeFingerprint())
+
+	list := func(value any) *QueryParams {
+		q := NewQueryParams()
+		q.AddFilter("metadata.size", OpIn, value)
+		return q
+	}
+	assert.NotEqual(t, list([]int{1, 2}).ShapeFingerprint(), list([]string{"1", "2"}).ShapeFingerprint())
+	assert.Equal(t, list([]int{1, 2}).ShapeFingerprint(), list([]any{3.5, 4}).ShapeFingerprint())
+}
+
+// Test shapes keep what changes the SQL of multi-field and tokenized criteria
+func TestQueryParams_ShapeFingerprintSearch(t *testing.T) {
+	shape := func(criteria ...SearchCriteria) string {
+		query := NewQueryParams()
+		query.Search = criteria
+		return query.ShapeFingerprint()
+	}
+	words := func(value string) SearchCriteria {
+		return SearchCriteria{Field: "title", Operator: OpIContains, Value: value, Tokenize: true}
+	}
+	fields := func(field string) SearchCriteria {
+		return SearchCriteria{Field: field, Fields: []string{"title", "body"}, Operator: OpIContains, Value: "go"}
+	}
+
+	assert.Equal(t, shape(words("go sql")), shape(words("  rust   db ")))
+	assert.NotEqual(t, shape(words("go sql")), shape(words("go sql db")))
+	assert.NotEqual(t, shape(words("go")), shape(SearchCriteria{Field: "title", Operator: OpIContains, Value: "go"}))
+	assert.Equal(t, shape(fields("title")), shape(fields("title")))
+	assert.NotEqual(t, shape(fields("title")), shape(fields("name")))
+}
+
+// Test advanced fingerprints
+func TestAdvancedQueryParams_Fingerprint(t *testing.T) {
+	first := NewAdvancedQueryParams()
+	first.SearchGroups = []LogicalGroup{
+		CreateSearchGroup(LogicOr, CreateSearchCondition("a", OpEqual, 1), CreateSearchCondition("b", OpEqual, 2)),
+		CreateSearchGroup(LogicAnd),
+	}
+
+	second := NewAdvancedQueryParams()
+	second.SearchGroups = []LogicalGroup{{
+		Operator: "and",
+		Groups: []LogicalGroup{{
+			Operator:   "or",
+			Conditions: []SearchCriteria{CreateSearchCondition("b", OpEqual, 2)},
+			Groups:     []LogicalGroup{CreateSearchGroup(LogicOr, CreateSearchCondition("a", OpEqual, 1))},
+		}},
+	}}
+
+	assert.Equal(t, first.Normalize(), second.Normalize())
+	assert.Equal(t, first.Fingerprint(), second.Fingerprint())
+
+	third := NewAdvancedQueryParams()
+	third.SearchGroups = []LogicalGroup{CreateSearchGroup(LogicOr, CreateSearchCondition("a", OpEqual, 5), CreateSearchCondition("b", OpEqual, 6))}
+	assert.NotEqual(t, first.Fingerprint(), third.Fingerprint())
+	assert.Equal(t, first.ShapeFingerprint(), third.ShapeFingerprint())
+
+	query := NewQueryParams()
+	query.AddSearch("a", OpEqual, 1)
+	assert.NotEqual(t, query.Fingerprint(), first.Fingerprint())
+}
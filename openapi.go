@@ -0,0 +1,354 @@
+package sqlbuilder
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// jsonSchemaDraft is the JSON Schema version of generated documents
+const jsonSchemaDraft = "https://json-schema.org/draft/2020-12/schema"
+
+// QueryParamsJSONSchema returns a JSON Schema document for QueryParams restricted to the schema fields
+func (sc *Schema) QueryParamsJSONSchema() map[string]any {
+	return sc.jsonSchemaDocument("QueryParams")
+}
+
+// AdvancedQueryParamsJSONSchema returns a JSON Schema document for AdvancedQueryParams restricted to the schema fields
+func (sc *Schema) AdvancedQueryParamsJSONSchema() map[string]any {
+	return sc.jsonSchemaDocument("AdvancedQueryParams")
+}
+
+// OpenAPIComponents returns the components section referenced by OpenAPIParameters
+// It holds the schemas of QueryParams, AdvancedQueryParams and the types they are made of.
+func (sc *Schema) OpenAPIComponents() map[string]any {
+	return map[string]any{"schemas": sc.jsonSchemaDefs("#/components/schemas/")}
+}
+
+// OpenAPIParameters returns the OpenAPI 3.1 query parameters of an endpoint taking QueryParams
+// search, filters and sort are JSON encoded arrays, search_mode is any or all, page and limit are integers bounded by the
+// schema pagination limits. The parameters reference the schemas of OpenAPIComponents.
+func (sc *Schema) OpenAPIParameters() []map[string]any {
+	jsonArray := func(name, item, description string) map[string]any {
+		return map[string]any{
+			"name":        name,
+			"in":          "query",
+			"description": description,
+			"content": map[string]any{
+				"application/json": map[string]any{
+					"schema": map[string]any{"type": "array", "items": map[string]any{"$ref": "#/components/schemas/" + item}},
+				},
+			},
+		}
+	}
+	pagination := sc.paginationSchema()["properties"].(map[string]any)
+	searchMode := map[string]any{"enum": []string{SearchModeAny, SearchModeAll}, "default": SearchModeAny}
+
+	return []map[string]any{
+		jsonArray("search", "SearchCriteria", "Search criteria, combined according to search_mode"),
+		{"name": "search_mode", "in": "query", "description": "Match any or all search criteria", "schema": searchMode},
+		jsonArray("filters", "FilterCriteria", "Filters, all of them must match"),
+		jsonArray("sort", "SortCriteria", "Sort order"),
+		{"name": "page", "in": "query", "description": "Page number, starting at 1", "schema": pagination["page"]},
+		{"name": "limit", "in": "query", "description": "Page size", "schema": pagination["limit"]},
+	}
+}
+
+// jsonSchemaDocument returns a standalone document whose root is the named definition
+func (sc *Schema) jsonSchemaDocument(root string) map[string]any {
+	return map[string]any{
+		"$schema": jsonSchemaDraft,
+		"$ref":    "#/$defs/" + root,
+		"$defs":   sc.jsonSchemaDefs("#/$defs/"),
+	}
+}
+
+// jsonSchemaDefs returns the definitions of the query contract, references start with refPrefix
+func (sc *Schema) jsonSchemaDefs(refPrefix string) map[string]any {
+	ref := func(name string) map[string]any {
+		return map[string]any{"$ref": refPrefix + name}
+	}
+	arrayOf := func(name string) map[string]any {
+		return map[string]any{"type": "array", "items": ref(name)}
+	}
+	object := func(properties map[string]any, required ...string) map[string]any {
+		schema := map[string]any{"type": "object", "properties": properties, "additionalProperties": false}
+		if len(required) > 0 {
+			schema["required"] = required
+		}
+		return schema
+	}
+	number := map[string]any{"type": "number"}
+
+	filterVariants := sc.criterionVariants(ref)
+	searchVariants := append(append(sc.criterionVariants(ref), sc.groupVariants(ref)...), sc.multiFieldVariants()...)
+
+	return map[string]any{
+		"GeoPoint":       object(map[string]any{"lat": number, "lng": number}, "lat", "lng"),
+		"GeoRadius":      object(map[string]any{"lat": number, "lng": number, "radius_km": map[string]any{"type": "number", "exclusiveMinimum": 0}}, "lat", "lng", "radius_km"),
+		"GeoBBox":        object(map[string]any{"min_lat": number, "min_lng": number, "max_lat": number, "max_lng": number}, "min_lat", "min_lng", "max_lat", "max_lng"),
+		"ArrayLength":    object(map[string]any{"operator": map[string]any{"enum": []string{OpEqual, OpNotEqual, OpGreaterThan, OpGreaterThanEq, OpLessThan, OpLessThanEq}}, "length": map[string]any{"type": "integer", "minimum": 0}}, "operator", "length"),
+		"SearchCriteria": map[string]any{"oneOf": searchVariants},
+		"FilterCriteria": map[string]any{"oneOf": filterVariants},
+		"SortCriteria": object(map[string]any{
+			"field": map[string]any{"enum": sc.sortableFields()},
+			"order": map[string]any{"enum": []string{SortAsc, SortDesc}, "default": SortAsc},
+			"near":  ref("GeoPoint"),
+		}, "field"),
+		"PaginationParams": sc.paginationSchema(),
+		"LogicalGroup": object(map[string]any{
+			"operator":   map[string]any{"enum": []string{LogicAnd, LogicOr, "and", "or"}, "default": LogicAnd},
+			"conditions": arrayOf("SearchCriteria"),
+			"groups":     arrayOf("LogicalGroup"),
+			"not":        map[string]any{"type": "boolean"},
+		}),
+		"QueryParams": object(map[string]any{
+			"search":      arrayOf("SearchCriteria"),
+			"search_mode": map[string]any{"enum": []string{SearchModeAny, SearchModeAll}, "default": SearchModeAny},
+			"filters":     arrayOf("FilterCriteria"),
+			"sort":        arrayOf("SortCriteria"),
+			"pagination":  ref("PaginationParams"),
+		}),
+		"AdvancedQueryParams": object(map[string]any{
+			"search_groups": arrayOf("LogicalGroup"),
+			"filters":       arrayOf("FilterCriteria"),
+			"sort":          arrayOf("SortCriteria"),
+			"pagination":    ref("PaginationParams"),
+		}),
+	}
+}
+
+// criterionVariants returns one variant per field and group of operators sharing a value schema
+func (sc *Schema) criterionVariants(ref func(string) map[string]any) []any {
+	variants := make([]any, 0)
+	for _, name := range sc.FieldNames() {
+		spec := sc.fields[name]
+		operators := spec.AllowedOperators()
+		values := make([]map[string]any, len(operators))
+		for i, operator := range operators {
+			values[i] = operatorValueSchema(spec.Type, operator, ref)
+		}
+		variants = append(variants, fieldVariants(name, spec.Description, operators, values)...)
+	}
+	return variants
+}
+
+// groupVariants returns the variants of the field groups that are not registered as fields
+// A group offers the operators every registered member allows with the same value schema.
+func (sc *Schema) groupVariants(ref func(string) map[string]any) []any {
+	names := make([]string, 0, len(sc.fieldGroups))
+	for name := range sc.fieldGroups {
+		if _, ok := sc.fields[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	variants := make([]any, 0)
+	for _, name := range names {
+		var specs []FieldSpec
+		for _, member := range sc.fieldGroups[name] {
+			if spec, ok := sc.fields[member]; ok {
+				specs = append(specs, spec)
+			}
+		}
+		if len(specs) == 0 {
+			continue
+		}
+
+		var operators []string
+		var values []map[string]any
+		for _, operator := range specs[0].AllowedOperators() {
+			value := operatorValueSchema(specs[0].Type, operator, ref)
+			shared := true
+			for _, spec := range specs[1:] {
+				shared = shared && spec.Allows(operator) && reflect.DeepEqual(value, operatorValueSchema(spec.Type, operator, ref))
+			}
+			if shared {
+				operators = append(operators, operator)
+				values = append(values, value)
+			}
+		}
+		description := "Matches any of " + strings.Join(sc.fieldGroups[name], ", ")
+		variants = append(variants, fieldVariants(name, description, operators, values)...)
+	}
+	return variants
+}
+
+// fieldVariants returns one variant of field per group of operators sharing a value schema
+// values holds the value schema of each operator, nil for operators taking no value.
+func fieldVariants(field, description string, operators []string, values []map[string]any) []any {
+	var grouped [][]string
+	var schemas []map[string]any
+	for i, operator := range operators {
+		found := false
+		for j := range schemas {
+			if reflect.DeepEqual(schemas[j], values[i]) {
+				grouped[j] = append(grouped[j], operator)
+				found = true
+				break
+			}
+		}
+		if !found {
+			grouped = append(grouped, []string{operator})
+			schemas = append(schemas, values[i])
+		}
+	}
+
+	variants := make([]any, 0, len(schemas))
+	for i, value := range schemas {
+		properties := map[string]any{
+			"field":    map[string]any{"const": field},
+			"operator": map[string]any{"enum": grouped[i]},
+		}
+		required := []string{"field", "operator"}
+		if value != nil {
+			properties["value"] = value
+			required = append(required, "value")
+		}
+		variant := map[string]any{"type": "object", "properties": properties, "required": required}
+		if description != "" {
+			variant["description"] = description
+		}
+		variants = append(variants, variant)
+	}
+	return variants
+}
+
+// multiFieldOperators are the string operators documented for multi-field search
+var multiFieldOperators = []string{OpEqual, OpContains, OpIContains, OpStartsWith, OpIStartsWith, OpEndsWith, OpIEndsWith, OpLike, OpILike}
+
+// multiFieldVariants returns the variants searching several string fields at once
+// Each variant lists the fields that allow all of its operators, as every field of a
+// multi-field criterion is checked against the operator.
+func (sc *Schema) multiFieldVariants() []any {
+	var fieldSets [][]string
+	var operators [][]string
+	for _, operator := range multiFieldOperators {
+		var fields []string
+		for _, name := range sc.FieldNames() {
+			if spec := sc.fields[name]; spec.Type == FieldString && spec.Allows(operator) {
+				fields = append(fields, name)
+			}
+		}
+		if len(fields) == 0 {
+			continue
+		}
+		found := false
+		for i := range fieldSets {
+			if reflect.DeepEqual(fieldSets[i], fields) {
+				operators[i] = append(operators[i], operator)
+				found = true
+				break
+			}
+		}
+		if !found {
+			fieldSets = append(fieldSets, fields)
+			operators = append(operators, []string{operator})
+		}
+	}
+
+	variants := make([]any, 0, len(fieldSets))
+	for i, fields := range fieldSets {
+		variants = append(variants, map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"field":    map[string]any{"const": ""},
+				"fields":   map[string]any{"type": "array", "items": map[string]any{"enum": fields}, "minItems": 1},
+				"operator": map[string]any{"enum": operators[i]},
+				"value":    map[string]any{"type": "string"},
+				"tokenize": map[string]any{"type": "boolean"},
+			},
+			"required": []string{"fields", "operator", "value"},
+		})
+	}
+	return variants
+}
+
+// sortableFields returns the sortable fields, plus relevance if a field allows full-text search
+func (sc *Schema) sortableFields() []string {
+	fields := make([]string, 0)
+	relevance := false
+	for _, name := range sc.FieldNames() {
+		spec := sc.fields[name]
+		if spec.Sortable {
+			fields = append(fields, name)
+		}
+		relevance = relevance || spec.Allows(OpFullText) || spec.Allows(OpFullTextBoolean) || spec.Allows(OpFullTextExpansion)
+	}
+	if relevance {
+		fields = append(fields, SortRelevance)
+	}
+	return fields
+}
+
+// paginationSchema returns the PaginationParams schema bounded by the pagination limits
+func (sc *Schema) paginationSchema() map[string]any {
+	defaultLimit, maxLimit := sc.PaginationLimits()
+	limit := map[string]any{"type": "integer", "minimum": 1, "default": defaultLimit}
+	if maxLimit > 0 {
+		limit["maximum"] = maxLimit
+	}
+
+	return map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"page":   map[string]any{"type": "integer", "minimum": 1, "default": 1},
+			"limit":  limit,
+			"offset": map[string]any{"type": "integer", "minimum": 0},
+		},
+		"additionalProperties": false,
+	}
+}
+
+// fieldTypeSchema returns the JSON Schema of a single value of the field type
+func fieldTypeSchema(fieldType FieldType, ref func(string) map[string]any) map[string]any {
+	switch fieldType {
+	case FieldString, FieldInteger, FieldNumber, FieldBoolean:
+		return map[string]any{"type": string(fieldType)}
+	case FieldDateTime:
+		return map[string]any{"type": "string", "format": "date-time"}
+	case FieldArray:
+		return map[string]any{"type": "array"}
+	case FieldGeo:
+		return ref("GeoPoint")
+	}
+	return map[string]any{}
+}
+
+// operatorValueSchema returns the JSON Schema of the value of operator, nil if it takes no value
+func operatorValueSchema(fieldType FieldType, operator string, ref func(string) map[string]any) map[string]any {
+	text := map[string]any{"type": "string"}
+
+	switch operator {
+	case OpIsNull, OpIsNotNull:
+		return nil
+	case OpIn, OpNotIn:
+		return map[string]any{"type": "array", "items": fieldTypeSchema(fieldType, ref), "minItems": 1}
+	case OpContains, OpIContains, OpStartsWith, OpIStartsWith, OpEndsWith, OpIEndsWith, OpLike, OpILike,
+		OpNotContains, OpNotIContains, OpNotStartsWith, OpNotIStartsWith, OpNotEndsWith, OpNotIEndsWith,
+		OpNotLike, OpNotILike, OpRegex, OpIRegex, OpNotRegex, OpNotIRegex,
+		OpFullText, OpFullTextBoolean, OpFullTextExpansion, OpJSONHasKey:
+		return text
+	case OpWithinLast:
+		return map[string]any{"type": "string", "pattern": "^[1-9][0-9]*[mhdw]$"}
+	case OpOnDate:
+		return map[string]any{"type": "string", "format": "date"}
+	case OpInPeriod, OpBeforePeriod, OpAfterPeriod:
+		return map[string]any{"enum": []string{
+			PeriodToday, PeriodYesterday, PeriodThisWeek, PeriodLastWeek, PeriodThisMonth,
+			PeriodLastMonth, PeriodThisQuarter, PeriodLastQuarter, PeriodThisYear, PeriodLastYear,
+		}}
+	case OpArrayContains, OpJSONContains:
+		return map[string]any{}
+	case OpArrayContainedBy, OpArrayOverlaps:
+		return map[string]any{"type": "array"}
+	case OpArrayLength:
+		return ref("ArrayLength")
+	case OpWithinRadius:
+		return ref("GeoRadius")
+	case OpWithinBBox:
+		return ref("GeoBBox")
+	}
+	return fieldTypeSchema(fieldType, ref)
+}
@@ -0,0 +1,182 @@
+package sqlbuilder
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func openAPITestSchema() *Schema {
+	return NewSchema().
+		AddField("name", FieldSpec{Type: FieldString, Sortable: true, Description: "Display name"}).
+		AddField("age", FieldSpec{Type: FieldInteger, Operators: []string{OpEqual, OpGreaterThanEq, OpIn}, Sortable: true}).
+		AddField("body", FieldSpec{Type: FieldString, Operators: []string{OpFullText}}).
+		AddField("created_at", FieldSpec{Type: FieldDateTime, Operators: []string{OpWithinLast, OpInPeriod, OpIsNull}}).
+		AddField("location", FieldSpec{Type: FieldGeo}).
+		SetPaginationLimits(20, 100)
+}
+
+// Test the Schema field registry
+func TestSchema_Fields(t *testing.T) {
+	schema := openAPITestSchema()
+
+	assert.Equal(t, []string{"age", "body", "created_at", "location", "name"}, schema.FieldNames())
+
+	spec, ok := schema.Field("age")
+	assert.True(t, ok)
+	assert.True(t, spec.Allows(OpIn))
+	assert.False(t, spec.Allows(OpLessThan))
+
+	spec, _ = schema.Field("location")
+	assert.Equal(t, []string{OpWithinRadius, OpWithinBBox, OpIsNull, OpIsNotNull}, spec.AllowedOperators())
+
+	_, ok = schema.Field("missing")
+	assert.False(t, ok)
+
+	defaultLimit, maxLimit := schema.PaginationLimits()
+	assert.Equal(t, 20, defaultLimit)
+	assert.Equal(t, 100, maxLimit)
+
+	var nilSchema *Schema
+	_, ok = nilSchema.Field("age")
+	assert.False(t, ok)
+	assert.Nil(t, nilSchema.FieldNames())
+}
+
+// Test DefaultOperators only returns known operators
+func TestDefaultOperators(t *testing.T) {
+	for _, fieldType := range []FieldType{FieldString, FieldInteger, FieldNumber, FieldBoolean, FieldDateTime, FieldArray, FieldJSON, FieldGeo} {
+		operators := DefaultOperators(fieldType)
+		assert.Contains(t, operators, OpIsNull)
+		for _, operator := range operators {
+			assert.True(t, IsOperator(operator), "%s: %s", fieldType, operator)
+		}
+	}
+	assert.NotContains(t, DefaultOperators(FieldBoolean), OpGreaterThan)
+	assert.Contains(t, DefaultOperators(FieldDateTime), OpInPeriod)
+}
+
+// Test the generated JSON Schema
+func TestSchema_JSONSchema(t *testing.T) {
+	document := openAPITestSchema().AdvancedQueryParamsJSONSchema()
+	assert.Equal(t, "https://json-schema.org/draft/2020-12/schema", document["$schema"])
+	assert.Equal(t, "#/$defs/AdvancedQueryParams", document["$ref"])
+
+	defs := document["$defs"].(map[string]any)
+	assert.Equal(t, map[string]any{"type": "array", "items": map[string]any{"$ref": "#/$defs/LogicalGroup"}},
+		defs["AdvancedQueryParams"].(map[string]any)["properties"].(map[string]any)["search_groups"])
+
+	variants := defs["FilterCriteria"].(map[string]any)["oneOf"].([]any)
+	assert.Contains(t, variants, map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"field":    map[string]any{"const": "age"},
+			"operator": map[string]any{"enum": []string{OpEqual, OpGreaterThanEq}},
+			"value":    map[string]any{"type": "integer"},
+		},
+		"required": []string{"field", "operator", "value"},
+	})
+	assert.Contains(t, variants, map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"field":    map[string]any{"const": "age"},
+			"operator": map[string]any{"enum": []string{OpIn}},
+			"value":    map[string]any{"type": "array", "items": map[string]any{"type": "integer"}, "minItems": 1},
+		},
+		"required": []string{"field", "operator", "value"},
+	})
+	assert.Contains(t, variants, map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"field":    map[string]any{"const": "created_at"},
+			"operator": map[string]any{"enum": []string{OpIsNull}},
+		},
+		"required": []string{"field", "operator"},
+	})
+	assert.Contains(t, variants, map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"field":    map[string]any{"const": "location"},
+			"operator": map[string]any{"enum": []string{OpWithinRadius}},
+			"value":    map[string]any{"$ref": "#/$defs/GeoRadius"},
+		},
+		"required": []string{"field", "operator", "value"},
+	})
+	assert.Len(t, defs["SearchCriteria"].(map[string]any)["oneOf"].([]any), len(variants)+1)
+
+	sort := defs["SortCriteria"].(map[string]any)["properties"].(map[string]any)
+	assert.Equal(t, map[string]any{"enum": []string{"age", "name", SortRelevance}}, sort["field"])
+
+	limit := defs["PaginationParams"].(map[string]any)["properties"].(map[string]any)["limit"]
+	assert.Equal(t, map[string]any{"type": "integer", "minimum": 1, "maximum": 100, "default": 20}, limit)
+
+	_, err := json.Marshal(document)
+	assert.NoError(t, err)
+	assert.Equal(t, "#/$defs/QueryParams", openAPITestSchema().QueryParamsJSONSchema()["$ref"])
+}
+
+// Test multi-field and field group variants only advertise operators the fields allow
+func TestSchema_JSONSchemaSearchVariants(t *testing.T) {
+	schema := NewSchema().
+		AddField("name", FieldSpec{Type: FieldString}).
+		AddField("email", FieldSpec{Type: FieldString, Operators: []string{OpEqual, OpIContains}}).
+		AddField("code", FieldSpec{Type: FieldString, Operators: []string{OpFullText}}).
+		AddFieldGroup("contact", "name", "email")
+	defs := schema.AdvancedQueryParamsJSONSchema()["$defs"].(map[string]any)
+	variants := defs["SearchCriteria"].(map[string]any)["oneOf"].([]any)
+
+	multiField := func(fields, operators []string) map[string]any {
+		return map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"field":    map[string]any{"const": ""},
+				"fields":   map[string]any{"type": "array", "items": map[string]any{"enum": fields}, "minItems": 1},
+				"operator": map[string]any{"enum": operators},
+				"value":    map[string]any{"type": "string"},
+				"tokenize": map[string]any{"type": "boolean"},
+			},
+			"required": []string{"fields", "operator", "value"},
+		}
+	}
+	assert.Contains(t, variants, multiField([]string{"email", "name"}, []string{OpEqual, OpIContains}))
+	assert.Contains(t, variants, multiField([]string{"name"},
+		[]string{OpContains, OpStartsWith, OpIStartsWith, OpEndsWith, OpIEndsWith, OpLike, OpILike}))
+
+	assert.Contains(t, variants, map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"field":    map[string]any{"const": "contact"},
+			"operator": map[string]any{"enum": []string{OpEqual, OpIContains}},
+			"value":    map[string]any{"type": "string"},
+		},
+		"required":    []string{"field", "operator", "value"},
+		"description": "Matches any of name, email",
+	})
+}
+
+// Test the generated OpenAPI parameters and components
+func TestSchema_OpenAPI(t *testing.T) {
+	schema := openAPITestSchema()
+
+	parameters := schema.OpenAPIParameters()
+	assert.Len(t, parameters, 6)
+	assert.Equal(t, "search_mode", parameters[1]["name"])
+	assert.Equal(t, "filters", parameters[2]["name"])
+	assert.Equal(t, map[string]any{
+		"application/json": map[string]any{
+			"schema": map[string]any{"type": "array", "items": map[string]any{"$ref": "#/components/schemas/FilterCriteria"}},
+		},
+	}, parameters[2]["content"])
+	assert.Equal(t, map[string]any{"type": "integer", "minimum": 1, "maximum": 100, "default": 20}, parameters[5]["schema"])
+
+	schemas := schema.OpenAPIComponents()["schemas"].(map[string]any)
+	for _, name := range []string{"QueryParams", "AdvancedQueryParams", "SearchCriteria", "FilterCriteria", "SortCriteria", "LogicalGroup", "PaginationParams", "GeoPoint", "GeoRadius", "GeoBBox", "ArrayLength"} {
+		assert.Contains(t, schemas, name)
+	}
+	assert.Equal(t, map[string]any{"$ref": "#/components/schemas/PaginationParams"},
+		schemas["QueryParams"].(map[string]any)["properties"].(map[string]any)["pagination"])
+
+	_, err := json.Marshal(schemas)
+	assert.NoError(t, err)
+}
@@ -0,0 +1,613 @@
+package sqlbuilder
+
+import (
+	"encoding/json"
+	"math/big"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// staticTruth is what the optimizer knows about a condition for every row
+type staticTruth int
+
+const (
+	truthDynamic staticTruth = iota // Depends on the row
+	truthAlways                     // TRUE for every row
+	truthNever                      // FALSE for every row
+	truthNull                       // FALSE or UNKNOWN for every row, so it never matches but NOT does not flip it
+)
+
+// OptimizeGroup simplifies a group without changing the rows it matches
+// The group is normalized with NormalizeGroup, then:
+//   - numeric ranges on a field are merged (age > 5 AND age >= 10 becomes age >= 10) and
+//     intersected with eq and in (age IN (1, 20) AND age > 5 becomes age = 20)
+//   - equalities on a field in an OR become one in (x = 1 OR x = 2 becomes x IN (1, 2))
+//   - tautologies are removed, such as not_in with an empty list or x IS NULL OR x IS NOT NULL
+//
+// It returns false if no row can match, e.g. age > 50 AND age < 10, status in [] or
+// x IS NULL AND x = 1, so the database call can be skipped and an empty page returned.
+// in with an empty list matches no row here, while the builder leaves it out of the SQL.
+// The group is then returned normalized but not simplified. Ranges are only merged for
+// numeric values, as string order depends on the column collation. Integers compare exactly,
+// but fields mixing floats with integers beyond 2^53 are left alone, as are criteria with
+// Fields or Tokenize. Field names are taken as columns, so schema field groups should not
+// be optimized.
+func OptimizeGroup(group LogicalGroup) (LogicalGroup, bool) {
+	normalized := NormalizeGroup(group)
+	optimized, truth := optimizeGroup(normalized, true)
+
+	switch truth {
+	case truthNever, truthNull:
+		return normalized, false
+	case truthAlways:
+		return NormalizeGroup(LogicalGroup{}), true
+	}
+	return optimized, true
+}
+
+// Optimize returns a simplified copy of q and false if no row can match
+// Search groups are optimized with OptimizeGroup, groups that match every row are removed
+// and filter ranges are merged. Conditions of all groups and filters are checked together
+// for contradictions. When false is returned the query is returned normalized but not simplified.
+func (q *AdvancedQueryParams) Optimize() (*AdvancedQueryParams, bool) {
+	normalized := q.Normalize()
+
+	filters := make([]SearchCriteria, len(normalized.Filters))
+	for i, filter := range normalized.Filters {
+		filters[i] = SearchCriteria{Field: filter.Field, Operator: filter.Operator, Value: filter.Value}
+	}
+	all := LogicalGroup{Operator: LogicAnd, Conditions: filters, Groups: normalized.SearchGroups}
+	if _, truth := optimizeGroup(NormalizeGroup(all), true); truth == truthNever || truth == truthNull {
+		return normalized, false
+	}
+
+	optimized := &AdvancedQueryParams{
+		SearchGroups: make([]LogicalGroup, 0, len(normalized.SearchGroups)),
+		Filters:      make([]FilterCriteria, 0, len(normalized.Filters)),
+		Sort:         normalized.Sort,
+		Pagination:   normalized.Pagination,
+	}
+	for _, group := range normalized.SearchGroups {
+		group, truth := optimizeGroup(group, true)
+		if truth != truthAlways {
+			optimized.SearchGroups = append(optimized.SearchGroups, group)
+		}
+	}
+	merged, _ := mergeAnd(filters)
+	for _, criterion := range sortUnique(merged) {
+		optimized.Filters = append(optimized.Filters, FilterCriteria{Field: criterion.Field, Operator: criterion.Operator, Value: criterion.Value})
+	}
+	return optimized, true
+}
+
+// optimizeGroup simplifies a normalized group
+// positive is true when no NOT encloses the group, so rows where it is UNKNOWN are filtered
+// out like rows where it is FALSE and truthNull members can be treated as truthNever.
+func optimizeGroup(group LogicalGroup, positive bool) (LogicalGroup, staticTruth) {
+	if group.Operator != LogicAnd && group.Operator != LogicOr {
+		return group, truthDynamic
+	}
+	if len(group.Conditions) == 0 && len(group.Groups) == 0 {
+		return group, truthAlways
+	}
+	and := group.Operator == LogicAnd
+	innerPositive := positive && !group.Not
+
+	var truths []staticTruth
+	conditions := make([]SearchCriteria, 0, len(group.Conditions))
+	for _, criterion := range group.Conditions {
+		switch truth := criterionTruth(criterion); truth {
+		case truthDynamic:
+			conditions = append(conditions, criterion)
+		default:
+			truths = append(truths, truth)
+		}
+	}
+	groups := make([]LogicalGroup, 0, len(group.Groups))
+	for _, nestedGroup := range group.Groups {
+		nestedGroup, truth := optimizeGroup(nestedGroup, innerPositive)
+		switch truth {
+		case truthDynamic:
+			groups = append(groups, nestedGroup)
+		case truthNull:
+			if !innerPositive {
+				// Keep the member, AND/OR with UNKNOWN must not collapse under NOT
+				groups = append(groups, nestedGroup)
+			}
+			truths = append(truths, truth)
+		default:
+			truths = append(truths, truth)
+		}
+	}
+
+	var merged staticTruth
+	if and {
+		conditions, merged = mergeAnd(conditions)
+	} else {
+		conditions, merged = mergeOr(conditions)
+	}
+	truths = append(truths, merged)
+
+	truth := combineTruths(truths, and, innerPositive)
+	if truth == truthDynamic && len(conditions) == 0 && len(groups) == 0 {
+		// Every member was removed: TRUE members of an AND or FALSE members of an OR
+		truth = truthNever
+		if and {
+			truth = truthAlways
+		}
+	}
+
+	optimized := NormalizeGroup(LogicalGroup{Operator: group.Operator, Conditions: conditions, Groups: groups, Not: group.Not})
+	if !group.Not {
+		return optimized, truth
+	}
+	switch truth {
+	case truthAlways:
+		return optimized, truthNever
+	case truthNever:
+		return optimized, truthAlways
+	}
+	return optimized, truthDynamic
+}
+
+// combineTruths combines the known truths of the members of an AND or OR group
+func combineTruths(truths []staticTruth, and, positive bool) staticTruth {
+	dominant, neutral := truthAlways, truthNever
+	if and {
+		dominant, neutral = truthNever, truthAlways
+	}
+
+	result := truthDynamic
+	for _, truth := range truths {
+		switch {
+		case truth == dominant:
+			return dominant
+		case truth == truthNull && (and || positive):
+			result = truthNull
+		case truth == neutral, truth == truthDynamic, truth == truthNull:
+		}
+	}
+	if result == truthNull && positive {
+		return truthNever
+	}
+	return result
+}
+
+// criterionTruth returns the truth of criteria that do not depend on the row
+func criterionTruth(criterion SearchCriteria) staticTruth {
+	values, ok := criterion.Value.([]any)
+	if !ok || len(values) > 0 || !plainCriterion(criterion) {
+		return truthDynamic
+	}
+	switch criterion.Operator {
+	case OpIn:
+		return truthNever
+	case OpNotIn:
+		return truthAlways
+	}
+	return truthDynamic
+}
+
+// numericBound is one end of a numeric range
+type numericBound struct {
+	value     *big.Rat
+	inclusive bool
+	original  any
+}
+
+// fieldRange collects the numeric constraints ANDed on a field
+type fieldRange struct {
+	lower, upper *numericBound
+	set          []any // Allowed values from eq and in, nil when unconstrained
+	excluded     []any // Values from ne and not_in
+}
+
+// mergeAnd merges numeric constraints on the same field and detects contradictions
+func mergeAnd(conditions []SearchCriteria) ([]SearchCriteria, staticTruth) {
+	nulls := make(map[string]map[string]bool)
+	compared := make(map[string]bool)
+	ranges := make(map[string]*fieldRange)
+	var fields []string
+	result := make([]SearchCriteria, 0, len(conditions))
+
+	ambiguous := ambiguousFields(conditions)
+
+	for _, criterion := range conditions {
+		field := criterion.Field
+		if !plainCriterion(criterion) {
+			result = append(result, criterion)
+			continue
+		}
+		if nulls[field] == nil {
+			nulls[field] = make(map[string]bool)
+		}
+		nulls[field][criterion.Operator] = true
+		compared[field] = compared[field] || rejectsNull(criterion)
+
+		r, ok := ranges[field]
+		if !ok {
+			r = &fieldRange{}
+		}
+		if ambiguous[field] || !r.add(criterion) {
+			result = append(result, criterion)
+			continue
+		}
+		if !ok {
+			ranges[field] = r
+			fields = append(fields, field)
+		}
+	}
+
+	truth := truthDynamic
+	for field, operators := range nulls {
+		if operators[OpIsNull] && operators[OpIsNotNull] {
+			return conditions, truthNever
+		}
+		if operators[OpIsNull] && compared[field] {
+			// Comparisons with NULL are UNKNOWN
+			truth = truthNull
+		}
+	}
+
+	for _, field := range fields {
+		merged, ok := ranges[field].criteria(field)
+		if !ok {
+			truth = truthNull
+			continue
+		}
+		result = append(result, merged...)
+	}
+	if truth != truthDynamic {
+		return conditions, truth
+	}
+	return result, truthDynamic
+}
+
+// rejectsNull returns true if the criterion is never TRUE on a NULL column
+func rejectsNull(criterion SearchCriteria) bool {
+	if criterion.Value == nil {
+		return false
+	}
+	switch criterion.Operator {
+	case OpEqual, OpNotEqual, OpGreaterThan, OpGreaterThanEq, OpLessThan, OpLessThanEq, OpIn, OpNotIn:
+		return true
+	}
+	return false
+}
+
+// add records a numeric constraint, false if the criterion is not one
+func (r *fieldRange) add(criterion SearchCriteria) bool {
+	switch criterion.Operator {
+	case OpIn, OpNotIn:
+		values, ok := criterion.Value.([]any)
+		if !ok || len(values) == 0 {
+			return false
+		}
+		for _, value := range values {
+			if _, ok := numericValue(value); !ok {
+				return false
+			}
+		}
+		if criterion.Operator == OpNotIn {
+			r.excluded = append(r.excluded, values...)
+		} else {
+			r.intersect(values)
+		}
+		return true
+	}
+
+	number, ok := numericValue(criterion.Value)
+	if !ok {
+		return false
+	}
+	bound := &numericBound{value: number, original: criterion.Value}
+	switch criterion.Operator {
+	case OpEqual:
+		r.intersect([]any{criterion.Value})
+	case OpNotEqual:
+		r.excluded = append(r.excluded, criterion.Value)
+	case OpGreaterThan, OpGreaterThanEq:
+		bound.inclusive = criterion.Operator == OpGreaterThanEq
+		if cmp := compareBound(bound, r.lower); r.lower == nil || cmp > 0 || (cmp == 0 && !bound.inclusive) {
+			r.lower = bound
+		}
+	case OpLessThan, OpLessThanEq:
+		bound.inclusive = criterion.Operator == OpLessThanEq
+		if cmp := compareBound(bound, r.upper); r.upper == nil || cmp < 0 || (cmp == 0 && !bound.inclusive) {
+			r.upper = bound
+		}
+	default:
+		return false
+	}
+	return true
+}
+
+// intersect restricts the allowed values to values
+func (r *fieldRange) intersect(values []any) {
+	if r.set == nil {
+		r.set = append([]any{}, values...)
+		return
+	}
+	kept := make([]any, 0, len(r.set))
+	for _, value := range r.set {
+		if containsNumber(values, value) {
+			kept = append(kept, value)
+		}
+	}
+	r.set = kept
+}
+
+// allows returns true if value is within the bounds and not excluded
+func (r *fieldRange) allows(value any) bool {
+	number, _ := numericValue(value)
+	if r.lower != nil {
+		if cmp := number.Cmp(r.lower.value); cmp < 0 || (cmp == 0 && !r.lower.inclusive) {
+			return false
+		}
+	}
+	if r.upper != nil {
+		if cmp := number.Cmp(r.upper.value); cmp > 0 || (cmp == 0 && !r.upper.inclusive) {
+			return false
+		}
+	}
+	return !containsNumber(r.excluded, value)
+}
+
+// criteria returns the simplest criteria for the range, false if it allows no value
+func (r *fieldRange) criteria(field string) ([]SearchCriteria, bool) {
+	if r.set != nil {
+		var allowed []any
+		for _, value := range r.set {
+			if r.allows(value) && !containsNumber(allowed, value) {
+				allowed = append(allowed, value)
+			}
+		}
+		switch len(allowed) {
+		case 0:
+			return nil, false
+		case 1:
+			return []SearchCriteria{CreateSearchCondition(field, OpEqual, allowed[0])}, true
+		}
+		sortNumbers(allowed)
+		return []SearchCriteria{CreateSearchCondition(field, OpIn, allowed)}, true
+	}
+
+	if r.lower != nil && r.upper != nil {
+		switch r.lower.value.Cmp(r.upper.value) {
+		case 1:
+			return nil, false
+		case 0:
+			if !r.lower.inclusive || !r.upper.inclusive || containsNumber(r.excluded, r.lower.original) {
+				return nil, false
+			}
+			return []SearchCriteria{CreateSearchCondition(field, OpEqual, r.lower.original)}, true
+		}
+	}
+
+	var criteria []SearchCriteria
+	if r.lower != nil {
+		operator := OpGreaterThan
+		if r.lower.inclusive {
+			operator = OpGreaterThanEq
+		}
+		criteria = append(criteria, CreateSearchCondition(field, operator, r.lower.original))
+	}
+	if r.upper != nil {
+		operator := OpLessThan
+		if r.upper.inclusive {
+			operator = OpLessThanEq
+		}
+		criteria = append(criteria, CreateSearchCondition(field, operator, r.upper.original))
+	}
+
+	// Exclusions outside the range are implied by it
+	var excluded []any
+	for _, value := range r.excluded {
+		if withinBounds(r, value) && !containsNumber(excluded, value) {
+			excluded = append(excluded, value)
+		}
+	}
+	switch len(excluded) {
+	case 0:
+	case 1:
+		criteria = append(criteria, CreateSearchCondition(field, OpNotEqual, excluded[0]))
+	default:
+		sortNumbers(excluded)
+		criteria = append(criteria, CreateSearchCondition(field, OpNotIn, excluded))
+	}
+	return criteria, true
+}
+
+// withinBounds returns true if value is within the bounds of r, ignoring exclusions
+func withinBounds(r *fieldRange, value any) bool {
+	bounds := fieldRange{lower: r.lower, upper: r.upper}
+	return bounds.allows(value)
+}
+
+// mergeOr turns equalities on a field into one in, keeps the loosest one-sided numeric
+// bounds and detects x IS NULL OR x IS NOT NULL
+func mergeOr(conditions []SearchCriteria) ([]SearchCriteria, staticTruth) {
+	nulls := make(map[string]map[string]bool)
+	values := make(map[string][]any)
+	counts := make(map[string]int)
+	lowers := make(map[string]SearchCriteria)
+	uppers := make(map[string]SearchCriteria)
+	var fields []string
+	result := make([]SearchCriteria, 0, len(conditions))
+	ambiguous := ambiguousFields(conditions)
+
+	for _, criterion := range conditions {
+		field := criterion.Field
+		if !plainCriterion(criterion) {
+			result = append(result, criterion)
+			continue
+		}
+		if nulls[field] == nil {
+			nulls[field] = make(map[string]bool)
+			fields = append(fields, field)
+		}
+		nulls[field][criterion.Operator] = true
+
+		switch criterion.Operator {
+		case OpEqual:
+			if criterion.Value != nil && !isSliceValue(criterion.Value) {
+				values[field] = append(values[field], criterion.Value)
+				counts[field]++
+				continue
+			}
+		case OpIn:
+			if list, ok := criterion.Value.([]any); ok {
+				values[field] = append(values[field], list...)
+				counts[field]++
+				continue
+			}
+		case OpGreaterThan, OpGreaterThanEq:
+			if _, ok := numericValue(criterion.Value); ok && !ambiguous[field] {
+				if current, exists := lowers[field]; !exists || looser(criterion, current, -1) {
+					lowers[field] = criterion
+				}
+				continue
+			}
+		case OpLessThan, OpLessThanEq:
+			if _, ok := numericValue(criterion.Value); ok && !ambiguous[field] {
+				if current, exists := uppers[field]; !exists || looser(criterion, current, 1) {
+					uppers[field] = criterion
+				}
+				continue
+			}
+		}
+		result = append(result, criterion)
+	}
+
+	for _, field := range fields {
+		if nulls[field][OpIsNull] && nulls[field][OpIsNotNull] {
+			return conditions, truthAlways
+		}
+		switch {
+		case counts[field] == 1 && len(values[field]) == 1:
+			result = append(result, CreateSearchCondition(field, OpEqual, values[field][0]))
+		case counts[field] > 0:
+			result = append(result, CreateSearchCondition(field, OpIn, sortUnique(values[field])))
+		}
+		if lower, ok := lowers[field]; ok {
+			result = append(result, lower)
+		}
+		if upper, ok := uppers[field]; ok {
+			result = append(result, upper)
+		}
+	}
+	return result, truthDynamic
+}
+
+// looser returns true if the one-sided bound a allows more values than b
+// direction is -1 for lower bounds and 1 for upper bounds.
+func looser(a, b SearchCriteria, direction int) bool {
+	av, _ := numericValue(a.Value)
+	bv, _ := numericValue(b.Value)
+	if cmp := av.Cmp(bv); cmp != 0 {
+		return cmp == direction
+	}
+	return a.Operator == OpGreaterThanEq || a.Operator == OpLessThanEq
+}
+
+// plainCriterion returns true for a criterion on Field alone without flags, the only kind
+// the optimizer rewrites, as rebuilding any other kind would change what it renders
+func plainCriterion(criterion SearchCriteria) bool {
+	return criterion.Field != "" && len(criterion.Fields) == 0 && !criterion.Tokenize
+}
+
+// maxExactInteger is the largest integer magnitude a float64 holds exactly, 2^53
+var maxExactInteger = big.NewRat(1<<53, 1)
+
+// ambiguousFields returns the fields of plain criteria comparing floats and integers beyond 2^53
+// Databases differ in whether such integers are compared exactly or as doubles, so the
+// numeric criteria of these fields are left alone.
+func ambiguousFields(conditions []SearchCriteria) map[string]bool {
+	floats := make(map[string]bool)
+	large := make(map[string]bool)
+	for _, criterion := range conditions {
+		if !plainCriterion(criterion) {
+			continue
+		}
+		values := []any{criterion.Value}
+		if list, ok := criterion.Value.([]any); ok {
+			values = list
+		}
+		for _, value := range values {
+			number, ok := numericValue(value)
+			switch {
+			case !ok:
+			case isFloatValue(value):
+				floats[criterion.Field] = true
+			case new(big.Rat).Abs(number).Cmp(maxExactInteger) > 0:
+				large[criterion.Field] = true
+			}
+		}
+	}
+
+	ambiguous := make(map[string]bool)
+	for field := range floats {
+		ambiguous[field] = large[field]
+	}
+	return ambiguous
+}
+
+// numericValue converts Go numbers and json.Number exactly, other values (strings, bools, NaN,
+// infinities) are not numeric
+func numericValue(value any) (*big.Rat, bool) {
+	if value == nil {
+		return nil, false
+	}
+	if number, ok := value.(json.Number); ok {
+		return new(big.Rat).SetString(number.String())
+	}
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return new(big.Rat).SetInt64(v.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return new(big.Rat).SetInt(new(big.Int).SetUint64(v.Uint())), true
+	case reflect.Float32, reflect.Float64:
+		number := new(big.Rat).SetFloat64(v.Float())
+		return number, number != nil
+	}
+	return nil, false
+}
+
+// isFloatValue returns true for float32 and float64 values and json.Number values with a fraction or exponent
+func isFloatValue(value any) bool {
+	if number, ok := value.(json.Number); ok {
+		return strings.ContainsAny(number.String(), ".eE")
+	}
+	kind := reflect.ValueOf(value).Kind()
+	return kind == reflect.Float32 || kind == reflect.Float64
+}
+
+// compareBound compares bound with current, 0 when current is nil
+func compareBound(bound, current *numericBound) int {
+	if current == nil {
+		return 0
+	}
+	return bound.value.Cmp(current.value)
+}
+
+// containsNumber returns true if a value of values is numerically equal to value
+func containsNumber(values []any, value any) bool {
+	number, _ := numericValue(value)
+	for _, v := range values {
+		if n, _ := numericValue(v); n.Cmp(number) == 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// sortNumbers sorts numeric values in ascending order
+func sortNumbers(values []any) {
+	sort.SliceStable(values, func(i, j int) bool {
+		a, _ := numericValue(values[i])
+		b, _ := numericValue(values[j])
+		return a.Cmp(b) < 0
+	})
+}
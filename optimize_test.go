@@ -0,0 +1,278 @@
+package sqlbuilder
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Test OptimizeGroup simplifications and contradictions
+func TestOptimizeGroup(t *testing.T) {
+	tests := []struct {
+		name        string
+		input       LogicalGroup
+		expected    LogicalGroup
+		satisfiable bool
+	}{
+		{
+			name: "merge lower bounds",
+			input: CreateSearchGroup(LogicAnd,
+				CreateSearchCondition("age", OpGreaterThan, 5),
+				CreateSearchCondition("age", OpGreaterThanEq, 10),
+				CreateSearchCondition("age", OpLessThan, 60),
+			),
+			expected: CreateSearchGroup(LogicAnd,
+				CreateSearchCondition("age", OpGreaterThanEq, 10),
+				CreateSearchCondition("age", OpLessThan, 60),
+			),
+			satisfiable: true,
+		},
+		{
+			name: "bounds meet",
+			input: CreateSearchGroup(LogicAnd,
+				CreateSearchCondition("age", OpGreaterThanEq, 30),
+				CreateSearchCondition("age", OpLessThanEq, 30),
+			),
+			expected:    CreateSearchGroup(LogicAnd, CreateSearchCondition("age", OpEqual, 30)),
+			satisfiable: true,
+		},
+		{
+			name: "in within range",
+			input: CreateSearchGroup(LogicAnd,
+				CreateSearchCondition("age", OpIn, []any{1, 20, 40}),
+				CreateSearchCondition("age", OpGreaterThan, 5),
+				CreateSearchCondition("age", OpNotEqual, 40),
+				CreateSearchCondition("status", OpEqual, "active"),
+			),
+			expected: CreateSearchGroup(LogicAnd,
+				CreateSearchCondition("age", OpEqual, 20),
+				CreateSearchCondition("status", OpEqual, "active"),
+			),
+			satisfiable: true,
+		},
+		{
+			name: "exclusions outside the range",
+			input: CreateSearchGroup(LogicAnd,
+				CreateSearchCondition("age", OpGreaterThan, 18),
+				CreateSearchCondition("age", OpNotIn, []any{10, 30, 20}),
+			),
+			expected: CreateSearchGroup(LogicAnd,
+				CreateSearchCondition("age", OpGreaterThan, 18),
+				CreateSearchCondition("age", OpNotIn, []any{20, 30}),
+			),
+			satisfiable: true,
+		},
+		{
+			name: "or of equalities",
+			input: CreateSearchGroup(LogicOr,
+				CreateSearchCondition("status", OpEqual, "b"),
+				CreateSearchCondition("status", OpEqual, "a"),
+				CreateSearchCondition("status", OpIn, []any{"c", "a"}),
+				CreateSearchCondition("age", OpGreaterThan, 50),
+				CreateSearchCondition("age", OpGreaterThanEq, 40),
+			),
+			expected: CreateSearchGroup(LogicOr,
+				CreateSearchCondition("age", OpGreaterThanEq, 40),
+				CreateSearchCondition("status", OpIn, []any{"a", "b", "c"}),
+			),
+			satisfiable: true,
+		},
+		{
+			name: "redundant equality",
+			input: CreateSearchGroup(LogicOr,
+				CreateSearchCondition("age", OpEqual, 1),
+				CreateSearchCondition("age", OpEqual, 1),
+			),
+			expected:    CreateSearchGroup(LogicOr, CreateSearchCondition("age", OpEqual, 1)),
+			satisfiable: true,
+		},
+		{
+			name: "tautologies removed",
+			input: LogicalGroup{
+				Operator:   LogicAnd,
+				Conditions: []SearchCriteria{CreateSearchCondition("status", OpNotIn, []any{}), CreateSearchCondition("age", OpEqual, 1)},
+				Groups:     []LogicalGroup{CreateSearchGroup(LogicOr, CreateSearchCondition("name", OpIsNull, nil), CreateSearchCondition("name", OpIsNotNull, nil))},
+			},
+			expected:    CreateSearchGroup(LogicAnd, CreateSearchCondition("age", OpEqual, 1)),
+			satisfiable: true,
+		},
+		{
+			name: "unsatisfiable member of or is removed",
+			input: LogicalGroup{
+				Operator:   LogicOr,
+				Conditions: []SearchCriteria{CreateSearchCondition("status", OpIn, []any{}), CreateSearchCondition("name", OpEqual, "x")},
+				Groups: []LogicalGroup{CreateSearchGroup(LogicAnd,
+					CreateSearchCondition("age", OpGreaterThan, 50),
+					CreateSearchCondition("age", OpLessThan, 10),
+				)},
+			},
+			expected:    CreateSearchGroup(LogicOr, CreateSearchCondition("name", OpEqual, "x")),
+			satisfiable: true,
+		},
+		{
+			name: "negated contradiction is kept",
+			input: LogicalGroup{
+				Operator:   LogicAnd,
+				Conditions: []SearchCriteria{CreateSearchCondition("age", OpGreaterThan, 50), CreateSearchCondition("age", OpLessThan, 10)},
+				Not:        true,
+			},
+			expected: LogicalGroup{
+				Operator:   LogicAnd,
+				Conditions: []SearchCriteria{CreateSearchCondition("age", OpGreaterThan, 50), CreateSearchCondition("age", OpLessThan, 10)},
+				Groups:     []LogicalGroup{},
+				Not:        true,
+			},
+			satisfiable: true,
+		},
+		{
+			name:        "match everything",
+			input:       CreateSearchGroup(LogicAnd, CreateSearchCondition("status", OpNotIn, []any{})),
+			expected:    CreateSearchGroup(LogicAnd),
+			satisfiable: true,
+		},
+		{
+			name: "string bounds are kept",
+			input: CreateSearchGroup(LogicAnd,
+				CreateSearchCondition("name", OpGreaterThan, "m"),
+				CreateSearchCondition("name", OpLessThan, "b"),
+			),
+			expected: CreateSearchGroup(LogicAnd,
+				CreateSearchCondition("name", OpGreaterThan, "m"),
+				CreateSearchCondition("name", OpLessThan, "b"),
+			),
+			satisfiable: true,
+		},
+		{
+			name:        "large integers stay distinct",
+			input:       CreateSearchGroup(LogicAnd, CreateSearchCondition("id", OpIn, []any{int64(9007199254740993), int64(9007199254740992)})),
+			expected:    CreateSearchGroup(LogicAnd, CreateSearchCondition("id", OpIn, []any{int64(9007199254740992), int64(9007199254740993)})),
+			satisfiable: true,
+		},
+		{
+			name: "large integer excluded neighbour",
+			input: CreateSearchGroup(LogicAnd,
+				CreateSearchCondition("id", OpEqual, int64(9007199254740993)),
+				CreateSearchCondition("id", OpNotEqual, int64(9007199254740992)),
+			),
+			expected:    CreateSearchGroup(LogicAnd, CreateSearchCondition("id", OpEqual, int64(9007199254740993))),
+			satisfiable: true,
+		},
+		{
+			name: "large integers and floats are left alone",
+			input: CreateSearchGroup(LogicAnd,
+				CreateSearchCondition("id", OpEqual, uint64(9007199254740993)),
+				CreateSearchCondition("id", OpLessThan, 9007199254740992.0),
+			),
+			expected: CreateSearchGroup(LogicAnd,
+				CreateSearchCondition("id", OpEqual, uint64(9007199254740993)),
+				CreateSearchCondition("id", OpLessThan, 9007199254740992.0),
+			),
+			satisfiable: true,
+		},
+		{
+			name: "tokenized criteria are left alone",
+			input: CreateSearchGroup(LogicOr,
+				SearchCriteria{Field: "name", Operator: OpEqual, Value: "a b", Tokenize: true},
+				CreateSearchCondition("name", OpEqual, "c"),
+			),
+			expected: CreateSearchGroup(LogicOr,
+				SearchCriteria{Field: "name", Operator: OpEqual, Value: "a b", Tokenize: true},
+				CreateSearchCondition("name", OpEqual, "c"),
+			),
+			satisfiable: true,
+		},
+		{
+			name: "tokenized range is not merged",
+			input: CreateSearchGroup(LogicAnd,
+				SearchCriteria{Field: "age", Operator: OpGreaterThan, Value: 50, Tokenize: true},
+				CreateSearchCondition("age", OpLessThan, 10),
+			),
+			expected: CreateSearchGroup(LogicAnd,
+				SearchCriteria{Field: "age", Operator: OpGreaterThan, Value: 50, Tokenize: true},
+				CreateSearchCondition("age", OpLessThan, 10),
+			),
+			satisfiable: true,
+		},
+		{name: "empty range", input: CreateSearchGroup(LogicAnd, CreateSearchCondition("age", OpGreaterThan, 50), CreateSearchCondition("age", OpLessThan, 10)), satisfiable: false},
+		{name: "open bounds meet", input: CreateSearchGroup(LogicAnd, CreateSearchCondition("age", OpGreaterThan, 30), CreateSearchCondition("age", OpLessThanEq, 30)), satisfiable: false},
+		{name: "empty in", input: CreateSearchGroup(LogicAnd, CreateSearchCondition("status", OpIn, []any{}), CreateSearchCondition("age", OpEqual, 1)), satisfiable: false},
+		{name: "different equalities", input: CreateSearchGroup(LogicAnd, CreateSearchCondition("age", OpEqual, 1), CreateSearchCondition("age", OpEqual, 2.0)), satisfiable: false},
+		{name: "equality excluded", input: CreateSearchGroup(LogicAnd, CreateSearchCondition("age", OpEqual, 1), CreateSearchCondition("age", OpNotIn, []any{1, 2})), satisfiable: false},
+		{name: "null and comparison", input: CreateSearchGroup(LogicAnd, CreateSearchCondition("age", OpIsNull, nil), CreateSearchCondition("age", OpGreaterThan, 1)), satisfiable: false},
+		{name: "null and not null", input: CreateSearchGroup(LogicAnd, CreateSearchCondition("age", OpIsNull, nil), CreateSearchCondition("age", OpIsNotNull, nil)), satisfiable: false},
+		{
+			name: "every member of or unsatisfiable",
+			input: LogicalGroup{
+				Operator:   LogicOr,
+				Conditions: []SearchCriteria{CreateSearchCondition("status", OpIn, []any{})},
+				Groups:     []LogicalGroup{CreateSearchGroup(LogicAnd, CreateSearchCondition("age", OpEqual, 1), CreateSearchCondition("age", OpEqual, 2))},
+			},
+			satisfiable: false,
+		},
+		{
+			name: "negated tautology",
+			input: LogicalGroup{
+				Operator:   LogicOr,
+				Conditions: []SearchCriteria{CreateSearchCondition("age", OpIsNull, nil), CreateSearchCondition("age", OpIsNotNull, nil)},
+				Not:        true,
+			},
+			satisfiable: false,
+		},
+	}
+
+	items := []map[string]any{
+		{"id": 1, "age": 1, "status": "a", "name": "x"},
+		{"id": int64(9007199254740992), "age": 20, "status": "active", "name": "m"},
+		{"id": int64(9007199254740993), "age": 30, "status": "b", "name": nil},
+		{"id": 4, "age": 40, "status": "c", "name": "z"},
+		{"id": 5, "age": 45, "status": "d", "name": "a b"},
+		{"id": 6, "age": 60, "status": "active", "name": "x"},
+		{"id": 7, "age": nil, "status": "a", "name": "c"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			optimized, satisfiable := OptimizeGroup(tt.input)
+			assert.Equal(t, tt.satisfiable, satisfiable)
+			if !satisfiable {
+				assert.Equal(t, canonicalKey(NormalizeGroup(tt.input)), canonicalKey(optimized))
+				return
+			}
+			assert.Equal(t, canonicalKey(NormalizeGroup(tt.expected)), canonicalKey(optimized))
+
+			// The optimized group matches the same rows
+			before, _, err := Evaluate(items, &AdvancedQueryParams{SearchGroups: []LogicalGroup{tt.input}})
+			assert.NoError(t, err)
+			after, _, err := Evaluate(items, &AdvancedQueryParams{SearchGroups: []LogicalGroup{optimized}})
+			assert.NoError(t, err)
+			assert.Equal(t, before, after)
+		})
+	}
+}
+
+// Test AdvancedQueryParams.Optimize across groups and filters
+func TestAdvancedQueryParams_Optimize(t *testing.T) {
+	q := NewAdvancedQueryParams()
+	q.SearchGroups = []LogicalGroup{
+		CreateSearchGroup(LogicOr, CreateSearchCondition("status", OpEqual, "a"), CreateSearchCondition("status", OpEqual, "b")),
+		CreateSearchGroup(LogicAnd, CreateSearchCondition("tags", OpNotIn, []any{})),
+	}
+	q.Filters = []FilterCriteria{
+		{Field: "age", Operator: OpGreaterThan, Value: 18},
+		{Field: "age", Operator: OpGreaterThan, Value: 21},
+	}
+	q.SetPagination(2, 20)
+
+	optimized, satisfiable := q.Optimize()
+	assert.True(t, satisfiable)
+	assert.Equal(t, []LogicalGroup{CreateSearchGroup(LogicOr, CreateSearchCondition("status", OpIn, []any{"a", "b"}))}, optimized.SearchGroups)
+	assert.Equal(t, []FilterCriteria{{Field: "age", Operator: OpGreaterThan, Value: 21}}, optimized.Filters)
+	assert.Equal(t, q.Pagination, optimized.Pagination)
+	assert.Len(t, q.Filters, 2)
+
+	// A filter contradicting a search group
+	q.SearchGroups = []LogicalGroup{CreateSearchGroup(LogicAnd, CreateSearchCondition("age", OpLessThan, 10))}
+	optimized, satisfiable = q.Optimize()
+	assert.False(t, satisfiable)
+	assert.Equal(t, q.Normalize(), optimized)
+}
@@ -0,0 +1,129 @@
+package sqlbuilder
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+)
+
+// ErrInvalidPagination is returned when a strict pagination policy rejects a page or limit
+var ErrInvalidPagination = errors.New("sqlbuilder: invalid pagination")
+
+// Page size bounds of NewPaginationParams
+const (
+	DefaultPageLimit = 10
+	MaxPageLimit     = 1000
+)
+
+// PaginationPolicy bounds client-supplied pagination
+// Out-of-range values are clamped, or rejected with ErrInvalidPagination when Strict is set.
+// Missing (zero or negative) pages and limits always fall back to page 1 and DefaultLimit.
+// Even without MaxPage, pages whose offset would overflow an int are out of range.
+type PaginationPolicy struct {
+	DefaultLimit int  // Page size when none is given, DefaultPageLimit when zero
+	MaxLimit     int  // Largest page size, 0 means unlimited
+	MaxPage      int  // Largest page number, bounding OFFSET scans, 0 means unlimited
+	Strict       bool // Reject out-of-range values instead of clamping them
+}
+
+// DefaultPaginationPolicy returns the policy of NewPaginationParams
+func DefaultPaginationPolicy() PaginationPolicy {
+	return PaginationPolicy{DefaultLimit: DefaultPageLimit, MaxLimit: MaxPageLimit}
+}
+
+// PaginationPolicy returns a clamping policy with the schema pagination limits
+func (sc *Schema) PaginationPolicy() PaginationPolicy {
+	defaultLimit, maxLimit := sc.PaginationLimits()
+	return PaginationPolicy{DefaultLimit: defaultLimit, MaxLimit: maxLimit}
+}
+
+// Apply returns params with defaults filled in, bounds enforced and the offset recomputed
+func (p PaginationPolicy) Apply(params PaginationParams) (PaginationParams, error) {
+	page, limit := params.Page, params.Limit
+	if limit <= 0 {
+		limit = p.DefaultLimit
+		if limit <= 0 {
+			limit = DefaultPageLimit
+		}
+	}
+	if page <= 0 {
+		page = 1
+	}
+
+	if p.MaxLimit > 0 && limit > p.MaxLimit {
+		if p.Strict {
+			return PaginationParams{}, fmt.Errorf("%w: limit %d exceeds %d", ErrInvalidPagination, limit, p.MaxLimit)
+		}
+		limit = p.MaxLimit
+	}
+	if p.MaxPage > 0 && page > p.MaxPage {
+		if p.Strict {
+			return PaginationParams{}, fmt.Errorf("%w: page %d exceeds %d", ErrInvalidPagination, page, p.MaxPage)
+		}
+		page = p.MaxPage
+	}
+	if lastPage := maxOffsetPage(limit); page > lastPage {
+		if p.Strict {
+			return PaginationParams{}, fmt.Errorf("%w: page %d overflows the offset", ErrInvalidPagination, page)
+		}
+		page = lastPage
+	}
+
+	return PaginationParams{Page: page, Limit: limit, Offset: (page - 1) * limit}, nil
+}
+
+// Resolve applies the policy and handles pages past the last page of totalRecords
+// Such pages are clamped to the last page, or rejected when Strict is set.
+func (p PaginationPolicy) Resolve(params PaginationParams, totalRecords int) (PaginationParams, error) {
+	params, err := p.Apply(params)
+	if err != nil {
+		return PaginationParams{}, err
+	}
+
+	lastPage := max(1, pageCount(totalRecords, params.Limit))
+	if params.Page > lastPage {
+		if p.Strict {
+			return PaginationParams{}, fmt.Errorf("%w: page %d is past the last page %d", ErrInvalidPagination, params.Page, lastPage)
+		}
+		params.Page = lastPage
+		params.Offset = (lastPage - 1) * params.Limit
+	}
+	return params, nil
+}
+
+// maxOffsetPage returns the largest page whose offset (page-1)*limit fits an int
+func maxOffsetPage(limit int) int {
+	return min(math.MaxInt/limit, math.MaxInt-1) + 1
+}
+
+// pageCount returns the number of pages needed for totalRecords, without overflowing for large limits
+func pageCount(totalRecords, limit int) int {
+	pages := totalRecords / limit
+	if totalRecords%limit > 0 {
+		pages++
+	}
+	return pages
+}
+
+// UnmarshalJSON decodes pagination and recomputes the offset from the page
+// A client-supplied offset is only used to derive the page when no page is given,
+// so page and offset can never disagree. Limits are bounded as in NewPaginationParams.
+func (p *PaginationParams) UnmarshalJSON(data []byte) error {
+	var raw struct {
+		Page   int `json:"page"`
+		Limit  int `json:"limit"`
+		Offset int `json:"offset"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	page := raw.Page
+	if page <= 0 && raw.Offset > 0 {
+		limit := NewPaginationParams(1, raw.Limit).Limit
+		page = min(raw.Offset/limit, math.MaxInt-1) + 1
+	}
+	*p = NewPaginationParams(page, raw.Limit)
+	return nil
+}
@@ -0,0 +1,221 @@
+package sqlbuilder
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Test PaginationPolicy bounds, clamping and strict mode
+func TestPaginationPolicy(t *testing.T) {
+	tests := []struct {
+		name     string
+		policy   PaginationPolicy
+		params   PaginationParams
+		total    int
+		expected PaginationParams
+		err      string
+	}{
+		{
+			name:     "defaults",
+			policy:   PaginationPolicy{DefaultLimit: 20, MaxLimit: 100},
+			params:   PaginationParams{Offset: 500},
+			total:    1000,
+			expected: PaginationParams{Page: 1, Limit: 20, Offset: 0},
+		},
+		{
+			name:     "limit clamped",
+			policy:   PaginationPolicy{MaxLimit: 100},
+			params:   PaginationParams{Page: 2, Limit: 1000000},
+			total:    1000,
+			expected: PaginationParams{Page: 2, Limit: 100, Offset: 100},
+		},
+		{
+			name:   "limit rejected",
+			policy: PaginationPolicy{MaxLimit: 100, Strict: true},
+			params: PaginationParams{Page: 2, Limit: 1000000},
+			err:    "sqlbuilder: invalid pagination: limit 1000000 exceeds 100",
+		},
+		{
+			name:     "max page clamped",
+			policy:   PaginationPolicy{MaxPage: 50},
+			params:   PaginationParams{Page: 99999, Limit: 10},
+			total:    1000000,
+			expected: PaginationParams{Page: 50, Limit: 10, Offset: 490},
+		},
+		{
+			name:   "max page rejected",
+			policy: PaginationPolicy{MaxPage: 50, Strict: true},
+			params: PaginationParams{Page: 51, Limit: 10},
+			err:    "sqlbuilder: invalid pagination: page 51 exceeds 50",
+		},
+		{
+			name:     "offset overflow clamped",
+			policy:   PaginationPolicy{},
+			params:   PaginationParams{Page: math.MaxInt, Limit: 10},
+			total:    math.MaxInt,
+			expected: PaginationParams{Page: math.MaxInt/10 + 1, Limit: 10, Offset: math.MaxInt / 10 * 10},
+		},
+		{
+			name:   "offset overflow rejected",
+			policy: PaginationPolicy{Strict: true},
+			params: PaginationParams{Page: math.MaxInt/2 + 2, Limit: 2},
+			err:    fmt.Sprintf("sqlbuilder: invalid pagination: page %d overflows the offset", math.MaxInt/2+2),
+		},
+		{
+			name:     "past last page clamped",
+			policy:   DefaultPaginationPolicy(),
+			params:   PaginationParams{Page: 9, Limit: 10},
+			total:    23,
+			expected: PaginationParams{Page: 3, Limit: 10, Offset: 20},
+		},
+		{
+			name:     "no records",
+			policy:   DefaultPaginationPolicy(),
+			params:   PaginationParams{Page: 4, Limit: 10},
+			expected: PaginationParams{Page: 1, Limit: 10, Offset: 0},
+		},
+		{
+			name:   "past last page rejected",
+			policy: PaginationPolicy{Strict: true},
+			params: PaginationParams{Page: 4, Limit: 10},
+			total:  23,
+			err:    "sqlbuilder: invalid pagination: page 4 is past the last page 3",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			params, err := tt.policy.Resolve(tt.params, tt.total)
+			if tt.err != "" {
+				assert.ErrorIs(t, err, ErrInvalidPagination)
+				assert.EqualError(t, err, tt.err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, params)
+		})
+	}
+
+	schema := NewSchema().SetPaginationLimits(25, 200)
+	assert.Equal(t, PaginationPolicy{DefaultLimit: 25, MaxLimit: 200}, schema.PaginationPolicy())
+	params, err := DefaultPaginationPolicy().Apply(PaginationParams{Page: math.MaxInt, Limit: MaxPageLimit})
+	assert.NoError(t, err)
+	assert.GreaterOrEqual(t, params.Offset, 0)
+	assert.Equal(t, params.Offset, NewPaginationParams(math.MaxInt, MaxPageLimit).Offset)
+
+	var nilSchema *Schema
+	params, err = nilSchema.PaginationPolicy().Apply(PaginationParams{Limit: 5000})
+	assert.NoError(t, err)
+	assert.Equal(t, PaginationParams{Page: 1, Limit: 5000, Offset: 0}, params)
+}
+
+// Test decoding PaginationParams recomputes the offset
+func TestPaginationParams_UnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected PaginationParams
+	}{
+		{name: "page wins over offset", input: `{"page": 3, "limit": 20, "offset": 7}`, expected: PaginationParams{Page: 3, Limit: 20, Offset: 40}},
+		{name: "offset derives page", input: `{"limit": 20, "offset": 45}`, expected: PaginationParams{Page: 3, Limit: 20, Offset: 40}},
+		{name: "defaults", input: `{}`, expected: PaginationParams{Page: 1, Limit: 10, Offset: 0}},
+		{name: "limit clamped", input: `{"page": 1, "limit": 1000000}`, expected: PaginationParams{Page: 1, Limit: MaxPageLimit, Offset: 0}},
+		{name: "huge offset", input: `{"limit": 1, "offset": 9223372036854775807}`, expected: PaginationParams{Page: math.MaxInt, Limit: 1, Offset: math.MaxInt - 1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var params PaginationParams
+			assert.NoError(t, json.Unmarshal([]byte(tt.input), &params))
+			assert.Equal(t, tt.expected, params)
+		})
+	}
+
+	var query QueryParams
+	assert.NoError(t, json.Unmarshal([]byte(`{"pagination": {"page": 2, "limit": 5, "offset": 0}}`), &query))
+	assert.Equal(t, PaginationParams{Page: 2, Limit: 5, Offset: 5}, query.Pagination)
+
+	var params PaginationParams
+	assert.Error(t, json.Unmarshal([]byte(`{"page": "two"}`), &params))
+}
+
+// Test the navigation fields of PaginationMeta
+func TestCalculatePaginationMeta_Navigation(t *testing.T) {
+	tests := []struct {
+		name     string
+		total    int
+		page     int
+		limit    int
+		expected PaginationMeta
+	}{
+		{
+			name:     "first page",
+			total:    23,
+			page:     1,
+			limit:    10,
+			expected: PaginationMeta{TotalItems: 23, TotalPage: 3, CurrentPage: 1, PageLimit: 10, HasNext: true, From: 1, To: 10, NextPage: 2},
+		},
+		{
+			name:     "middle page",
+			total:    23,
+			page:     2,
+			limit:    10,
+			expected: PaginationMeta{TotalItems: 23, TotalPage: 3, CurrentPage: 2, PageLimit: 10, HasNext: true, HasPrev: true, From: 11, To: 20, NextPage: 3, PrevPage: 1},
+		},
+		{
+			name:     "last page",
+			total:    23,
+			page:     3,
+			limit:    10,
+			expected: PaginationMeta{TotalItems: 23, TotalPage: 3, CurrentPage: 3, PageLimit: 10, HasPrev: true, From: 21, To: 23, PrevPage: 2},
+		},
+		{
+			name:     "past the last page",
+			total:    23,
+			page:     7,
+			limit:    10,
+			expected: PaginationMeta{TotalItems: 23, TotalPage: 3, CurrentPage: 7, PageLimit: 10, HasPrev: true, PrevPage: 3},
+		},
+		{
+			name:     "page overflowing the offset",
+			total:    23,
+			page:     math.MaxInt,
+			limit:    10,
+			expected: PaginationMeta{TotalItems: 23, TotalPage: 3, CurrentPage: math.MaxInt, PageLimit: 10, HasPrev: true, PrevPage: 3},
+		},
+		{
+			name:     "huge limit",
+			total:    23,
+			page:     1,
+			limit:    math.MaxInt,
+			expected: PaginationMeta{TotalItems: 23, TotalPage: 1, CurrentPage: 1, PageLimit: math.MaxInt, From: 1, To: 23},
+		},
+		{
+			name:     "zero limit does not panic",
+			total:    23,
+			page:     0,
+			limit:    0,
+			expected: PaginationMeta{TotalItems: 23, TotalPage: 3, CurrentPage: 1, PageLimit: 10, HasNext: true, From: 1, To: 10, NextPage: 2},
+		},
+		{
+			name:     "no records",
+			page:     1,
+			limit:    10,
+			expected: PaginationMeta{TotalPage: 1, CurrentPage: 1, PageLimit: 10},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, &tt.expected, CalculatePaginationMeta(tt.total, tt.page, tt.limit))
+		})
+	}
+
+	data, err := json.Marshal(CalculatePaginationMeta(5, 1, 10))
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"total_items": 5, "total_page": 1, "current_page": 1, "page_limit": 10, "has_next": false, "has_prev": false, "from": 1, "to": 5}`, string(data))
+}
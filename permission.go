@@ -0,0 +1,291 @@
+package sqlbuilder
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrForbidden is returned when the principal may not filter or sort on a field
+var ErrForbidden = errors.New("sqlbuilder: forbidden")
+
+// Principal is the caller whose roles are checked against field permissions
+type Principal interface {
+	HasRole(role string) bool
+}
+
+// Roles is a Principal holding a fixed set of roles
+type Roles []string
+
+// HasRole returns true if role is one of the roles
+func (r Roles) HasRole(role string) bool {
+	for _, held := range r {
+		if held == role {
+			return true
+		}
+	}
+	return false
+}
+
+// principalKey is the context key of the principal stored with WithPrincipal
+type principalKey struct{}
+
+// WithPrincipal returns a copy of ctx carrying the principal
+func WithPrincipal(ctx context.Context, principal Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, principal)
+}
+
+// PrincipalFromContext returns the principal stored with WithPrincipal, nil if there is none
+// A nil principal holds no roles, so it is denied every restricted field.
+func PrincipalFromContext(ctx context.Context) Principal {
+	principal, _ := ctx.Value(principalKey{}).(Principal)
+	return principal
+}
+
+// SetPrincipal sets the principal whose permissions are checked when query params are applied
+// Criteria and sorts the principal may not use are left out and set Err to an ErrForbidden error.
+// Once a schema field is guarded by roles, fields that are not registered are denied too.
+func (s *SQLBuilder) SetPrincipal(principal Principal) {
+	s.principal = principal
+}
+
+// Permits returns true if principal may use operator on the field
+// Roles guards every use of the field and OperatorRoles additionally guards single operators.
+func (spec FieldSpec) Permits(principal Principal, operator string) bool {
+	return hasAnyRole(principal, spec.Roles) && hasAnyRole(principal, spec.OperatorRoles[operator])
+}
+
+// PermitsSort returns true if principal may sort on the field
+func (spec FieldSpec) PermitsSort(principal Principal) bool {
+	return hasAnyRole(principal, spec.Roles)
+}
+
+// hasAnyRole returns true if roles is empty or principal holds one of them
+func hasAnyRole(principal Principal, roles []string) bool {
+	if len(roles) == 0 {
+		return true
+	}
+	if principal == nil {
+		return false
+	}
+	for _, role := range roles {
+		if principal.HasRole(role) {
+			return true
+		}
+	}
+	return false
+}
+
+// Authorize returns an ErrForbidden error if the principal in ctx may not use the query
+func (q *QueryParams) Authorize(ctx context.Context, schema *Schema) error {
+	principal := PrincipalFromContext(ctx)
+	if err := schema.authorizeSearch(principal, q.Search); err != nil {
+		return err
+	}
+	if err := schema.authorizeFilters(principal, q.Filters); err != nil {
+		return err
+	}
+	return schema.authorizeSort(principal, q.Sort)
+}
+
+// Authorize returns an ErrForbidden error if the principal in ctx may not use the query
+func (q *AdvancedQueryParams) Authorize(ctx context.Context, schema *Schema) error {
+	principal := PrincipalFromContext(ctx)
+	if err := schema.authorizeGroups(principal, q.SearchGroups); err != nil {
+		return err
+	}
+	if err := schema.authorizeFilters(principal, q.Filters); err != nil {
+		return err
+	}
+	return schema.authorizeSort(principal, q.Sort)
+}
+
+// permit records err on the builder and returns true if there is none
+func (s *SQLBuilder) permit(err error) bool {
+	if err != nil {
+		s.setErr(err)
+		return false
+	}
+	return true
+}
+
+// admit checks client criteria against the limits and then the permissions, recording the first failure
+// Limits go first, so an oversized query is rejected before authorization walks all of it.
+func (s *SQLBuilder) admit(limits func(checker *limitChecker) error, authorize func() error) bool {
+	return s.permit(s.checkLimits(limits)) && s.permit(authorize())
+}
+
+// authorizeSearch checks every field of the search criteria, including multi-field ones
+// Fields takes precedence over Field, as in SearchCriteria.Expr.
+func (sc *Schema) authorizeSearch(principal Principal, criteria []SearchCriteria) error {
+	for _, criterion := range criteria {
+		for _, field := range criterion.renderedFields() {
+			if err := sc.authorizeField(principal, field, criterion.Operator, criterion.Value); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// authorizeFilters checks every filter field
+func (sc *Schema) authorizeFilters(principal Principal, filters []FilterCriteria) error {
+	for _, filter := range filters {
+		if err := sc.authorizeField(principal, filter.Field, filter.Operator, filter.Value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// authorizeGroups checks the conditions of the groups and their nested groups
+func (sc *Schema) authorizeGroups(principal Principal, groups []LogicalGroup) error {
+	for _, group := range groups {
+		if err := sc.authorizeSearch(principal, group.Conditions); err != nil {
+			return err
+		}
+		if err := sc.authorizeGroups(principal, group.Groups); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// authorizeSort checks every sort field
+func (sc *Schema) authorizeSort(principal Principal, sort []SortCriteria) error {
+	for _, criterion := range sort {
+		if criterion.Field == SortRelevance {
+			continue
+		}
+		specs, ok := sc.permissionSpecs(criterion.Field, nil)
+		if !ok {
+			return fmt.Errorf("%w: sort on unknown field %s", ErrForbidden, criterion.Field)
+		}
+		for _, spec := range specs {
+			if !spec.PermitsSort(principal) {
+				return fmt.Errorf("%w: sort on %s", ErrForbidden, criterion.Field)
+			}
+		}
+		if spec, ok := sc.querySpec(criterion.Field); ok && spec.typed() && !spec.Sortable {
+			return fmt.Errorf("%w: %s is not sortable", ErrForbidden, criterion.Field)
+		}
+	}
+	return nil
+}
+
+// authorizeField checks operator on field, its JSON column and the members of a field group
+// The operator must also be one of the AllowedOperators of the spec registered for field.
+// The paths a JSON operator reads through its value, such as the keys of a json_contains
+// document or the key of json_has_key, are guarded by their path specs too.
+func (sc *Schema) authorizeField(principal Principal, field, operator string, value any) error {
+	for _, name := range append([]string{field}, jsonValueFields(field, operator, value)...) {
+		specs, ok := sc.permissionSpecs(name, nil)
+		if !ok {
+			return fmt.Errorf("%w: %s on unknown field %s", ErrForbidden, operator, name)
+		}
+		for _, spec := range specs {
+			if !spec.Permits(principal, operator) {
+				return fmt.Errorf("%w: %s on %s", ErrForbidden, operator, name)
+			}
+		}
+	}
+	if spec, ok := sc.querySpec(field); ok && spec.typed() && !spec.Allows(operator) {
+		return fmt.Errorf("%w: %s is not allowed on %s", ErrForbidden, operator, field)
+	}
+	return nil
+}
+
+// querySpec returns the spec registered for field, the spec of the path for a JSON path
+// A qualified field such as e.name uses the spec of its column, as in permissionSpecs.
+func (sc *Schema) querySpec(field string) (FieldSpec, bool) {
+	if sc == nil {
+		return FieldSpec{}, false
+	}
+	if _, ok := sc.FieldGroup(field); ok {
+		return sc.permissionField(field)
+	}
+	column, path := sc.permissionName(field)
+	if path != "" {
+		return sc.permissionField(column + "." + path)
+	}
+	return sc.permissionField(column)
+}
+
+// permissionSpecs appends the specs whose permissions apply to field
+// A field group is guarded by each of its members, a JSON path such as metadata.color by
+// the metadata column, and a table qualified field such as e.salary by its column. Names are
+// compared as the database compares identifiers, ignoring case and quotes. ok is false when
+// the field is not registered and the schema restricts some fields, as an unknown name may
+// still reach a restricted column.
+func (sc *Schema) permissionSpecs(field string, specs []FieldSpec) ([]FieldSpec, bool) {
+	if sc == nil {
+		return specs, true
+	}
+	if members, ok := sc.FieldGroup(field); ok {
+		if spec, ok := sc.permissionField(field); ok {
+			specs = append(specs, spec)
+		}
+		for _, member := range members {
+			var known bool
+			if specs, known = sc.columnSpecs(member, specs); !known {
+				return specs, false
+			}
+		}
+		return specs, true
+	}
+	return sc.columnSpecs(field, specs)
+}
+
+// columnSpecs appends the specs whose permissions apply to a column or JSON path
+func (sc *Schema) columnSpecs(field string, specs []FieldSpec) ([]FieldSpec, bool) {
+	column, path := sc.permissionName(field)
+	if path != "" {
+		if spec, ok := sc.permissionField(column + "." + path); ok {
+			specs = append(specs, spec)
+		}
+	}
+	spec, ok := sc.permissionField(column)
+	if !ok {
+		return specs, !sc.restricted()
+	}
+	return append(specs, spec), true
+}
+
+// permissionName returns the normalized column of field and its JSON path, if any
+func (sc *Schema) permissionName(field string) (column, path string) {
+	name := strings.ToLower(identifierQuotes.Replace(field))
+	parts := strings.Split(strings.ReplaceAll(name, "->", "."), ".")
+	for column := range sc.jsonColumns {
+		if len(parts) > 1 && strings.EqualFold(column, parts[0]) {
+			return parts[0], strings.Join(parts[1:], ".")
+		}
+	}
+	return parts[len(parts)-1], ""
+}
+
+// permissionField returns the spec of a registered field, comparing normalized names
+func (sc *Schema) permissionField(name string) (FieldSpec, bool) {
+	if spec, ok := sc.fields[name]; ok {
+		return spec, true
+	}
+	for registered, spec := range sc.fields {
+		if strings.ToLower(identifierQuotes.Replace(registered)) == name {
+			return spec, true
+		}
+	}
+	return FieldSpec{}, false
+}
+
+// restricted returns true if a field of the schema is guarded by roles
+func (sc *Schema) restricted() bool {
+	for _, spec := range sc.fields {
+		if len(spec.Roles) > 0 || len(spec.OperatorRoles) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// identifierQuotes removes the quoting and whitespace the database ignores in identifiers
+var identifierQuotes = strings.NewReplacer("`", "", `"`, "", "[", "", "]", "", " ", "", "\t", "", "\n", "")
@@ -0,0 +1,272 @@
+package sqlbuilder
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func permissionTestSchema() *Schema {
+	return NewSchema().
+		AddField("name", FieldSpec{Type: FieldString, Sortable: true}).
+		AddField("salary", FieldSpec{Type: FieldNumber, Sortable: true, Roles: []string{"admin", "hr"}}).
+		AddField("email", FieldSpec{Type: FieldString, OperatorRoles: map[string][]string{OpContains: {"admin"}}}).
+		AddField("metadata", FieldSpec{Type: FieldJSON, Roles: []string{"admin"}}).
+		AddField("age", FieldSpec{Type: FieldInteger, Operators: []string{OpEqual, OpGreaterThanEq}}).
+		AllowJSONPaths("metadata", "color").
+		AddField("profile", FieldSpec{Type: FieldJSON}).
+		AddField("profile.salary", FieldSpec{Roles: []string{"admin"}}).
+		AllowJSONPaths("profile", "city", "salary").
+		AddFieldGroup("contact", "name", "email")
+}
+
+// Test field permissions of QueryParams and AdvancedQueryParams
+func TestAuthorize(t *testing.T) {
+	admin := WithPrincipal(context.Background(), Roles{"admin"})
+	user := WithPrincipal(context.Background(), Roles{"user"})
+
+	tests := []struct {
+		name     string
+		ctx      context.Context
+		query    *QueryParams
+		groups   []LogicalGroup
+		expected string
+	}{
+		{
+			name:  "unrestricted field",
+			ctx:   user,
+			query: &QueryParams{Search: []SearchCriteria{CreateSearchCondition("name", OpContains, "ann")}},
+		},
+		{
+			name:     "restricted filter",
+			ctx:      user,
+			query:    &QueryParams{Filters: []FilterCriteria{CreateFilterCondition("salary", OpGreaterThan, 100000)}},
+			expected: "sqlbuilder: forbidden: gt on salary",
+		},
+		{
+			name:  "restricted filter with role",
+			ctx:   WithPrincipal(context.Background(), Roles{"hr"}),
+			query: &QueryParams{Filters: []FilterCriteria{CreateFilterCondition("salary", OpGreaterThan, 100000)}},
+		},
+		{
+			name:     "no principal",
+			ctx:      context.Background(),
+			query:    &QueryParams{Sort: []SortCriteria{{Field: "salary", Order: SortDesc}}},
+			expected: "sqlbuilder: forbidden: sort on salary",
+		},
+		{
+			name:  "unrestricted operator",
+			ctx:   user,
+			query: &QueryParams{Search: []SearchCriteria{CreateSearchCondition("email", OpEqual, "a@b.c")}},
+		},
+		{
+			name:     "restricted operator",
+			ctx:      user,
+			query:    &QueryParams{Search: []SearchCriteria{CreateSearchCondition("email", OpContains, "@corp")}},
+			expected: "sqlbuilder: forbidden: contains on email",
+		},
+		{
+			name:     "multi-field search",
+			ctx:      user,
+			query:    &QueryParams{Search: []SearchCriteria{CreateMultiFieldSearchCondition(OpContains, "x", false, "name", "email")}},
+			expected: "sqlbuilder: forbidden: contains on email",
+		},
+		{
+			name:     "field group",
+			ctx:      user,
+			query:    &QueryParams{Search: []SearchCriteria{CreateSearchCondition("contact", OpContains, "x")}},
+			expected: "sqlbuilder: forbidden: contains on contact",
+		},
+		{
+			name:     "json path",
+			ctx:      user,
+			query:    &QueryParams{Filters: []FilterCriteria{CreateFilterCondition("metadata.color", OpEqual, "red")}},
+			expected: "sqlbuilder: forbidden: eq on metadata.color",
+		},
+		{
+			name:     "json contains document key",
+			ctx:      user,
+			query:    &QueryParams{Filters: []FilterCriteria{CreateFilterCondition("profile", OpJSONContains, map[string]any{"salary": 100})}},
+			expected: "sqlbuilder: forbidden: json_contains on profile.salary",
+		},
+		{
+			name:     "json has key",
+			ctx:      user,
+			query:    &QueryParams{Search: []SearchCriteria{CreateSearchCondition("profile", OpJSONHasKey, "salary")}},
+			expected: "sqlbuilder: forbidden: json_has_key on profile.salary",
+		},
+		{
+			name:  "json contains unrestricted key",
+			ctx:   user,
+			query: &QueryParams{Filters: []FilterCriteria{CreateFilterCondition("profile", OpJSONContains, map[string]any{"city": "Berlin"})}},
+		},
+		{
+			name:  "json contains document key with role",
+			ctx:   admin,
+			query: &QueryParams{Filters: []FilterCriteria{CreateFilterCondition("profile", OpJSONContains, map[string]any{"salary": 100})}},
+		},
+		{
+			name:     "fields take precedence over field",
+			ctx:      user,
+			query:    &QueryParams{Search: []SearchCriteria{{Field: "name", Fields: []string{"salary"}, Operator: OpGreaterThan, Value: 1}}},
+			expected: "sqlbuilder: forbidden: gt on salary",
+		},
+		{
+			name:     "table qualified field",
+			ctx:      user,
+			query:    &QueryParams{Filters: []FilterCriteria{CreateFilterCondition("e.salary", OpGreaterThan, 1)}},
+			expected: "sqlbuilder: forbidden: gt on e.salary",
+		},
+		{
+			name:     "field case and quotes",
+			ctx:      user,
+			query:    &QueryParams{Filters: []FilterCriteria{CreateFilterCondition("SALARY", OpLessThan, 1), CreateFilterCondition("`Salary`", OpLessThan, 1)}},
+			expected: "sqlbuilder: forbidden: lt on SALARY",
+		},
+		{
+			name:     "unknown field",
+			ctx:      admin,
+			query:    &QueryParams{Filters: []FilterCriteria{CreateFilterCondition("password", OpEqual, "x")}},
+			expected: "sqlbuilder: forbidden: eq on unknown field password",
+		},
+		{
+			name:     "unknown sort field",
+			ctx:      admin,
+			query:    &QueryParams{Sort: []SortCriteria{{Field: "password"}}},
+			expected: "sqlbuilder: forbidden: sort on unknown field password",
+		},
+		{
+			name:     "operator not allowed",
+			ctx:      admin,
+			query:    &QueryParams{Filters: []FilterCriteria{CreateFilterCondition("e.Name", OpGreaterThan, "m")}},
+			expected: "sqlbuilder: forbidden: gt is not allowed on e.Name",
+		},
+		{
+			name:     "listed operators",
+			ctx:      admin,
+			query:    &QueryParams{Filters: []FilterCriteria{CreateFilterCondition("age", OpGreaterThan, 18)}},
+			expected: "sqlbuilder: forbidden: gt is not allowed on age",
+		},
+		{
+			name:  "listed operator",
+			ctx:   admin,
+			query: &QueryParams{Filters: []FilterCriteria{CreateFilterCondition("age", OpGreaterThanEq, 18)}},
+		},
+		{
+			name:     "sort not allowed",
+			ctx:      admin,
+			query:    &QueryParams{Sort: []SortCriteria{{Field: "email"}}},
+			expected: "sqlbuilder: forbidden: email is not sortable",
+		},
+		{
+			name:  "qualified unrestricted field",
+			ctx:   user,
+			query: &QueryParams{Search: []SearchCriteria{CreateSearchCondition("e.Name", OpEqual, "ann")}, Sort: []SortCriteria{{Field: SortRelevance}}},
+		},
+		{
+			name:  "admin",
+			ctx:   admin,
+			query: &QueryParams{Search: []SearchCriteria{CreateSearchCondition("contact", OpContains, "x")}, Sort: []SortCriteria{{Field: "salary"}}},
+		},
+		{
+			name: "nested group",
+			ctx:  user,
+			groups: []LogicalGroup{{
+				Operator:   LogicOr,
+				Conditions: []SearchCriteria{CreateSearchCondition("name", OpEqual, "a")},
+				Groups:     []LogicalGroup{CreateSearchGroup(LogicAnd, CreateSearchCondition("salary", OpLessThan, 10))},
+			}},
+			expected: "sqlbuilder: forbidden: lt on salary",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var err error
+			if tt.groups != nil {
+				err = (&AdvancedQueryParams{SearchGroups: tt.groups}).Authorize(tt.ctx, permissionTestSchema())
+			} else {
+				err = tt.query.Authorize(tt.ctx, permissionTestSchema())
+			}
+
+			if tt.expected == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorIs(t, err, ErrForbidden)
+			assert.EqualError(t, err, tt.expected)
+		})
+	}
+}
+
+// Test applying query params leaves forbidden parts out and sets Err
+func TestSQLBuilder_Permissions(t *testing.T) {
+	query := &QueryParams{
+		Search:  []SearchCriteria{CreateSearchCondition("name", OpEqual, "ann")},
+		Filters: []FilterCriteria{CreateFilterCondition("salary", OpGreaterThan, 100000)},
+		Sort:    []SortCriteria{{Field: "salary", Order: SortDesc}},
+	}
+
+	builder := NewSQLBuilder()
+	builder.SetSchema(permissionTestSchema())
+	builder.SetPrincipal(PrincipalFromContext(WithPrincipal(context.Background(), Roles{"user"})))
+	query.ApplySearch(builder)
+	query.ApplyFilters(builder)
+
+	assert.Equal(t, "WHERE (name = ?)", builder.GetWhereClause())
+	assert.Equal(t, "", query.ApplySort(builder))
+	assert.ErrorIs(t, builder.Err(), ErrForbidden)
+	assert.EqualError(t, builder.Err(), "sqlbuilder: forbidden: gt on salary")
+
+	builder = NewSQLBuilder()
+	builder.SetSchema(permissionTestSchema())
+	builder.SetPrincipal(Roles{"admin"})
+	query.ApplySearch(builder)
+	query.ApplyFilters(builder)
+
+	assert.Equal(t, "WHERE (name = ?) AND salary > ?", builder.GetWhereClause())
+	assert.Equal(t, "ORDER BY salary DESC", query.ApplySort(builder))
+	assert.NoError(t, builder.Err())
+
+	builder = NewSQLBuilder()
+	builder.SetSchema(permissionTestSchema())
+	builder.SetPrincipal(Roles{"admin"})
+	(&QueryParams{Filters: []FilterCriteria{CreateFilterCondition("age", OpLessThan, 18)}}).ApplyFilters(builder)
+
+	assert.Equal(t, "", builder.GetWhereClause())
+	assert.EqualError(t, builder.Err(), "sqlbuilder: forbidden: lt is not allowed on age")
+
+	advanced := &AdvancedQueryParams{SearchGroups: []LogicalGroup{CreateSearchGroup(LogicOr, CreateSearchCondition("metadata->color", OpEqual, "red"))}}
+	builder = NewSQLBuilder()
+	builder.SetSchema(permissionTestSchema())
+	advanced.ApplyAdvancedSearch(builder)
+
+	assert.Equal(t, "", builder.GetWhereClause())
+	assert.ErrorIs(t, builder.Err(), ErrForbidden)
+	assert.Nil(t, PrincipalFromContext(context.Background()))
+}
+
+// Test restricted columns cannot be reached through Fields, qualifiers or other spellings
+func TestSQLBuilder_PermissionBypass(t *testing.T) {
+	tests := []struct {
+		name   string
+		search []SearchCriteria
+	}{
+		{name: "fields", search: []SearchCriteria{{Field: "name", Fields: []string{"salary"}, Operator: OpGreaterThan, Value: 1}}},
+		{name: "qualified", search: []SearchCriteria{CreateSearchCondition("e.salary", OpGreaterThan, 1)}},
+		{name: "upper case", search: []SearchCriteria{CreateSearchCondition("SALARY", OpLessThan, 1)}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			builder := NewSQLBuilder()
+			builder.SetSchema(permissionTestSchema())
+			builder.SetPrincipal(Roles{"user"})
+			(&QueryParams{Search: tt.search}).ApplySearch(builder)
+
+			assert.Equal(t, "", builder.GetWhereClause())
+			assert.ErrorIs(t, builder.Err(), ErrForbidden)
+		})
+	}
+}
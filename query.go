@@ -1,15 +1,22 @@
 package sqlbuilder
 
 import (
+	"database/sql"
+	"errors"
 	"fmt"
+	"regexp"
 	"strings"
+	"time"
 )
 
 // SearchCriteria represents a single search criterion
+// Fields searches several columns at once, Tokenize requires every word of the value to match at least one field
 type SearchCriteria struct {
-	Field    string `json:"field"`
-	Operator string `json:"operator"`
-	Value    any    `json:"value"`
+	Field    string   `json:"field"`
+	Operator string   `json:"operator"`
+	Value    any      `json:"value"`
+	Fields   []string `json:"fields,omitempty"`
+	Tokenize bool     `json:"tokenize,omitempty"`
 }
 
 // FilterCriteria represents a single filter criterion
@@ -21,8 +28,9 @@ type FilterCriteria struct {
 
 // SortCriteria represents sorting parameters
 type SortCriteria struct {
-	Field string `json:"field"`
-	Order string `json:"order"` // ASC or DESC
+	Field string    `json:"field"`
+	Order string    `json:"order"`          // ASC or DESC
+	Near  *GeoPoint `json:"near,omitempty"` // Sorts a location field by distance to this point
 }
 
 // PaginationParams represents pagination parameters
@@ -35,6 +43,7 @@ type PaginationParams struct {
 // QueryParams represents comprehensive query parameters
 type QueryParams struct {
 	Search     []SearchCriteria `json:"search"`
+	SearchMode string           `json:"search_mode,omitempty"` // SearchModeAny or SearchModeAll, the builder mode when empty
 	Filters    []FilterCriteria `json:"filters"`
 	Sort       []SortCriteria   `json:"sort"`
 	Pagination PaginationParams `json:"pagination"`
@@ -45,6 +54,7 @@ type LogicalGroup struct {
 	Operator   string           `json:"operator"` // AND or OR
 	Conditions []SearchCriteria `json:"conditions"`
 	Groups     []LogicalGroup   `json:"groups"` // Nested groups for complex logic
+	Not        bool             `json:"not"`    // Negates the whole group
 }
 
 // AdvancedQueryParams supports complex AND/OR logic
@@ -75,45 +85,108 @@ const (
 	OpNotIn         = "not_in"
 	OpIsNull        = "is_null"
 	OpIsNotNull     = "is_not_null"
-	OpFullText      = "full_text"
-	OpRegex         = "regex"  // Regular expression matching
-	OpIRegex        = "iregex" // Case-insensitive regex
+	OpFullText      = "full_text" // Natural language mode, see fulltext.go for other modes
+	OpRegex         = "regex"     // Regular expression matching
+	OpIRegex        = "iregex"    // Case-insensitive regex
+
+	// Negated string operators
+	OpNotContains    = "not_contains"
+	OpNotIContains   = "not_icontains"
+	OpNotStartsWith  = "not_starts_with"
+	OpNotIStartsWith = "not_istarts_with"
+	OpNotEndsWith    = "not_ends_with"
+	OpNotIEndsWith   = "not_iends_with"
+	OpNotLike        = "not_like"
+	OpNotILike       = "not_ilike"
+	OpNotRegex       = "not_regex"
+	OpNotIRegex      = "not_iregex"
 )
 
+// operators lists every search operator understood by the builder
+var operators = []string{
+	OpEqual, OpNotEqual, OpGreaterThan, OpGreaterThanEq, OpLessThan, OpLessThanEq,
+	OpContains, OpIContains, OpStartsWith, OpIStartsWith, OpEndsWith, OpIEndsWith,
+	OpLike, OpILike, OpIn, OpNotIn, OpIsNull, OpIsNotNull, OpFullText, OpRegex, OpIRegex,
+	OpNotContains, OpNotIContains, OpNotStartsWith, OpNotIStartsWith, OpNotEndsWith,
+	OpNotIEndsWith, OpNotLike, OpNotILike, OpNotRegex, OpNotIRegex,
+	OpFullTextBoolean, OpFullTextExpansion, OpJSONContains, OpJSONHasKey,
+	OpArrayContains, OpArrayContainedBy, OpArrayOverlaps, OpArrayLength,
+	OpWithinRadius, OpWithinBBox, OpWithinLast, OpOnDate, OpInPeriod, OpBeforePeriod, OpAfterPeriod,
+}
+
+// Operators returns every supported search operator
+func Operators() []string {
+	return append([]string{}, operators...)
+}
+
+// IsOperator returns true if operator is a supported search operator
+func IsOperator(operator string) bool {
+	for _, known := range operators {
+		if known == operator {
+			return true
+		}
+	}
+	return false
+}
+
 // Logical operators
 const (
 	LogicAnd = "AND"
 	LogicOr  = "OR"
 )
 
+// Search modes control how the criteria of QueryParams.Search are combined
+const (
+	SearchModeAny = "any" // Match any criterion (OR), the default
+	SearchModeAll = "all" // Match every criterion (AND)
+)
+
 // Sort orders
 const (
 	SortAsc  = "asc"
 	SortDesc = "desc"
 )
 
+// ParamStyle controls how the builder renders placeholders and collects parameters
+type ParamStyle int
+
+// Parameter styles
+const (
+	ParamPositional ParamStyle = iota // ? placeholders, values from GetParams
+	ParamNamed                        // :name placeholders, values from GetNamedParams/GetNamedArgs
+)
+
+// ErrParamNameConflict is returned when a named parameter is already bound on the builder
+var ErrParamNameConflict = errors.New("sqlbuilder: named parameter already bound")
+
+// ErrInvalidGroupOperator is returned for logical groups whose operator is not AND or OR
+var ErrInvalidGroupOperator = errors.New("sqlbuilder: invalid group operator")
+
+// ErrInvalidField is returned for criterion and sort fields that are not identifiers
+var ErrInvalidField = errors.New("sqlbuilder: invalid field")
+
+// ErrInvalidValue is returned when a criterion value has the wrong type for its operator
+var ErrInvalidValue = errors.New("sqlbuilder: invalid value")
+
 // PaginationMeta represents pagination metadata
 type PaginationMeta struct {
-	TotalItems  int `json:"total_items"`
-	TotalPage   int `json:"total_page"`
-	CurrentPage int `json:"current_page"`
-	PageLimit   int `json:"page_limit"`
+	TotalItems  int  `json:"total_items"`
+	TotalPage   int  `json:"total_page"`
+	CurrentPage int  `json:"current_page"`
+	PageLimit   int  `json:"page_limit"`
+	HasNext     bool `json:"has_next"`
+	HasPrev     bool `json:"has_prev"`
+	From        int  `json:"from"`                // 1-based index of the first item on the page, 0 if the page is empty
+	To          int  `json:"to"`                  // 1-based index of the last item on the page, 0 if the page is empty
+	NextPage    int  `json:"next_page,omitempty"` // 0 if there is no next page
+	PrevPage    int  `json:"prev_page,omitempty"` // 0 if there is no previous page
 }
 
 // NewPaginationParams creates pagination parameters with defaults
+// Limits above MaxPageLimit are clamped, use a PaginationPolicy for other bounds.
 func NewPaginationParams(page, limit int) PaginationParams {
-	if page <= 0 {
-		page = 1
-	}
-	if limit <= 0 {
-		limit = 10
-	}
-
-	return PaginationParams{
-		Page:   page,
-		Limit:  limit,
-		Offset: (page - 1) * limit,
-	}
+	params, _ := DefaultPaginationPolicy().Apply(PaginationParams{Page: page, Limit: limit})
+	return params
 }
 
 // NewQueryParams creates a new QueryParams instance
@@ -183,25 +256,41 @@ func (q *QueryParams) HasSort() bool {
 }
 
 // ApplySearch applies search conditions to the SQL builder
+// SearchMode overrides the builder search mode when set. An unknown mode sets Err and
+// adds 1 = 0, as the criteria cannot be combined as the client intended.
 func (q *QueryParams) ApplySearch(builder *SQLBuilder) {
-	if q.HasSearch() {
-		searchConditions := builder.BuildSearchConditions(q.Search)
-		builder.AddWhereCondition(searchConditions)
+	if !q.HasSearch() {
+		return
+	}
+	mode := builder.searchMode
+	if q.SearchMode != "" {
+		mode = q.SearchMode
+	}
+	operator, err := searchModeOperator(mode)
+	if err != nil {
+		builder.setErr(err)
+		builder.AddWhereCondition(scopeNever)
+		return
+	}
+	if builder.admit(func(c *limitChecker) error { return c.search(q.Search) },
+		func() error { return builder.schema.authorizeSearch(builder.principal, q.Search) }) {
+		builder.AddWhereCondition(builder.renderSearch(q.Search, operator))
 	}
 }
 
 // ApplyFilters applies filter conditions to the SQL builder
 func (q *QueryParams) ApplyFilters(builder *SQLBuilder) {
-	if q.HasFilters() {
-		filterConditions := builder.BuildFilterConditions(q.Filters)
-		builder.AddWhereCondition(filterConditions)
+	if q.HasFilters() && builder.admit(func(c *limitChecker) error { return c.filters(q.Filters) },
+		func() error { return builder.schema.authorizeFilters(builder.principal, q.Filters) }) {
+		builder.AddWhereCondition(builder.renderFilters(q.Filters))
 	}
 }
 
 // ApplySort applies sort conditions to the SQL builder and returns the ORDER BY clause
 func (q *QueryParams) ApplySort(builder *SQLBuilder, includePrefix ...bool) string {
-	if q.HasSort() {
-		return builder.BuildOrderBy(q.Sort, includePrefix...)
+	if q.HasSort() && builder.admit(func(c *limitChecker) error { return c.sort(q.Sort) },
+		func() error { return builder.schema.authorizeSort(builder.principal, q.Sort) }) {
+		return builder.renderOrderBy(q.Sort, includePrefix...)
 	}
 	return ""
 }
@@ -243,24 +332,25 @@ func (q *AdvancedQueryParams) HasSort() bool {
 
 // ApplyAdvancedSearch applies advanced search conditions to the SQL builder
 func (q *AdvancedQueryParams) ApplyAdvancedSearch(builder *SQLBuilder) {
-	if q.HasSearchGroups() {
-		searchConditions := builder.BuildAdvancedSearchConditions(q.SearchGroups)
-		builder.AddWhereCondition(searchConditions)
+	if q.HasSearchGroups() && builder.admit(func(c *limitChecker) error { return c.groups(q.SearchGroups, 1) },
+		func() error { return builder.schema.authorizeGroups(builder.principal, q.SearchGroups) }) {
+		builder.AddWhereCondition(builder.renderGroups(q.SearchGroups))
 	}
 }
 
 // ApplyFilters applies filter conditions to the SQL builder
 func (q *AdvancedQueryParams) ApplyFilters(builder *SQLBuilder) {
-	if q.HasFilters() {
-		filterConditions := builder.BuildFilterConditions(q.Filters)
-		builder.AddWhereCondition(filterConditions)
+	if q.HasFilters() && builder.admit(func(c *limitChecker) error { return c.filters(q.Filters) },
+		func() error { return builder.schema.authorizeFilters(builder.principal, q.Filters) }) {
+		builder.AddWhereCondition(builder.renderFilters(q.Filters))
 	}
 }
 
 // ApplySort applies sort conditions to the SQL builder and returns the ORDER BY clause
 func (q *AdvancedQueryParams) ApplySort(builder *SQLBuilder, includePrefix ...bool) string {
-	if q.HasSort() {
-		return builder.BuildOrderBy(q.Sort, includePrefix...)
+	if q.HasSort() && builder.admit(func(c *limitChecker) error { return c.sort(q.Sort) },
+		func() error { return builder.schema.authorizeSort(builder.principal, q.Sort) }) {
+		return builder.renderOrderBy(q.Sort, includePrefix...)
 	}
 	return ""
 }
@@ -275,6 +365,27 @@ type SQLBuilder struct {
 	whereConditions []string
 	params          []any
 	paramIndex      int
+	paramStyle      ParamStyle
+	namedParams     map[string]any
+	namedOrder      []string
+	nameCounters    map[string]int
+	dialect         Dialect
+	regexLimits     *RegexLimits
+	relevance       *relevanceScore
+	schema          *Schema
+	now             func() time.Time
+	location        *time.Location
+	whereCombinator string
+	searchMode      string
+	scopes          []Scope
+	scopeConditions []string
+	scopesApplied   bool
+	scopeErr        error
+	trashed         trashedMode
+	principal       Principal
+	queryLimits     *QueryLimits
+	limitConditions int
+	err             error
 }
 
 // NewSQLBuilder creates a new SQL builder
@@ -283,39 +394,109 @@ func NewSQLBuilder() *SQLBuilder {
 		whereConditions: make([]string, 0),
 		params:          make([]any, 0),
 		paramIndex:      0,
+		paramStyle:      ParamPositional,
+		namedParams:     make(map[string]any),
+		namedOrder:      make([]string, 0),
+		nameCounters:    make(map[string]int),
+		dialect:         DialectMySQL,
+		whereCombinator: LogicAnd,
+		searchMode:      SearchModeAny,
+	}
+}
+
+// NewNamedSQLBuilder creates a new SQL builder that emits :name placeholders
+func NewNamedSQLBuilder() *SQLBuilder {
+	builder := NewSQLBuilder()
+	builder.SetParamStyle(ParamNamed)
+	return builder
+}
+
+// SetParamStyle sets the placeholder style used for conditions built afterwards
+func (s *SQLBuilder) SetParamStyle(style ParamStyle) {
+	s.paramStyle = style
+}
+
+// SetWhereCombinator sets how GetWhereClause joins the added conditions, LogicAnd by default
+// With LogicOr every condition is parenthesized. Any other operator sets Err.
+func (s *SQLBuilder) SetWhereCombinator(operator string) {
+	combinator, err := groupOperator(operator)
+	if err != nil {
+		s.setErr(err)
+		return
 	}
+	s.whereCombinator = combinator
 }
 
-// BuildSearchConditions builds WHERE conditions for search (OR logic)
+// SetSearchMode sets how BuildSearchConditions combines criteria, SearchModeAny by default
+// Any other mode sets Err.
+func (s *SQLBuilder) SetSearchMode(mode string) {
+	if _, err := searchModeOperator(mode); err != nil {
+		s.setErr(err)
+		return
+	}
+	s.searchMode = mode
+}
+
+// BuildSearchConditions builds WHERE conditions for search
+// Criteria are ORed, or ANDed when the search mode is SearchModeAll
 func (s *SQLBuilder) BuildSearchConditions(search []SearchCriteria) string {
+	return s.buildSearch(search, s.searchMode)
+}
+
+// buildSearch combines search criteria according to mode
+func (s *SQLBuilder) buildSearch(search []SearchCriteria, mode string) string {
 	if len(search) == 0 {
 		return ""
 	}
 
-	var conditions []string
-	for _, criterion := range search {
-		condition := s.buildCondition(criterion.Field, criterion.Operator, criterion.Value)
-		if condition != "" {
-			conditions = append(conditions, condition)
-		}
+	operator, err := searchModeOperator(mode)
+	if err != nil {
+		s.setErr(err)
+		return scopeNever
 	}
-
-	if len(conditions) == 0 {
+	if !s.permit(s.checkLimits(func(c *limitChecker) error { return c.search(search) })) {
 		return ""
 	}
+	return s.renderSearch(search, operator)
+}
+
+// renderSearch combines search criteria with operator, after their limits were checked
+func (s *SQLBuilder) renderSearch(search []SearchCriteria, operator string) string {
+	exprs := make([]Expr, len(search))
+	for i, criterion := range search {
+		exprs[i] = criterion.Expr()
+	}
+
+	if operator == LogicAnd {
+		return s.Render(And(exprs...))
+	}
+	return s.Render(Or(exprs...))
+}
 
-	return "(" + strings.Join(conditions, " OR ") + ")"
+// searchModeOperator returns the logical operator of a search mode, an empty mode means any
+func searchModeOperator(mode string) (string, error) {
+	switch mode {
+	case "", SearchModeAny:
+		return LogicOr, nil
+	case SearchModeAll:
+		return LogicAnd, nil
+	}
+	return "", fmt.Errorf("%w: unknown search mode %q", ErrInvalidValue, mode)
 }
 
 // BuildFilterConditions builds WHERE conditions for filters (AND logic)
 func (s *SQLBuilder) BuildFilterConditions(filters []FilterCriteria) string {
-	if len(filters) == 0 {
+	if len(filters) == 0 || !s.permit(s.checkLimits(func(c *limitChecker) error { return c.filters(filters) })) {
 		return ""
 	}
+	return s.renderFilters(filters)
+}
 
+// renderFilters ANDs the filter conditions, after their limits were checked
+func (s *SQLBuilder) renderFilters(filters []FilterCriteria) string {
 	var conditions []string
 	for _, filter := range filters {
-		condition := s.buildCondition(filter.Field, filter.Operator, filter.Value)
+		condition := s.Render(filter.Expr())
 		if condition != "" {
 			conditions = append(conditions, condition)
 		}
@@ -330,13 +511,17 @@ func (s *SQLBuilder) BuildFilterConditions(filters []FilterCriteria) string {
 
 // BuildAdvancedSearchConditions builds complex search conditions with AND/OR logic
 func (s *SQLBuilder) BuildAdvancedSearchConditions(groups []LogicalGroup) string {
-	if len(groups) == 0 {
+	if len(groups) == 0 || !s.permit(s.checkLimits(func(c *limitChecker) error { return c.groups(groups, 1) })) {
 		return ""
 	}
+	return s.renderGroups(groups)
+}
 
+// renderGroups ANDs the search groups, after their limits were checked
+func (s *SQLBuilder) renderGroups(groups []LogicalGroup) string {
 	var groupConditions []string
 	for _, group := range groups {
-		groupCondition := s.buildLogicalGroup(group)
+		groupCondition := s.Render(group.Expr())
 		if groupCondition != "" {
 			groupConditions = append(groupConditions, groupCondition)
 		}
@@ -349,48 +534,47 @@ func (s *SQLBuilder) BuildAdvancedSearchConditions(groups []LogicalGroup) string
 	return strings.Join(groupConditions, " AND ")
 }
 
-// buildLogicalGroup builds conditions for a logical group
-func (s *SQLBuilder) buildLogicalGroup(group LogicalGroup) string {
-	var conditions []string
-
-	// Add direct conditions
-	for _, criterion := range group.Conditions {
-		condition := s.buildCondition(criterion.Field, criterion.Operator, criterion.Value)
-		if condition != "" {
-			conditions = append(conditions, condition)
-		}
-	}
-
-	// Add nested groups
-	for _, nestedGroup := range group.Groups {
-		nestedCondition := s.buildLogicalGroup(nestedGroup)
-		if nestedCondition != "" {
-			conditions = append(conditions, "("+nestedCondition+")")
-		}
-	}
-
-	if len(conditions) == 0 {
-		return ""
-	}
-
-	operator := " " + strings.ToUpper(group.Operator) + " "
-	return "(" + strings.Join(conditions, operator) + ")"
-}
-
 // BuildOrderBy builds ORDER BY clause
 // If includePrefix is false, returns the order clauses without "ORDER BY" prefix
+// Distance sorts bind parameters, so build the ORDER BY after the WHERE conditions
 func (s *SQLBuilder) BuildOrderBy(sort []SortCriteria, includePrefix ...bool) string {
-	if len(sort) == 0 {
+	if len(sort) == 0 || !s.permit(s.checkLimits(func(c *limitChecker) error { return c.sort(sort) })) {
 		return ""
 	}
+	return s.renderOrderBy(sort, includePrefix...)
+}
 
+// renderOrderBy builds the ORDER BY clause, after the number of sort keys was checked
+func (s *SQLBuilder) renderOrderBy(sort []SortCriteria, includePrefix ...bool) string {
 	var orderByClauses []string
 	for _, criterion := range sort {
 		order := "ASC"
 		if strings.ToLower(criterion.Order) == "desc" {
 			order = "DESC"
 		}
-		orderByClauses = append(orderByClauses, fmt.Sprintf("%s %s", criterion.Field, order))
+		if criterion.Field == SortRelevance {
+			// Sorting by relevance needs a full-text condition, and defaults to best match first
+			if s.relevance == nil {
+				continue
+			}
+			if criterion.Order == "" {
+				order = "DESC"
+			}
+		}
+		field := criterion.Field
+		if field != SortRelevance && !s.permit(s.checkField(field, "")) {
+			continue
+		}
+		if criterion.Near != nil {
+			if field = s.buildDistanceOrder(criterion.Field, *criterion.Near); field == "" {
+				continue
+			}
+		}
+		orderByClauses = append(orderByClauses, fmt.Sprintf("%s %s", field, order))
+	}
+
+	if len(orderByClauses) == 0 {
+		return ""
 	}
 
 	orderClause := strings.Join(orderByClauses, ", ")
@@ -407,20 +591,111 @@ func (s *SQLBuilder) BuildOrderBy(sort []SortCriteria, includePrefix ...bool) st
 	return orderClause
 }
 
+// checkField returns an ErrInvalidField error unless field is a column, optionally table
+// qualified, or a JSON path in dotted or arrow form. Full-text operators take a comma
+// separated list of columns. Segments may be quoted as the dialect quotes identifiers.
+func (s *SQLBuilder) checkField(field, operator string) error {
+	columns := []string{field}
+	switch operator {
+	case OpFullText, OpFullTextBoolean, OpFullTextExpansion:
+		columns = fullTextColumns(field)
+	}
+	pattern := mysqlFieldPattern
+	if s.dialect == DialectPostgres {
+		pattern = postgresFieldPattern
+	}
+	for _, column := range columns {
+		if !pattern.MatchString(column) {
+			return fmt.Errorf("%w: %q", ErrInvalidField, field)
+		}
+	}
+	if len(columns) == 0 {
+		return fmt.Errorf("%w: %q", ErrInvalidField, field)
+	}
+	return nil
+}
+
+// Field patterns, a plain segment is a letter or underscore followed by letters, digits, _ or $
+var (
+	mysqlFieldPattern    = fieldPattern("`[^`]+`")
+	postgresFieldPattern = fieldPattern(`"[^"]+"`)
+)
+
+// fieldPattern matches segments joined by . or ->, plain or quoted as quoted matches
+func fieldPattern(quoted string) *regexp.Regexp {
+	segment := `(?:[\p{L}_][\p{L}\p{N}_$]*|` + quoted + `)`
+	return regexp.MustCompile(`^` + segment + `(?:(?:\.|->)` + segment + `)*$`)
+}
+
 // GetParams returns the accumulated parameters
 func (s *SQLBuilder) GetParams() []any {
 	return s.params
 }
 
+// GetNamedParams returns the accumulated named parameters keyed by name (without the colon)
+func (s *SQLBuilder) GetNamedParams() map[string]any {
+	return s.namedParams
+}
+
+// GetNamedArgs returns the named parameters as sql.NamedArg values in binding order
+// The result can be passed straight to database/sql query methods
+func (s *SQLBuilder) GetNamedArgs() []any {
+	args := make([]any, 0, len(s.namedOrder))
+	for _, name := range s.namedOrder {
+		args = append(args, sql.Named(name, s.namedParams[name]))
+	}
+	return args
+}
+
+// MergeNamedParams adds caller-supplied named parameters to the builder
+// Names generated afterwards never reuse a merged name. Merging a name that is
+// already bound returns ErrParamNameConflict and leaves the builder unchanged.
+func (s *SQLBuilder) MergeNamedParams(params map[string]any) error {
+	for name := range params {
+		if _, exists := s.namedParams[name]; exists {
+			return fmt.Errorf("%w: %s", ErrParamNameConflict, name)
+		}
+	}
+	for name, value := range params {
+		s.namedParams[name] = value
+		s.namedOrder = append(s.namedOrder, name)
+	}
+	return nil
+}
+
+// Err returns the first error encountered while building conditions, such as an invalid group operator
+// Invalid parts are left out of the generated SQL, so the query must not be executed when Err is non-nil
+func (s *SQLBuilder) Err() error {
+	return s.err
+}
+
+// setErr records err unless an earlier error is already recorded
+func (s *SQLBuilder) setErr(err error) {
+	if s.err == nil {
+		s.err = err
+	}
+}
+
 // GetWhereClause returns the complete WHERE clause
 // If includePrefix is false, returns the conditions without "WHERE" prefix
+// Scope and soft-delete predicates are ANDed in front of all other conditions.
 func (s *SQLBuilder) GetWhereClause(includePrefix ...bool) string {
-	if len(s.whereConditions) == 0 {
+	conditions := strings.Join(s.whereConditions, " AND ")
+	if s.whereCombinator == LogicOr && len(s.whereConditions) > 1 {
+		wrapped := make([]string, len(s.whereConditions))
+		for i, condition := range s.whereConditions {
+			wrapped[i] = condition
+			if !isParenthesized(condition) {
+				wrapped[i] = "(" + condition + ")"
+			}
+		}
+		conditions = strings.Join(wrapped, " OR ")
+	}
+	conditions = s.mandatoryWhere(conditions)
+	if conditions == "" {
 		return ""
 	}
 
-	conditions := strings.Join(s.whereConditions, " AND ")
-
 	// Default to including prefix if not specified
 	shouldIncludePrefix := true
 	if len(includePrefix) > 0 {
@@ -433,6 +708,56 @@ func (s *SQLBuilder) GetWhereClause(includePrefix ...bool) string {
 	return conditions
 }
 
+// mandatoryWhere ANDs the scope and soft-delete predicates with the parenthesized conditions
+func (s *SQLBuilder) mandatoryWhere(conditions string) string {
+	scopes, ok := s.scopePredicates()
+	if !ok {
+		return scopeNever
+	}
+
+	parts := append([]string{}, scopes...)
+	if condition := s.softDeleteCondition(); condition != "" {
+		parts = append(parts, condition)
+	}
+	if len(parts) == 0 {
+		return conditions
+	}
+	if conditions != "" {
+		if !isParenthesized(conditions) {
+			conditions = "(" + conditions + ")"
+		}
+		parts = append(parts, conditions)
+	}
+	return strings.Join(parts, " AND ")
+}
+
+// isParenthesized returns true if the whole condition is enclosed in one pair of parentheses
+func isParenthesized(condition string) bool {
+	if !strings.HasPrefix(condition, "(") || !strings.HasSuffix(condition, ")") {
+		return false
+	}
+	depth := 0
+	var quote rune
+	for i, r := range condition {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '\'' || r == '"' || r == '`':
+			quote = r
+		case r == '(':
+			depth++
+		case r == ')':
+			depth--
+			if depth == 0 && i < len(condition)-1 {
+				return false
+			}
+		}
+	}
+	return depth == 0
+}
+
 // AddWhereCondition adds a condition to the WHERE clause
 func (s *SQLBuilder) AddWhereCondition(condition string) {
 	if condition != "" {
@@ -440,104 +765,198 @@ func (s *SQLBuilder) AddWhereCondition(condition string) {
 	}
 }
 
+// AddWhere adds a hand-written condition whose ? placeholders are bound to args
+// The placeholders join the builder sequence ($n for PostgreSQL, :p_raw_n for named parameters),
+// so custom and generated conditions can be mixed in any order. ? inside quoted literals is
+// left alone and ?? writes a literal ?. The condition is parenthesized so an OR inside it
+// cannot leak into the surrounding AND. A placeholder count that does not match args sets Err
+// and adds 1 = 0 instead, so the query matches nothing rather than more than intended.
+func (s *SQLBuilder) AddWhere(sql string, args ...any) {
+	if condition := s.Render(Raw(sql, args...)); condition != "" {
+		s.AddWhereCondition("(" + condition + ")")
+	}
+}
+
+// bind records a parameter value for field and returns its placeholder
+func (s *SQLBuilder) bind(field string, value any) string {
+	if s.paramStyle == ParamNamed {
+		name := s.nextParamName(field)
+		s.namedParams[name] = value
+		s.namedOrder = append(s.namedOrder, name)
+		return ":" + name
+	}
+	s.params = append(s.params, value)
+	return s.positionalPlaceholder()
+}
+
+// bindList binds every value for field and returns the comma separated placeholders
+func (s *SQLBuilder) bindList(field string, values []any) string {
+	placeholders := make([]string, len(values))
+	for i, v := range values {
+		placeholders[i] = s.bind(field, v)
+	}
+	return strings.Join(placeholders, ", ")
+}
+
+// nextParamName derives a unique parameter name such as p_status_1 from a field name
+// Counters live on the builder, so names stay unique across nested groups and repeated builds
+func (s *SQLBuilder) nextParamName(field string) string {
+	base := "p_" + paramNameBase(field)
+	for {
+		s.nameCounters[base]++
+		name := fmt.Sprintf("%s_%d", base, s.nameCounters[base])
+		if _, exists := s.namedParams[name]; !exists {
+			return name
+		}
+	}
+}
+
+// paramNameBase reduces a field expression to a lowercase identifier usable in a placeholder
+func paramNameBase(field string) string {
+	var b strings.Builder
+	lastUnderscore := false
+	for _, r := range strings.ToLower(field) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			b.WriteRune(r)
+			lastUnderscore = false
+		} else if !lastUnderscore && b.Len() > 0 {
+			b.WriteByte('_')
+			lastUnderscore = true
+		}
+	}
+	base := strings.TrimSuffix(b.String(), "_")
+	if base == "" {
+		return "param"
+	}
+	return base
+}
+
 // buildCondition builds a single condition and adds parameters
 // Uses ILIKE for better search flexibility by default, but supports both LIKE and ILIKE
 func (s *SQLBuilder) buildCondition(field, operator string, value any) string {
+	if operator == OpJSONContains || operator == OpJSONHasKey {
+		return s.buildJSONCondition(field, operator, value)
+	}
+
+	column, ok := s.resolveField(field, numericComparison(operator, value))
+	if !ok {
+		return ""
+	}
+
 	switch operator {
 	case OpEqual:
-		s.params = append(s.params, value)
-		return fmt.Sprintf("%s = ?", field)
+		return fmt.Sprintf("%s = %s", column, s.bind(field, value))
 	case OpNotEqual:
-		s.params = append(s.params, value)
-		return fmt.Sprintf("%s != ?", field)
+		return fmt.Sprintf("%s != %s", column, s.bind(field, value))
 	case OpGreaterThan:
-		s.params = append(s.params, value)
-		return fmt.Sprintf("%s > ?", field)
+		return fmt.Sprintf("%s > %s", column, s.bind(field, value))
 	case OpGreaterThanEq:
-		s.params = append(s.params, value)
-		return fmt.Sprintf("%s >= ?", field)
+		return fmt.Sprintf("%s >= %s", column, s.bind(field, value))
 	case OpLessThan:
-		s.params = append(s.params, value)
-		return fmt.Sprintf("%s < ?", field)
+		return fmt.Sprintf("%s < %s", column, s.bind(field, value))
 	case OpLessThanEq:
-		s.params = append(s.params, value)
-		return fmt.Sprintf("%s <= ?", field)
+		return fmt.Sprintf("%s <= %s", column, s.bind(field, value))
 	case OpContains:
-		s.params = append(s.params, fmt.Sprintf("%%%v%%", value))
-		return fmt.Sprintf("%s LIKE ?", field)
+		return fmt.Sprintf("%s LIKE %s", column, s.bind(field, wildcardPattern("%", value, "%")))
 	case OpIContains:
-		s.params = append(s.params, fmt.Sprintf("%%%v%%", value))
-		return fmt.Sprintf("LOWER(%s) LIKE LOWER(?)", field)
+		return fmt.Sprintf("LOWER(%s) LIKE LOWER(%s)", column, s.bind(field, wildcardPattern("%", value, "%")))
 	case OpStartsWith:
-		s.params = append(s.params, fmt.Sprintf("%v%%", value))
-		return fmt.Sprintf("%s LIKE ?", field)
+		return fmt.Sprintf("%s LIKE %s", column, s.bind(field, wildcardPattern("", value, "%")))
 	case OpIStartsWith:
-		s.params = append(s.params, fmt.Sprintf("%v%%", value))
-		return fmt.Sprintf("LOWER(%s) LIKE LOWER(?)", field)
+		return fmt.Sprintf("LOWER(%s) LIKE LOWER(%s)", column, s.bind(field, wildcardPattern("", value, "%")))
 	case OpEndsWith:
-		s.params = append(s.params, fmt.Sprintf("%%%v", value))
-		return fmt.Sprintf("%s LIKE ?", field)
+		return fmt.Sprintf("%s LIKE %s", column, s.bind(field, wildcardPattern("%", value, "")))
 	case OpIEndsWith:
-		s.params = append(s.params, fmt.Sprintf("%%%v", value))
-		return fmt.Sprintf("LOWER(%s) LIKE LOWER(?)", field)
+		return fmt.Sprintf("LOWER(%s) LIKE LOWER(%s)", column, s.bind(field, wildcardPattern("%", value, "")))
 	case OpLike:
-		s.params = append(s.params, value)
-		return fmt.Sprintf("%s LIKE ?", field)
+		return fmt.Sprintf("%s LIKE %s", column, s.bind(field, value))
 	case OpILike:
-		s.params = append(s.params, value)
-		return fmt.Sprintf("LOWER(%s) LIKE LOWER(?)", field)
-	case OpFullText:
-		s.params = append(s.params, value)
-		return fmt.Sprintf("MATCH(%s) AGAINST(? IN NATURAL LANGUAGE MODE)", field)
-	case OpRegex:
-		s.params = append(s.params, value)
-		return fmt.Sprintf("%s REGEXP ?", field)
-	case OpIRegex:
-		s.params = append(s.params, value)
-		return fmt.Sprintf("%s REGEXP ?", field) // MySQL regex is case-insensitive by default
+		return fmt.Sprintf("LOWER(%s) LIKE LOWER(%s)", column, s.bind(field, value))
+	case OpFullText, OpFullTextBoolean, OpFullTextExpansion:
+		return s.buildFullTextModeCondition(column, operator, value)
+	case OpRegex, OpIRegex, OpNotRegex, OpNotIRegex:
+		return s.buildRegexCondition(column, operator, value)
+	case OpNotContains:
+		return fmt.Sprintf("%s NOT LIKE %s", column, s.bind(field, wildcardPattern("%", value, "%")))
+	case OpNotIContains:
+		return fmt.Sprintf("LOWER(%s) NOT LIKE LOWER(%s)", column, s.bind(field, wildcardPattern("%", value, "%")))
+	case OpNotStartsWith:
+		return fmt.Sprintf("%s NOT LIKE %s", column, s.bind(field, wildcardPattern("", value, "%")))
+	case OpNotIStartsWith:
+		return fmt.Sprintf("LOWER(%s) NOT LIKE LOWER(%s)", column, s.bind(field, wildcardPattern("", value, "%")))
+	case OpNotEndsWith:
+		return fmt.Sprintf("%s NOT LIKE %s", column, s.bind(field, wildcardPattern("%", value, "")))
+	case OpNotIEndsWith:
+		return fmt.Sprintf("LOWER(%s) NOT LIKE LOWER(%s)", column, s.bind(field, wildcardPattern("%", value, "")))
+	case OpNotLike:
+		return fmt.Sprintf("%s NOT LIKE %s", column, s.bind(field, value))
+	case OpNotILike:
+		return fmt.Sprintf("LOWER(%s) NOT LIKE LOWER(%s)", column, s.bind(field, value))
+	case OpArrayContains, OpArrayContainedBy, OpArrayOverlaps, OpArrayLength:
+		return s.buildArrayCondition(column, field, operator, value)
+	case OpWithinLast, OpOnDate, OpInPeriod, OpBeforePeriod, OpAfterPeriod:
+		return s.buildDateCondition(column, field, operator, value)
+	case OpWithinRadius, OpWithinBBox:
+		return s.buildGeoCondition(column, field, operator, value)
 	case OpIsNull:
-		return fmt.Sprintf("%s IS NULL", field)
+		return fmt.Sprintf("%s IS NULL", column)
 	case OpIsNotNull:
-		return fmt.Sprintf("%s IS NOT NULL", field)
+		return fmt.Sprintf("%s IS NOT NULL", column)
 	case OpIn:
-		if values, ok := value.([]any); ok && len(values) > 0 {
-			placeholders := make([]string, len(values))
-			for i, v := range values {
-				placeholders[i] = "?"
-				s.params = append(s.params, v)
-			}
-			return fmt.Sprintf("%s IN (%s)", field, strings.Join(placeholders, ", "))
+		if values, ok := listValues(value); ok && len(values) > 0 {
+			return fmt.Sprintf("%s IN (%s)", column, s.bindList(field, values))
 		}
 	case OpNotIn:
-		if values, ok := value.([]any); ok && len(values) > 0 {
-			placeholders := make([]string, len(values))
-			for i, v := range values {
-				placeholders[i] = "?"
-				s.params = append(s.params, v)
-			}
-			return fmt.Sprintf("%s NOT IN (%s)", field, strings.Join(placeholders, ", "))
+		if values, ok := listValues(value); ok && len(values) > 0 {
+			return fmt.Sprintf("%s NOT IN (%s)", column, s.bindList(field, values))
 		}
 	}
 	return ""
 }
 
+// wildcardPattern wraps value in the % wildcards of a LIKE operator
+// A nil value stays nil, so the pattern is NULL and the condition UNKNOWN, as in Evaluate.
+func wildcardPattern(prefix string, value any, suffix string) any {
+	if value == nil {
+		return nil
+	}
+	return prefix + fmt.Sprint(value) + suffix
+}
+
 // CalculatePaginationMeta calculates pagination metadata
-// Formula explanation: totalPages = (totalRecords + limit - 1) / limit
-// This formula ensures we round up to the nearest integer:
-// - If totalRecords is exactly divisible by limit, we get the exact number of pages
-// - If there's a remainder, we get one extra page to accommodate the remaining records
-// Example: 23 records with 10 per page = (23 + 10 - 1) / 10 = 32 / 10 = 3 pages
+// The page count rounds up: totalRecords / limit, plus one page when there is a remainder,
+// e.g. 23 records with 10 per page make 3 pages. Dividing first means huge limits cannot
+// overflow. An empty result still has one page. From and To are left zero for pages past
+// the last one. Pages and limits of zero or less fall back to the NewPaginationParams defaults.
 func CalculatePaginationMeta(totalRecords int, page, limit int) *PaginationMeta {
-	totalPages := (totalRecords + limit - 1) / limit
-	if totalPages == 0 {
-		totalPages = 1
+	if page <= 0 {
+		page = 1
+	}
+	if limit <= 0 {
+		limit = DefaultPageLimit
 	}
-	return &PaginationMeta{
+	totalPages := max(1, pageCount(totalRecords, limit))
+
+	meta := &PaginationMeta{
 		TotalItems:  totalRecords,
 		TotalPage:   totalPages,
 		CurrentPage: page,
 		PageLimit:   limit,
+		HasNext:     page < totalPages,
+		HasPrev:     page > 1,
+	}
+	if page <= pageCount(totalRecords, limit) {
+		offset := (page - 1) * limit
+		meta.From = offset + 1
+		meta.To = offset + min(limit, totalRecords-offset)
+	}
+	if meta.HasNext {
+		meta.NextPage = page + 1
 	}
+	if meta.HasPrev {
+		meta.PrevPage = min(page-1, totalPages)
+	}
+	return meta
 }
 
 // Helper functions for creating search groups
@@ -560,6 +979,16 @@ func CreateSearchCondition(field, operator string, value any) SearchCriteria {
 	}
 }
 
+// CreateMultiFieldSearchCondition creates a search criterion matching the value against any of the fields
+func CreateMultiFieldSearchCondition(operator string, value any, tokenize bool, fields ...string) SearchCriteria {
+	return SearchCriteria{
+		Operator: operator,
+		Value:    value,
+		Fields:   fields,
+		Tokenize: tokenize,
+	}
+}
+
 // CreateFilterCondition creates a filter criterion
 func CreateFilterCondition(field, operator string, value any) FilterCriteria {
 	return FilterCriteria{
@@ -1,6 +1,8 @@
 package sqlbuilder
 
 import (
+	"database/sql"
+	"encoding/json"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -64,6 +66,14 @@ func TestNewPaginationParams(t *testing.T) {
 			expectedLimit:  10,
 			expectedOffset: 0,
 		},
+		{
+			name:           "limit above max is clamped",
+			page:           3,
+			limit:          1000000,
+			expectedPage:   3,
+			expectedLimit:  MaxPageLimit,
+			expectedOffset: 2 * MaxPageLimit,
+		},
 	}
 
 	for _, tt := range tests {
@@ -497,6 +507,145 @@ func TestSQLBuilder_WhereClause(t *testing.T) {
 	assert.Equal(t, expectedWithoutPrefix, builder.GetWhereClause(false))
 }
 
+// Test SQLBuilder SetWhereCombinator
+func TestSQLBuilder_WhereCombinator(t *testing.T) {
+	filters := []FilterCriteria{
+		CreateFilterCondition("status", OpEqual, "active"),
+		CreateFilterCondition("verified", OpEqual, true),
+	}
+
+	builder := NewSQLBuilder()
+	builder.SetWhereCombinator("or")
+	builder.AddWhereCondition(builder.BuildSearchConditions([]SearchCriteria{CreateSearchCondition("name", OpContains, "go")}))
+	builder.AddWhereCondition(builder.BuildFilterConditions(filters))
+
+	assert.NoError(t, builder.Err())
+	assert.Equal(t, "WHERE (name LIKE ?) OR (status = ? AND verified = ?)", builder.GetWhereClause())
+
+	single := NewSQLBuilder()
+	single.SetWhereCombinator(LogicOr)
+	single.AddWhereCondition("a = ?")
+	assert.Equal(t, "a = ?", single.GetWhereClause(false))
+	single.AddWhereCondition("(b = ?) AND (c = ')')")
+	assert.Equal(t, "(a = ?) OR ((b = ?) AND (c = ')'))", single.GetWhereClause(false))
+
+	invalid := NewSQLBuilder()
+	invalid.SetWhereCombinator("XOR")
+	invalid.AddWhereCondition("a = ?")
+	invalid.AddWhereCondition("b = ?")
+	assert.ErrorIs(t, invalid.Err(), ErrInvalidGroupOperator)
+	assert.Equal(t, "a = ? AND b = ?", invalid.GetWhereClause(false))
+}
+
+// Test search modes on the builder and QueryParams
+func TestSQLBuilder_SearchMode(t *testing.T) {
+	search := []SearchCriteria{
+		CreateSearchCondition("title", OpIContains, "go"),
+		CreateSearchCondition("body", OpIContains, "sql"),
+	}
+
+	builder := NewSQLBuilder()
+	assert.Equal(t, "(LOWER(title) LIKE LOWER(?) OR LOWER(body) LIKE LOWER(?))", builder.BuildSearchConditions(search))
+
+	builder = NewSQLBuilder()
+	builder.SetSearchMode(SearchModeAll)
+	assert.Equal(t, "(LOWER(title) LIKE LOWER(?) AND LOWER(body) LIKE LOWER(?))", builder.BuildSearchConditions(search))
+
+	builder.SetSearchMode("some")
+	assert.ErrorIs(t, builder.Err(), ErrInvalidValue)
+
+	var params QueryParams
+	assert.NoError(t, json.Unmarshal([]byte(`{"search": [{"field": "a", "operator": "eq", "value": 1}, {"field": "b", "operator": "eq", "value": 2}], "search_mode": "all"}`), &params))
+	assert.Equal(t, SearchModeAll, params.SearchMode)
+
+	builder = NewSQLBuilder()
+	params.ApplySearch(builder)
+	assert.Equal(t, "WHERE (a = ? AND b = ?)", builder.GetWhereClause())
+
+	params.SearchMode = ""
+	builder = NewSQLBuilder()
+	builder.SetSearchMode(SearchModeAll)
+	params.ApplySearch(builder)
+	assert.Equal(t, "WHERE (a = ? AND b = ?)", builder.GetWhereClause())
+
+	data, err := json.Marshal(NewQueryParams())
+	assert.NoError(t, err)
+	assert.NotContains(t, string(data), "search_mode")
+
+	params.SearchMode = "every"
+	builder = NewSQLBuilder()
+	params.ApplySearch(builder)
+	params.ApplyFilters(builder)
+	assert.ErrorIs(t, builder.Err(), ErrInvalidValue)
+	assert.Equal(t, "WHERE 1 = 0", builder.GetWhereClause())
+	assert.Empty(t, builder.GetParams())
+}
+
+// Test SQLBuilder AddWhere
+func TestSQLBuilder_AddWhere(t *testing.T) {
+	tests := []struct {
+		name           string
+		dialect        Dialect
+		style          ParamStyle
+		expectedSQL    string
+		expectedParams []any
+		expectedNamed  map[string]any
+	}{
+		{
+			name:           "mysql",
+			dialect:        DialectMySQL,
+			expectedSQL:    "WHERE status = ? AND (tenant_id = ? OR owner_id = ?) AND (data ? 'key' AND note != '?') AND name = ?",
+			expectedParams: []any{"active", 7, 8, "bob"},
+		},
+		{
+			name:           "postgres renumbers placeholders",
+			dialect:        DialectPostgres,
+			expectedSQL:    "WHERE status = $1 AND (tenant_id = $2 OR owner_id = $3) AND (data ? 'key' AND note != '?') AND name = $4",
+			expectedParams: []any{"active", 7, 8, "bob"},
+		},
+		{
+			name:          "named",
+			dialect:       DialectPostgres,
+			style:         ParamNamed,
+			expectedSQL:   "WHERE status = :p_status_1 AND (tenant_id = :p_raw_1 OR owner_id = :p_raw_2) AND (data ? 'key' AND note != '?') AND name = :p_name_1",
+			expectedNamed: map[string]any{"p_status_1": "active", "p_raw_1": 7, "p_raw_2": 8, "p_name_1": "bob"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			builder := NewSQLBuilder()
+			builder.SetDialect(tt.dialect)
+			builder.SetParamStyle(tt.style)
+
+			builder.AddWhereCondition(builder.BuildFilterConditions([]FilterCriteria{CreateFilterCondition("status", OpEqual, "active")}))
+			builder.AddWhere("tenant_id = ? OR owner_id = ?", 7, 8)
+			builder.AddWhere("data ?? 'key' AND note != '?'")
+			builder.AddWhereCondition(builder.BuildFilterConditions([]FilterCriteria{CreateFilterCondition("name", OpEqual, "bob")}))
+
+			assert.NoError(t, builder.Err())
+			assert.Equal(t, tt.expectedSQL, builder.GetWhereClause())
+			if tt.style == ParamNamed {
+				assert.Equal(t, tt.expectedNamed, builder.GetNamedParams())
+			} else {
+				assert.Equal(t, tt.expectedParams, builder.GetParams())
+			}
+		})
+	}
+
+	t.Run("placeholder count mismatch", func(t *testing.T) {
+		builder := NewSQLBuilder()
+		builder.AddWhere("tenant_id = ?", 7)
+		builder.AddWhere("a = ? AND b = ?", 1)
+		builder.AddWhere("c = ?", 1, 2)
+
+		assert.ErrorIs(t, builder.Err(), ErrInvalidValue)
+		assert.EqualError(t, builder.Err(), "sqlbuilder: invalid value: raw condition has 2 placeholders for 1 args")
+		assert.Equal(t, "WHERE (tenant_id = ?) AND (1 = 0) AND (1 = 0)", builder.GetWhereClause())
+		assert.Equal(t, []any{7}, builder.GetParams())
+	})
+}
+
 // Test all buildCondition operators
 func TestSQLBuilder_buildCondition(t *testing.T) {
 	tests := []struct {
@@ -640,7 +789,7 @@ func TestSQLBuilder_buildCondition(t *testing.T) {
 			field:          "title",
 			operator:       OpIRegex,
 			value:          "^test.*",
-			expectedSQL:    "title REGEXP ?",
+			expectedSQL:    "REGEXP_LIKE(title, ?, 'i')",
 			expectedParams: []any{"^test.*"},
 		},
 		{
@@ -675,6 +824,86 @@ func TestSQLBuilder_buildCondition(t *testing.T) {
 			expectedSQL:    "status NOT IN (?, ?)",
 			expectedParams: []any{"active", "inactive"},
 		},
+		{
+			name:           "OpNotContains",
+			field:          "title",
+			operator:       OpNotContains,
+			value:          "test",
+			expectedSQL:    "title NOT LIKE ?",
+			expectedParams: []any{"%test%"},
+		},
+		{
+			name:           "OpNotIContains",
+			field:          "title",
+			operator:       OpNotIContains,
+			value:          "test",
+			expectedSQL:    "LOWER(title) NOT LIKE LOWER(?)",
+			expectedParams: []any{"%test%"},
+		},
+		{
+			name:           "OpNotStartsWith",
+			field:          "title",
+			operator:       OpNotStartsWith,
+			value:          "test",
+			expectedSQL:    "title NOT LIKE ?",
+			expectedParams: []any{"test%"},
+		},
+		{
+			name:           "OpNotIStartsWith",
+			field:          "title",
+			operator:       OpNotIStartsWith,
+			value:          "test",
+			expectedSQL:    "LOWER(title) NOT LIKE LOWER(?)",
+			expectedParams: []any{"test%"},
+		},
+		{
+			name:           "OpNotEndsWith",
+			field:          "title",
+			operator:       OpNotEndsWith,
+			value:          "test",
+			expectedSQL:    "title NOT LIKE ?",
+			expectedParams: []any{"%test"},
+		},
+		{
+			name:           "OpNotIEndsWith",
+			field:          "title",
+			operator:       OpNotIEndsWith,
+			value:          "test",
+			expectedSQL:    "LOWER(title) NOT LIKE LOWER(?)",
+			expectedParams: []any{"%test"},
+		},
+		{
+			name:           "OpNotLike",
+			field:          "title",
+			operator:       OpNotLike,
+			value:          "te_t%",
+			expectedSQL:    "title NOT LIKE ?",
+			expectedParams: []any{"te_t%"},
+		},
+		{
+			name:           "OpNotILike",
+			field:          "title",
+			operator:       OpNotILike,
+			value:          "te_t%",
+			expectedSQL:    "LOWER(title) NOT LIKE LOWER(?)",
+			expectedParams: []any{"te_t%"},
+		},
+		{
+			name:           "OpNotRegex",
+			field:          "title",
+			operator:       OpNotRegex,
+			value:          "^test",
+			expectedSQL:    "title NOT REGEXP ?",
+			expectedParams: []any{"^test"},
+		},
+		{
+			name:           "OpNotIRegex",
+			field:          "title",
+			operator:       OpNotIRegex,
+			value:          "^test",
+			expectedSQL:    "NOT REGEXP_LIKE(title, ?, 'i')",
+			expectedParams: []any{"^test"},
+		},
 		{
 			name:           "OpIn empty array",
 			field:          "status",
@@ -691,6 +920,22 @@ func TestSQLBuilder_buildCondition(t *testing.T) {
 			expectedSQL:    "",
 			expectedParams: []any{},
 		},
+		{
+			name:           "OpNotIContains NULL pattern",
+			field:          "title",
+			operator:       OpNotIContains,
+			value:          nil,
+			expectedSQL:    "LOWER(title) NOT LIKE LOWER(?)",
+			expectedParams: []any{nil},
+		},
+		{
+			name:           "OpStartsWith NULL pattern",
+			field:          "title",
+			operator:       OpStartsWith,
+			value:          nil,
+			expectedSQL:    "title LIKE ?",
+			expectedParams: []any{nil},
+		},
 		{
 			name:           "OpIn invalid type",
 			field:          "status",
@@ -1098,6 +1343,90 @@ func TestSQLBuilder_EdgeCases(t *testing.T) {
 	})
 }
 
+// Test named parameter output mode
+func TestSQLBuilder_NamedParams(t *testing.T) {
+	t.Run("named placeholders across nested groups", func(t *testing.T) {
+		builder := NewNamedSQLBuilder()
+
+		groups := []LogicalGroup{
+			{
+				Operator: LogicAnd,
+				Conditions: []SearchCriteria{
+					{Field: "status", Operator: OpEqual, Value: "active"},
+				},
+				Groups: []LogicalGroup{
+					{
+						Operator: LogicOr,
+						Conditions: []SearchCriteria{
+							{Field: "status", Operator: OpEqual, Value: "pending"},
+							{Field: "u.name", Operator: OpIContains, Value: "john"},
+						},
+					},
+				},
+			},
+		}
+
+		result := builder.BuildAdvancedSearchConditions(groups)
+		assert.Equal(t, "(status = :p_status_1 AND ((status = :p_status_2 OR LOWER(u.name) LIKE LOWER(:p_u_name_1))))", result)
+		assert.Equal(t, map[string]any{
+			"p_status_1": "active",
+			"p_status_2": "pending",
+			"p_u_name_1": "%john%",
+		}, builder.GetNamedParams())
+		assert.Equal(t, []any{
+			sql.Named("p_status_1", "active"),
+			sql.Named("p_status_2", "pending"),
+			sql.Named("p_u_name_1", "%john%"),
+		}, builder.GetNamedArgs())
+		assert.Equal(t, 0, len(builder.GetParams()))
+	})
+
+	t.Run("IN list gets one name per value", func(t *testing.T) {
+		builder := NewNamedSQLBuilder()
+		result := builder.BuildFilterConditions([]FilterCriteria{
+			{Field: "category", Operator: OpIn, Value: []any{"a", "b"}},
+		})
+		assert.Equal(t, "category IN (:p_category_1, :p_category_2)", result)
+		assert.Equal(t, map[string]any{"p_category_1": "a", "p_category_2": "b"}, builder.GetNamedParams())
+	})
+
+	t.Run("merged params are never reused", func(t *testing.T) {
+		builder := NewNamedSQLBuilder()
+		err := builder.MergeNamedParams(map[string]any{"p_status_1": "mine", "tenant": 7})
+		assert.NoError(t, err)
+
+		result := builder.BuildFilterConditions([]FilterCriteria{
+			{Field: "status", Operator: OpEqual, Value: "active"},
+		})
+		assert.Equal(t, "status = :p_status_2", result)
+		assert.Equal(t, "mine", builder.GetNamedParams()["p_status_1"])
+		assert.Equal(t, "active", builder.GetNamedParams()["p_status_2"])
+		assert.Equal(t, 7, builder.GetNamedParams()["tenant"])
+	})
+
+	t.Run("merging a bound name fails", func(t *testing.T) {
+		builder := NewNamedSQLBuilder()
+		builder.BuildFilterConditions([]FilterCriteria{
+			{Field: "status", Operator: OpEqual, Value: "active"},
+		})
+
+		err := builder.MergeNamedParams(map[string]any{"p_status_1": "other", "extra": 1})
+		assert.ErrorIs(t, err, ErrParamNameConflict)
+		assert.Equal(t, "active", builder.GetNamedParams()["p_status_1"])
+		assert.NotContains(t, builder.GetNamedParams(), "extra")
+	})
+
+	t.Run("positional mode is unchanged", func(t *testing.T) {
+		builder := NewSQLBuilder()
+		result := builder.BuildFilterConditions([]FilterCriteria{
+			{Field: "status", Operator: OpEqual, Value: "active"},
+		})
+		assert.Equal(t, "status = ?", result)
+		assert.Equal(t, []any{"active"}, builder.GetParams())
+		assert.Equal(t, 0, len(builder.GetNamedParams()))
+	})
+}
+
 // Benchmark tests
 func BenchmarkSQLBuilder_BuildSearchConditions(b *testing.B) {
 	search := []SearchCriteria{
@@ -0,0 +1,216 @@
+package sqlbuilder
+
+import "sort"
+
+// FieldType is the value type of a schema field
+type FieldType string
+
+// Field types
+const (
+	FieldString   FieldType = "string"
+	FieldInteger  FieldType = "integer"
+	FieldNumber   FieldType = "number"
+	FieldBoolean  FieldType = "boolean"
+	FieldDateTime FieldType = "date-time"
+	FieldArray    FieldType = "array" // PostgreSQL array or MySQL JSON array
+	FieldJSON     FieldType = "json"  // JSON/JSONB document
+	FieldGeo      FieldType = "geo"   // POINT or PostGIS geometry/geography
+)
+
+// FieldSpec describes a field clients can query
+// Operators and Sortable are enforced with the roles when the spec has a Type or Operators,
+// a spec with neither only guards the field by roles.
+type FieldSpec struct {
+	Type        FieldType
+	Operators   []string // Allowed operators, DefaultOperators(Type) when empty
+	Sortable    bool     // Sorting on the field is allowed
+	Description string
+
+	Roles         []string            // Roles allowed to filter or sort on the field, anyone when empty
+	OperatorRoles map[string][]string // Roles allowed to use an operator, checked in addition to Roles
+}
+
+// Schema describes what clients are allowed to query
+type Schema struct {
+	jsonColumns      map[string]map[string]bool
+	fieldGroups      map[string][]string
+	fields           map[string]FieldSpec
+	defaultLimit     int
+	maxLimit         int
+	softDeleteColumn string
+}
+
+// NewSchema creates an empty schema
+func NewSchema() *Schema {
+	return &Schema{
+		jsonColumns:  make(map[string]map[string]bool),
+		fieldGroups:  make(map[string][]string),
+		fields:       make(map[string]FieldSpec),
+		defaultLimit: 10,
+	}
+}
+
+// DefaultOperators returns the operators that make sense for a field type
+func DefaultOperators(fieldType FieldType) []string {
+	nullable := []string{OpIsNull, OpIsNotNull}
+	ordered := []string{OpEqual, OpNotEqual, OpGreaterThan, OpGreaterThanEq, OpLessThan, OpLessThanEq, OpIn, OpNotIn}
+
+	switch fieldType {
+	case FieldString:
+		return append([]string{
+			OpEqual, OpNotEqual, OpIn, OpNotIn,
+			OpContains, OpIContains, OpStartsWith, OpIStartsWith, OpEndsWith, OpIEndsWith, OpLike, OpILike,
+			OpNotContains, OpNotIContains, OpNotStartsWith, OpNotIStartsWith, OpNotEndsWith, OpNotIEndsWith,
+			OpNotLike, OpNotILike,
+		}, nullable...)
+	case FieldInteger, FieldNumber:
+		return append(ordered, nullable...)
+	case FieldBoolean:
+		return append([]string{OpEqual, OpNotEqual}, nullable...)
+	case FieldDateTime:
+		return append(append(ordered, OpWithinLast, OpOnDate, OpInPeriod, OpBeforePeriod, OpAfterPeriod), nullable...)
+	case FieldArray:
+		return append([]string{OpArrayContains, OpArrayContainedBy, OpArrayOverlaps, OpArrayLength}, nullable...)
+	case FieldJSON:
+		return append([]string{OpJSONContains, OpJSONHasKey}, nullable...)
+	case FieldGeo:
+		return append([]string{OpWithinRadius, OpWithinBBox}, nullable...)
+	}
+	return nullable
+}
+
+// AllowedOperators returns the operators allowed on the field
+func (spec FieldSpec) AllowedOperators() []string {
+	if len(spec.Operators) > 0 {
+		return append([]string{}, spec.Operators...)
+	}
+	return DefaultOperators(spec.Type)
+}
+
+// Allows returns true if operator is allowed on the field
+func (spec FieldSpec) Allows(operator string) bool {
+	for _, allowed := range spec.AllowedOperators() {
+		if allowed == operator {
+			return true
+		}
+	}
+	return false
+}
+
+// typed returns true if the spec describes the operators and sorting allowed on the field
+func (spec FieldSpec) typed() bool {
+	return spec.Type != "" || len(spec.Operators) > 0
+}
+
+// AddField registers a field clients can query
+func (sc *Schema) AddField(name string, spec FieldSpec) *Schema {
+	spec.Operators = append([]string{}, spec.Operators...)
+	spec.Roles = append([]string{}, spec.Roles...)
+	if spec.OperatorRoles != nil {
+		operatorRoles := make(map[string][]string, len(spec.OperatorRoles))
+		for operator, roles := range spec.OperatorRoles {
+			operatorRoles[operator] = append([]string{}, roles...)
+		}
+		spec.OperatorRoles = operatorRoles
+	}
+	sc.fields[name] = spec
+	return sc
+}
+
+// Field returns the spec of a registered field
+func (sc *Schema) Field(name string) (FieldSpec, bool) {
+	if sc == nil {
+		return FieldSpec{}, false
+	}
+	spec, ok := sc.fields[name]
+	return spec, ok
+}
+
+// FieldNames returns the registered fields in sorted order
+func (sc *Schema) FieldNames() []string {
+	if sc == nil {
+		return nil
+	}
+	names := make([]string, 0, len(sc.fields))
+	for name := range sc.fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// SetPaginationLimits sets the default and maximum page size, a maximum of 0 means unlimited
+func (sc *Schema) SetPaginationLimits(defaultLimit, maxLimit int) *Schema {
+	sc.defaultLimit = defaultLimit
+	sc.maxLimit = maxLimit
+	return sc
+}
+
+// PaginationLimits returns the default and maximum page size
+func (sc *Schema) PaginationLimits() (defaultLimit, maxLimit int) {
+	if sc == nil {
+		return 10, 0
+	}
+	return sc.defaultLimit, sc.maxLimit
+}
+
+// AllowJSONPaths declares column as a JSON/JSONB column and allows the given dotted paths
+// Only allowed paths can be filtered on, e.g. AllowJSONPaths("metadata", "color", "size.width")
+// allows the fields "metadata.color", "metadata->color" and "metadata.size.width".
+func (sc *Schema) AllowJSONPaths(column string, paths ...string) *Schema {
+	allowed, ok := sc.jsonColumns[column]
+	if !ok {
+		allowed = make(map[string]bool)
+		sc.jsonColumns[column] = allowed
+	}
+	for _, path := range paths {
+		allowed[path] = true
+	}
+	return sc
+}
+
+// IsJSONColumn returns true if column was declared with AllowJSONPaths
+func (sc *Schema) IsJSONColumn(column string) bool {
+	if sc == nil {
+		return false
+	}
+	_, ok := sc.jsonColumns[column]
+	return ok
+}
+
+// AddFieldGroup registers a name that expands to several fields in search criteria
+// A criterion on the group, e.g. {"field": "contact", "operator": "icontains"}, matches
+// when any of the fields matches. Group names take precedence over columns of the same name.
+// Groups expand one level: members are always columns, so a group may list a column of its
+// own name, e.g. AddFieldGroup("name", "name", "title"), and never expands to other groups.
+func (sc *Schema) AddFieldGroup(name string, fields ...string) *Schema {
+	sc.fieldGroups[name] = append([]string{}, fields...)
+	return sc
+}
+
+// FieldGroup returns the fields of a registered group
+func (sc *Schema) FieldGroup(name string) ([]string, bool) {
+	if sc == nil {
+		return nil, false
+	}
+	fields, ok := sc.fieldGroups[name]
+	return fields, ok
+}
+
+// expandFields returns the columns criteria on fields render to, field groups replaced by their members
+func (sc *Schema) expandFields(fields []string) []string {
+	expanded := make([]string, 0, len(fields))
+	for _, field := range fields {
+		if members, ok := sc.FieldGroup(field); ok {
+			expanded = append(expanded, members...)
+			continue
+		}
+		expanded = append(expanded, field)
+	}
+	return expanded
+}
+
+// SetSchema sets the schema used to resolve and validate fields
+func (s *SQLBuilder) SetSchema(schema *Schema) {
+	s.schema = schema
+}
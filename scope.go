@@ -0,0 +1,127 @@
+package sqlbuilder
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ErrMissingScope is returned when a mandatory scope cannot be resolved from the context
+var ErrMissingScope = errors.New("sqlbuilder: missing required scope")
+
+// ErrScopeOrder is returned when scopes are applied twice or after other parameters were bound
+var ErrScopeOrder = errors.New("sqlbuilder: scopes must be applied once, before any other condition")
+
+// scopeNever is the condition rendered in place of scopes that could not be applied
+const scopeNever = "1 = 0"
+
+// Scope produces a mandatory predicate, such as tenant_id = ?, from the request context
+// A nil Expr means the context is not restricted by this scope. An error fails the build.
+type Scope interface {
+	Predicate(ctx context.Context) (Expr, error)
+}
+
+// ScopeFunc adapts a function to the Scope interface
+type ScopeFunc func(ctx context.Context) (Expr, error)
+
+// Predicate calls f(ctx)
+func (f ScopeFunc) Predicate(ctx context.Context) (Expr, error) {
+	return f(ctx)
+}
+
+// scopeValueKey is the context key of a value stored with WithScopeValue
+type scopeValueKey string
+
+// WithScopeValue returns a copy of ctx carrying a scope value, such as the caller's tenant id
+func WithScopeValue(ctx context.Context, name string, value any) context.Context {
+	return context.WithValue(ctx, scopeValueKey(name), value)
+}
+
+// ScopeValue returns the scope value stored under name, false if it is missing or nil
+func ScopeValue(ctx context.Context, name string) (any, bool) {
+	value := ctx.Value(scopeValueKey(name))
+	return value, value != nil
+}
+
+// RequireValue returns a scope restricting column to the context value stored under name
+// A []any value restricts column to the listed values. A missing value or an empty list
+// fails with ErrMissingScope instead of leaving the query unrestricted.
+func RequireValue(column, name string) Scope {
+	return ScopeFunc(func(ctx context.Context) (Expr, error) {
+		value, ok := ScopeValue(ctx, name)
+		if !ok {
+			return nil, fmt.Errorf("%w: %s", ErrMissingScope, name)
+		}
+		if values, ok := value.([]any); ok {
+			if len(values) == 0 {
+				return nil, fmt.Errorf("%w: %s is empty", ErrMissingScope, name)
+			}
+			return In(column, values...), nil
+		}
+		return Cmp(column, OpEqual, value), nil
+	})
+}
+
+// SetScopes registers the scopes every WHERE clause of the builder must satisfy
+// Once scopes are registered, GetWhereClause renders a condition matching no rows and sets
+// Err until ApplyScopes succeeds, so forgetting to scope a query fails closed.
+func (s *SQLBuilder) SetScopes(scopes ...Scope) {
+	s.scopes = append(s.scopes, scopes...)
+}
+
+// ApplyScopes resolves the registered scopes from ctx and binds their predicates
+// It must be called once, after the dialect and parameter style are set and before any other
+// condition is bound, so scope placeholders come first. The predicates are ANDed outside all
+// other conditions, whatever the WHERE combinator, so client input cannot OR around them.
+// On error the builder keeps failing closed and Err returns the same error.
+func (s *SQLBuilder) ApplyScopes(ctx context.Context) error {
+	if s.scopesApplied {
+		return s.failScopes(fmt.Errorf("%w: scopes already applied", ErrScopeOrder))
+	}
+	s.scopesApplied = true
+	if len(s.whereConditions) > 0 || len(s.params) > 0 || len(s.namedOrder) > 0 {
+		return s.failScopes(ErrScopeOrder)
+	}
+
+	for _, scope := range s.scopes {
+		expr, err := scope.Predicate(ctx)
+		if err != nil {
+			return s.failScopes(err)
+		}
+		if expr == nil {
+			continue
+		}
+		condition := s.Render(expr)
+		if condition == "" {
+			if s.err != nil {
+				return s.failScopes(s.err)
+			}
+			return s.failScopes(fmt.Errorf("%w: scope rendered no condition", ErrMissingScope))
+		}
+		s.scopeConditions = append(s.scopeConditions, condition)
+	}
+	return nil
+}
+
+// failScopes records err and makes the WHERE clause match no rows
+func (s *SQLBuilder) failScopes(err error) error {
+	s.scopeErr = err
+	s.scopeConditions = nil
+	s.setErr(err)
+	return err
+}
+
+// scopePredicates returns the applied scope predicates, false if the build must fail closed
+func (s *SQLBuilder) scopePredicates() ([]string, bool) {
+	if len(s.scopes) == 0 && !s.scopesApplied {
+		return nil, true
+	}
+	if !s.scopesApplied {
+		s.setErr(fmt.Errorf("%w: scopes were not applied", ErrMissingScope))
+		return nil, false
+	}
+	if s.scopeErr != nil {
+		return nil, false
+	}
+	return s.scopeConditions, true
+}
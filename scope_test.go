@@ -0,0 +1,265 @@
+package sqlbuilder
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Test scope predicates are ANDed outside client-supplied conditions
+func TestSQLBuilder_Scopes(t *testing.T) {
+	ctx := WithScopeValue(context.Background(), "tenant", 42)
+	bypass := CreateSearchGroup(LogicOr,
+		CreateSearchCondition("status", OpEqual, "active"),
+		CreateSearchCondition("id", OpGreaterThan, 0),
+	)
+
+	tests := []struct {
+		name       string
+		dialect    Dialect
+		combinator string
+		build      func(b *SQLBuilder)
+		expected   string
+		params     []any
+	}{
+		{
+			name:     "no user conditions",
+			dialect:  DialectMySQL,
+			build:    func(b *SQLBuilder) {},
+			expected: "WHERE tenant_id = ?",
+			params:   []any{42},
+		},
+		{
+			name:    "or group cannot bypass",
+			dialect: DialectMySQL,
+			build: func(b *SQLBuilder) {
+				b.AddWhereCondition(b.BuildAdvancedSearchConditions([]LogicalGroup{bypass}))
+			},
+			expected: "WHERE tenant_id = ? AND (status = ? OR id > ?)",
+			params:   []any{42, "active", 0},
+		},
+		{
+			name:       "or combinator",
+			dialect:    DialectPostgres,
+			combinator: LogicOr,
+			build: func(b *SQLBuilder) {
+				b.AddWhere("a = ?", 1)
+				b.AddWhereCondition("b = 2")
+			},
+			expected: "WHERE tenant_id = $1 AND ((a = $2) OR (b = 2))",
+			params:   []any{42, 1},
+		},
+		{
+			name:    "unparenthesized raw condition",
+			dialect: DialectMySQL,
+			build: func(b *SQLBuilder) {
+				b.AddWhereCondition("a = 1 OR 1 = 1")
+			},
+			expected: "WHERE tenant_id = ? AND (a = 1 OR 1 = 1)",
+			params:   []any{42},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			builder := NewSQLBuilder()
+			builder.SetDialect(tt.dialect)
+			if tt.combinator != "" {
+				builder.SetWhereCombinator(tt.combinator)
+			}
+			builder.SetScopes(RequireValue("tenant_id", "tenant"))
+			assert.NoError(t, builder.ApplyScopes(ctx))
+
+			tt.build(builder)
+			assert.Equal(t, tt.expected, builder.GetWhereClause())
+			assert.Equal(t, tt.params, builder.GetParams())
+			assert.NoError(t, builder.Err())
+		})
+	}
+}
+
+// Test client field names cannot close the parentheses around them to bypass scopes
+func TestSQLBuilder_ScopeFieldBypass(t *testing.T) {
+	tests := []struct {
+		name    string
+		dialect Dialect
+		query   *QueryParams
+		where   string
+		order   string
+	}{
+		{
+			name:    "filter field",
+			dialect: DialectMySQL,
+			query:   &QueryParams{Filters: []FilterCriteria{CreateFilterCondition("x) OR 1 = 1 OR (x", OpEqual, 1)}},
+			where:   "WHERE tenant_id = ? AND deleted_at IS NULL AND (1 = 0)",
+		},
+		{
+			name:    "search field among valid ones",
+			dialect: DialectMySQL,
+			query: &QueryParams{Search: []SearchCriteria{
+				CreateSearchCondition("name", OpEqual, "a"),
+				CreateMultiFieldSearchCondition(OpContains, "b", false, "title", "body) OR (1 = 1"),
+			}},
+			where: "WHERE tenant_id = ? AND deleted_at IS NULL AND (1 = 0)",
+		},
+		{
+			name:    "backtick quoting on postgres",
+			dialect: DialectPostgres,
+			query:   &QueryParams{Filters: []FilterCriteria{CreateFilterCondition("`x) OR (1`", OpEqual, 1)}},
+			where:   "WHERE tenant_id = $1 AND deleted_at IS NULL AND (1 = 0)",
+		},
+		{
+			name:    "sort field",
+			dialect: DialectMySQL,
+			query:   &QueryParams{Sort: []SortCriteria{{Field: "(SELECT password FROM users LIMIT 1)"}}},
+			where:   "WHERE tenant_id = ? AND deleted_at IS NULL",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			builder := NewSQLBuilder()
+			builder.SetDialect(tt.dialect)
+			builder.SetSchema(NewSchema().SetSoftDelete("deleted_at"))
+			builder.SetScopes(RequireValue("tenant_id", "tenant"))
+			assert.NoError(t, builder.ApplyScopes(WithScopeValue(context.Background(), "tenant", 7)))
+
+			tt.query.ApplySearch(builder)
+			tt.query.ApplyFilters(builder)
+			order := tt.query.ApplySort(builder)
+
+			assert.Equal(t, tt.where, builder.GetWhereClause())
+			assert.Equal(t, tt.order, order)
+			assert.Equal(t, []any{7}, builder.GetParams())
+			assert.ErrorIs(t, builder.Err(), ErrInvalidField)
+		})
+	}
+}
+
+// Test quoted and non-ASCII identifiers are valid fields
+func TestSQLBuilder_CheckField(t *testing.T) {
+	mysql := NewSQLBuilder()
+	for _, field := range []string{"name", "u.name", "`order`.`from`", "metadata->color", "prénom", "_x$1"} {
+		assert.NoError(t, mysql.checkField(field, OpEqual), field)
+	}
+	assert.NoError(t, mysql.checkField("title, body", OpFullText))
+	for _, field := range []string{"", "a b", "a,b", "a.", "\"x\"", "1a", "a--", "a;b"} {
+		assert.ErrorIs(t, mysql.checkField(field, OpEqual), ErrInvalidField, field)
+	}
+	assert.ErrorIs(t, mysql.checkField(",", OpFullText), ErrInvalidField)
+
+	postgres := NewSQLBuilder()
+	postgres.SetDialect(DialectPostgres)
+	assert.NoError(t, postgres.checkField(`"Order"."from"`, OpEqual))
+	assert.ErrorIs(t, postgres.checkField("`x`", OpEqual), ErrInvalidField)
+}
+
+// Test scope values, lists and optional scopes
+func TestSQLBuilder_ScopeValues(t *testing.T) {
+	ctx := WithScopeValue(context.Background(), "tenants", []any{1, 2})
+	admin := ScopeFunc(func(ctx context.Context) (Expr, error) { return nil, nil })
+
+	builder := NewNamedSQLBuilder()
+	builder.SetScopes(RequireValue("org_id", "tenants"), admin)
+	assert.NoError(t, builder.ApplyScopes(ctx))
+	builder.AddWhere("active")
+
+	assert.Equal(t, "org_id IN (:p_org_id_1, :p_org_id_2) AND (active)", builder.GetWhereClause(false))
+	assert.Equal(t, map[string]any{"p_org_id_1": 1, "p_org_id_2": 2}, builder.GetNamedParams())
+
+	value, ok := ScopeValue(ctx, "tenants")
+	assert.True(t, ok)
+	assert.Equal(t, []any{1, 2}, value)
+	_, ok = ScopeValue(ctx, "tenant")
+	assert.False(t, ok)
+}
+
+// Test builds fail closed when a required scope is missing
+func TestSQLBuilder_ScopeErrors(t *testing.T) {
+	errDenied := errors.New("denied")
+
+	tests := []struct {
+		name     string
+		ctx      context.Context
+		scope    Scope
+		apply    func(b *SQLBuilder) error
+		expected error
+		message  string
+	}{
+		{
+			name:     "missing value",
+			ctx:      context.Background(),
+			scope:    RequireValue("tenant_id", "tenant"),
+			expected: ErrMissingScope,
+			message:  "sqlbuilder: missing required scope: tenant",
+		},
+		{
+			name:     "empty list",
+			ctx:      WithScopeValue(context.Background(), "tenant", []any{}),
+			scope:    RequireValue("tenant_id", "tenant"),
+			expected: ErrMissingScope,
+			message:  "sqlbuilder: missing required scope: tenant is empty",
+		},
+		{
+			name:     "scope error",
+			ctx:      context.Background(),
+			scope:    ScopeFunc(func(ctx context.Context) (Expr, error) { return nil, errDenied }),
+			expected: errDenied,
+			message:  "denied",
+		},
+		{
+			name:     "not applied",
+			ctx:      context.Background(),
+			scope:    RequireValue("tenant_id", "tenant"),
+			apply:    func(b *SQLBuilder) error { return nil },
+			expected: ErrMissingScope,
+			message:  "sqlbuilder: missing required scope: scopes were not applied",
+		},
+		{
+			name:  "applied after conditions",
+			ctx:   WithScopeValue(context.Background(), "tenant", 1),
+			scope: RequireValue("tenant_id", "tenant"),
+			apply: func(b *SQLBuilder) error {
+				b.AddWhere("a = ?", 1)
+				return b.ApplyScopes(WithScopeValue(context.Background(), "tenant", 1))
+			},
+			expected: ErrScopeOrder,
+			message:  "sqlbuilder: scopes must be applied once, before any other condition",
+		},
+		{
+			name:  "applied twice",
+			ctx:   WithScopeValue(context.Background(), "tenant", 1),
+			scope: RequireValue("tenant_id", "tenant"),
+			apply: func(b *SQLBuilder) error {
+				_ = b.ApplyScopes(WithScopeValue(context.Background(), "tenant", 1))
+				return b.ApplyScopes(WithScopeValue(context.Background(), "tenant", 1))
+			},
+			expected: ErrScopeOrder,
+			message:  "sqlbuilder: scopes must be applied once, before any other condition: scopes already applied",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			builder := NewSQLBuilder()
+			builder.SetScopes(tt.scope)
+
+			var err error
+			if tt.apply != nil {
+				err = tt.apply(builder)
+			} else {
+				err = builder.ApplyScopes(tt.ctx)
+			}
+			if err != nil {
+				assert.ErrorIs(t, err, tt.expected)
+			}
+
+			builder.AddWhereCondition("status = 'active'")
+			assert.Equal(t, "WHERE 1 = 0", builder.GetWhereClause())
+			assert.ErrorIs(t, builder.Err(), tt.expected)
+			assert.EqualError(t, builder.Err(), tt.message)
+		})
+	}
+}
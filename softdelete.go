@@ -0,0 +1,87 @@
+package sqlbuilder
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrNoSoftDelete is returned when a soft-delete helper is used without a soft-delete column
+var ErrNoSoftDelete = errors.New("sqlbuilder: schema has no soft-delete column")
+
+// trashedMode selects which soft-deleted rows the WHERE clause keeps
+type trashedMode int
+
+// Soft-delete modes
+const (
+	trashedExclude trashedMode = iota // Only live rows, the default
+	trashedInclude                    // Live and soft-deleted rows
+	trashedOnly                       // Only soft-deleted rows
+)
+
+// SetSoftDelete declares column, e.g. deleted_at, as the soft-delete timestamp of the table
+// Builders using the schema then only match rows where the column is NULL, see WithTrashed and OnlyTrashed.
+func (sc *Schema) SetSoftDelete(column string) *Schema {
+	sc.softDeleteColumn = column
+	return sc
+}
+
+// SoftDeleteColumn returns the soft-delete column, empty if the table has none
+func (sc *Schema) SoftDeleteColumn() string {
+	if sc == nil {
+		return ""
+	}
+	return sc.softDeleteColumn
+}
+
+// WithTrashed makes the WHERE clause match soft-deleted rows as well as live ones
+func (s *SQLBuilder) WithTrashed() {
+	s.trashed = trashedInclude
+}
+
+// OnlyTrashed makes the WHERE clause match soft-deleted rows only
+func (s *SQLBuilder) OnlyTrashed() {
+	s.trashed = trashedOnly
+}
+
+// WithoutTrashed restores the default of matching live rows only
+func (s *SQLBuilder) WithoutTrashed() {
+	s.trashed = trashedExclude
+}
+
+// softDeleteCondition returns the soft-delete predicate of the schema and mode, empty if there is none
+func (s *SQLBuilder) softDeleteCondition() string {
+	column := s.schema.SoftDeleteColumn()
+	if column == "" {
+		return ""
+	}
+
+	switch s.trashed {
+	case trashedInclude:
+		return ""
+	case trashedOnly:
+		return column + " IS NOT NULL"
+	}
+	return column + " IS NULL"
+}
+
+// SoftDeleteSet returns the SET clause that soft-deletes rows, e.g. SET deleted_at = ?, and the
+// arguments of the whole UPDATE table SET ... WHERE ... statement in placeholder order
+// Call it after the WHERE conditions are built and use it with GetWhereClause, whose default
+// mode only matches live rows. A schema without soft-delete column sets Err.
+func (s *SQLBuilder) SoftDeleteSet(at time.Time) (string, []any) {
+	column := s.schema.SoftDeleteColumn()
+	if column == "" {
+		s.setErr(ErrNoSoftDelete)
+		return "", nil
+	}
+	if s.paramStyle == ParamPositional && s.dialect != DialectPostgres {
+		// ? placeholders are bound in statement order and SET comes before WHERE
+		return "SET " + column + " = ?", append([]any{at}, s.params...)
+	}
+
+	set := "SET " + column + " = " + s.bind(column, at)
+	if s.paramStyle == ParamNamed {
+		return set, s.GetNamedArgs()
+	}
+	return set, append([]any{}, s.params...)
+}
@@ -0,0 +1,157 @@
+package sqlbuilder
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Test the soft-delete predicate in each mode
+func TestSQLBuilder_SoftDelete(t *testing.T) {
+	tests := []struct {
+		name     string
+		schema   *Schema
+		mode     func(b *SQLBuilder)
+		build    func(b *SQLBuilder)
+		expected string
+	}{
+		{
+			name:     "no soft-delete column",
+			schema:   NewSchema(),
+			build:    func(b *SQLBuilder) { b.AddWhere("a = ?", 1) },
+			expected: "WHERE (a = ?)",
+		},
+		{
+			name:     "live rows only",
+			schema:   NewSchema().SetSoftDelete("deleted_at"),
+			build:    func(b *SQLBuilder) {},
+			expected: "WHERE deleted_at IS NULL",
+		},
+		{
+			name:   "or group stays inside",
+			schema: NewSchema().SetSoftDelete("deleted_at"),
+			build: func(b *SQLBuilder) {
+				b.AddWhereCondition("a = 1 OR b = 2")
+			},
+			expected: "WHERE deleted_at IS NULL AND (a = 1 OR b = 2)",
+		},
+		{
+			name:     "with trashed",
+			schema:   NewSchema().SetSoftDelete("deleted_at"),
+			mode:     func(b *SQLBuilder) { b.WithTrashed() },
+			build:    func(b *SQLBuilder) { b.AddWhere("a = ?", 1) },
+			expected: "WHERE (a = ?)",
+		},
+		{
+			name:     "only trashed",
+			schema:   NewSchema().SetSoftDelete("deleted_at"),
+			mode:     func(b *SQLBuilder) { b.OnlyTrashed() },
+			build:    func(b *SQLBuilder) { b.AddWhere("a = ?", 1) },
+			expected: "WHERE deleted_at IS NOT NULL AND (a = ?)",
+		},
+		{
+			name:   "without trashed",
+			schema: NewSchema().SetSoftDelete("deleted_at"),
+			mode: func(b *SQLBuilder) {
+				b.OnlyTrashed()
+				b.WithoutTrashed()
+			},
+			build:    func(b *SQLBuilder) {},
+			expected: "WHERE deleted_at IS NULL",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			builder := NewSQLBuilder()
+			builder.SetSchema(tt.schema)
+			if tt.mode != nil {
+				tt.mode(builder)
+			}
+			tt.build(builder)
+			assert.Equal(t, tt.expected, builder.GetWhereClause())
+			assert.NoError(t, builder.Err())
+		})
+	}
+
+	assert.Equal(t, "", NewSQLBuilder().GetWhereClause())
+	var nilSchema *Schema
+	assert.Equal(t, "", nilSchema.SoftDeleteColumn())
+}
+
+// Test soft-delete predicates follow the scope predicates
+func TestSQLBuilder_SoftDeleteWithScopes(t *testing.T) {
+	builder := NewSQLBuilder()
+	builder.SetSchema(NewSchema().SetSoftDelete("deleted_at"))
+	builder.SetScopes(RequireValue("tenant_id", "tenant"))
+	assert.NoError(t, builder.ApplyScopes(WithScopeValue(context.Background(), "tenant", 7)))
+	builder.AddWhere("a = ?", 1)
+
+	assert.Equal(t, "WHERE tenant_id = ? AND deleted_at IS NULL AND (a = ?)", builder.GetWhereClause())
+	assert.Equal(t, []any{7, 1}, builder.GetParams())
+}
+
+// Test the SET clause and arguments of soft-delete updates
+func TestSQLBuilder_SoftDeleteSet(t *testing.T) {
+	at := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	schema := NewSchema().SetSoftDelete("deleted_at")
+
+	tests := []struct {
+		name          string
+		dialect       Dialect
+		style         ParamStyle
+		expectedSet   string
+		expectedWhere string
+		expectedArgs  []any
+	}{
+		{
+			name:          "mysql",
+			expectedSet:   "SET deleted_at = ?",
+			expectedWhere: "WHERE deleted_at IS NULL AND (tenant_id = ? AND status = ?)",
+			expectedArgs:  []any{at, 7, "draft"},
+		},
+		{
+			name:          "postgres",
+			dialect:       DialectPostgres,
+			expectedSet:   "SET deleted_at = $3",
+			expectedWhere: "WHERE deleted_at IS NULL AND (tenant_id = $1 AND status = $2)",
+			expectedArgs:  []any{7, "draft", at},
+		},
+		{
+			name:          "named",
+			style:         ParamNamed,
+			expectedSet:   "SET deleted_at = :p_deleted_at_1",
+			expectedWhere: "WHERE deleted_at IS NULL AND (tenant_id = :p_tenant_id_1 AND status = :p_status_1)",
+			expectedArgs:  []any{sql.Named("p_tenant_id_1", 7), sql.Named("p_status_1", "draft"), sql.Named("p_deleted_at_1", at)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			builder := NewSQLBuilder()
+			builder.SetDialect(tt.dialect)
+			builder.SetParamStyle(tt.style)
+			builder.SetSchema(schema)
+			builder.AddWhereCondition(builder.BuildFilterConditions([]FilterCriteria{
+				CreateFilterCondition("tenant_id", OpEqual, 7),
+				CreateFilterCondition("status", OpEqual, "draft"),
+			}))
+
+			set, args := builder.SoftDeleteSet(at)
+			assert.NoError(t, builder.Err())
+			assert.Equal(t, tt.expectedSet, set)
+			assert.Equal(t, tt.expectedWhere, builder.GetWhereClause())
+			assert.Equal(t, tt.expectedArgs, args)
+		})
+	}
+
+	builder := NewSQLBuilder()
+	builder.SetSchema(NewSchema())
+	set, args := builder.SoftDeleteSet(at)
+	assert.Equal(t, "", set)
+	assert.Nil(t, args)
+	assert.ErrorIs(t, builder.Err(), ErrNoSoftDelete)
+}
@@ -0,0 +1,238 @@
+package sqlbuilder
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// ErrInvalidQueryString is returned when URL query parameters cannot be decoded
+var ErrInvalidQueryString = errors.New("sqlbuilder: invalid query string")
+
+// ParseQueryParams decodes the query parameters described by Schema.OpenAPIParameters
+// search, filters and sort are JSON encoded arrays, search_mode is any or all, page and
+// limit are integers. Missing parameters keep the NewQueryParams defaults, other keys are ignored.
+func ParseQueryParams(values url.Values) (*QueryParams, error) {
+	q := NewQueryParams()
+	if err := decodeQueryJSON(values, "search", &q.Search); err != nil {
+		return nil, err
+	}
+	if err := decodeQueryJSON(values, "filters", &q.Filters); err != nil {
+		return nil, err
+	}
+	if err := decodeQueryJSON(values, "sort", &q.Sort); err != nil {
+		return nil, err
+	}
+	q.SearchMode = values.Get("search_mode")
+	if _, err := searchModeOperator(q.SearchMode); err != nil {
+		return nil, fmt.Errorf("%w: search_mode %q", ErrInvalidQueryString, q.SearchMode)
+	}
+
+	pagination, err := decodeQueryPagination(values)
+	if err != nil {
+		return nil, err
+	}
+	q.Pagination = pagination
+	return q, nil
+}
+
+// ParseAdvancedQueryParams decodes query parameters like ParseQueryParams, with search_groups instead of search
+func ParseAdvancedQueryParams(values url.Values) (*AdvancedQueryParams, error) {
+	q := NewAdvancedQueryParams()
+	if err := decodeQueryJSON(values, "search_groups", &q.SearchGroups); err != nil {
+		return nil, err
+	}
+	if err := decodeQueryJSON(values, "filters", &q.Filters); err != nil {
+		return nil, err
+	}
+	if err := decodeQueryJSON(values, "sort", &q.Sort); err != nil {
+		return nil, err
+	}
+
+	pagination, err := decodeQueryPagination(values)
+	if err != nil {
+		return nil, err
+	}
+	q.Pagination = pagination
+	return q, nil
+}
+
+// Encode returns the canonical query parameters of q, the inverse of ParseQueryParams
+// Empty lists are left out, page and limit are always present and the offset is implied by them.
+func (q *QueryParams) Encode() (url.Values, error) {
+	values := url.Values{}
+	if err := encodeQueryJSON(values, "search", q.Search); err != nil {
+		return nil, err
+	}
+	if err := encodeQueryJSON(values, "filters", q.Filters); err != nil {
+		return nil, err
+	}
+	if err := encodeQueryJSON(values, "sort", q.Sort); err != nil {
+		return nil, err
+	}
+	if q.SearchMode != "" {
+		values.Set("search_mode", q.SearchMode)
+	}
+	encodeQueryPagination(values, q.Pagination)
+	return values, nil
+}
+
+// Encode returns the canonical query parameters of q, the inverse of ParseAdvancedQueryParams
+func (q *AdvancedQueryParams) Encode() (url.Values, error) {
+	values := url.Values{}
+	if err := encodeQueryJSON(values, "search_groups", q.SearchGroups); err != nil {
+		return nil, err
+	}
+	if err := encodeQueryJSON(values, "filters", q.Filters); err != nil {
+		return nil, err
+	}
+	if err := encodeQueryJSON(values, "sort", q.Sort); err != nil {
+		return nil, err
+	}
+	encodeQueryPagination(values, q.Pagination)
+	return values, nil
+}
+
+// LinkHeader returns an RFC 8288 Link header with the first, prev, next and last pages of meta
+// The links keep the query of baseURL, typically the current request URL, and replace its
+// page and limit. prev and next are left out on the first and last page.
+func LinkHeader(baseURL string, meta *PaginationMeta) (string, error) {
+	base, err := url.Parse(baseURL)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrInvalidQueryString, err)
+	}
+
+	link := func(page int, rel string) string {
+		values := base.Query()
+		encodeQueryPagination(values, PaginationParams{Page: page, Limit: meta.PageLimit})
+		target := *base
+		target.RawQuery = values.Encode()
+		return fmt.Sprintf("<%s>; rel=%q", target.String(), rel)
+	}
+
+	links := []string{link(1, "first")}
+	if meta.HasPrev {
+		links = append(links, link(meta.PrevPage, "prev"))
+	}
+	if meta.HasNext {
+		links = append(links, link(meta.NextPage, "next"))
+	}
+	links = append(links, link(meta.TotalPage, "last"))
+	return strings.Join(links, ", "), nil
+}
+
+// decodeQueryJSON decodes the JSON array of key into target, leaving target alone if key is missing
+// Criterion values keep their numbers exact, see exactNumbers.
+func decodeQueryJSON(values url.Values, key string, target any) error {
+	raw := values.Get(key)
+	if raw == "" {
+		return nil
+	}
+	// Syntax errors are reported as json.Unmarshal reports them, including trailing data
+	if err := json.Unmarshal([]byte(raw), new(json.RawMessage)); err != nil {
+		return fmt.Errorf("%w: %s: %v", ErrInvalidQueryString, key, err)
+	}
+	decoder := json.NewDecoder(strings.NewReader(raw))
+	decoder.UseNumber()
+	if err := decoder.Decode(target); err != nil {
+		return fmt.Errorf("%w: %s: %v", ErrInvalidQueryString, key, err)
+	}
+
+	switch target := target.(type) {
+	case *[]SearchCriteria:
+		exactCriteria(*target)
+	case *[]FilterCriteria:
+		for i := range *target {
+			(*target)[i].Value = exactNumbers((*target)[i].Value)
+		}
+	case *[]LogicalGroup:
+		exactGroups(*target)
+	}
+	return nil
+}
+
+// exactCriteria converts the numbers of the criterion values with exactNumbers
+func exactCriteria(criteria []SearchCriteria) {
+	for i := range criteria {
+		criteria[i].Value = exactNumbers(criteria[i].Value)
+	}
+}
+
+// exactGroups converts the numbers of the conditions of groups and their nested groups
+func exactGroups(groups []LogicalGroup) {
+	for i := range groups {
+		exactCriteria(groups[i].Conditions)
+		exactGroups(groups[i].Groups)
+	}
+}
+
+// exactNumbers converts the json.Number values of a decoded value, in lists and objects too
+// A number becomes a float64 if encoding the float64 gives back the same text, otherwise an
+// int64 if it is an integer in range, so 9007199254740993 is not rounded to the nearest float64.
+// Numbers that fit neither, such as 2.0 or 1e400, stay json.Number and are encoded unchanged.
+func exactNumbers(value any) any {
+	switch v := value.(type) {
+	case json.Number:
+		if f, err := v.Float64(); err == nil {
+			if encoded, err := json.Marshal(f); err == nil && string(encoded) == v.String() {
+				return f
+			}
+		}
+		if n, err := v.Int64(); err == nil {
+			return n
+		}
+	case []any:
+		for i := range v {
+			v[i] = exactNumbers(v[i])
+		}
+	case map[string]any:
+		for key := range v {
+			v[key] = exactNumbers(v[key])
+		}
+	}
+	return value
+}
+
+// encodeQueryJSON sets key to the JSON encoding of a non-empty list
+// HTML characters are not escaped, so values such as & stay readable once URL encoded.
+func encodeQueryJSON[T any](values url.Values, key string, list []T) error {
+	if len(list) == 0 {
+		return nil
+	}
+	var data bytes.Buffer
+	encoder := json.NewEncoder(&data)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(list); err != nil {
+		return fmt.Errorf("%w: %s: %v", ErrInvalidQueryString, key, err)
+	}
+	values.Set(key, strings.TrimSuffix(data.String(), "\n"))
+	return nil
+}
+
+// decodeQueryPagination decodes page and limit, defaults as in NewPaginationParams
+func decodeQueryPagination(values url.Values) (PaginationParams, error) {
+	var numbers [2]int
+	for i, key := range []string{"page", "limit"} {
+		raw := values.Get(key)
+		if raw == "" {
+			continue
+		}
+		number, err := strconv.Atoi(raw)
+		if err != nil {
+			return PaginationParams{}, fmt.Errorf("%w: %s %q is not an integer", ErrInvalidQueryString, key, raw)
+		}
+		numbers[i] = number
+	}
+	return NewPaginationParams(numbers[0], numbers[1]), nil
+}
+
+// encodeQueryPagination sets page and limit, normalized as in NewPaginationParams
+func encodeQueryPagination(values url.Values, pagination PaginationParams) {
+	pagination = NewPaginationParams(pagination.Page, pagination.Limit)
+	values.Set("page", strconv.Itoa(pagination.Page))
+	values.Set("limit", strconv.Itoa(pagination.Limit))
+}
@@ -0,0 +1,170 @@
+package sqlbuilder
+
+import (
+	"encoding/json"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Test QueryParams round-trip through URL query parameters
+func TestQueryParams_Encode(t *testing.T) {
+	q := NewQueryParams()
+	q.AddSearch("name", OpIContains, "ann & co")
+	q.AddFilter("age", OpIn, []any{float64(18), float64(21)})
+	q.AddSort("created_at", SortDesc)
+	q.SearchMode = SearchModeAll
+	q.SetPagination(3, 25)
+
+	values, err := q.Encode()
+	assert.NoError(t, err)
+	assert.Equal(t, url.Values{
+		"search":      {`[{"field":"name","operator":"icontains","value":"ann & co"}]`},
+		"search_mode": {"all"},
+		"filters":     {`[{"field":"age","operator":"in","value":[18,21]}]`},
+		"sort":        {`[{"field":"created_at","order":"desc"}]`},
+		"page":        {"3"},
+		"limit":       {"25"},
+	}, values)
+
+	parsed, err := ParseQueryParams(values)
+	assert.NoError(t, err)
+	assert.Equal(t, q, parsed)
+
+	again, err := parsed.Encode()
+	assert.NoError(t, err)
+	assert.Equal(t, values.Encode(), again.Encode())
+
+	// Parsing a raw query string and encoding it again gives the canonical form
+	raw, err := url.ParseQuery("limit=5&filters=%5B%7B%22field%22%3A%22x%22%2C%22operator%22%3A%22eq%22%2C%22value%22%3Atrue%7D%5D&tracking=1")
+	assert.NoError(t, err)
+	parsed, err = ParseQueryParams(raw)
+	assert.NoError(t, err)
+	values, err = parsed.Encode()
+	assert.NoError(t, err)
+	assert.Equal(t, `filters=%5B%7B%22field%22%3A%22x%22%2C%22operator%22%3A%22eq%22%2C%22value%22%3Atrue%7D%5D&limit=5&page=1`, values.Encode())
+
+	empty, err := ParseQueryParams(url.Values{})
+	assert.NoError(t, err)
+	assert.Equal(t, NewQueryParams(), empty)
+}
+
+// Test numbers round-trip exactly, including integers above 2^53
+func TestParseQueryParams_ExactNumbers(t *testing.T) {
+	values := url.Values{
+		"filters": {`[{"field":"id","operator":"eq","value":9007199254740993},{"field":"id","operator":"in","value":[1,2.5,2.0]},{"field":"meta","operator":"json_contains","value":{"n":18446744073709551617}}]`},
+		"page":    {"1"},
+		"limit":   {"10"},
+	}
+
+	parsed, err := ParseQueryParams(values)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(9007199254740993), parsed.Filters[0].Value)
+	assert.Equal(t, []any{float64(1), 2.5, json.Number("2.0")}, parsed.Filters[1].Value)
+	assert.Equal(t, map[string]any{"n": json.Number("18446744073709551617")}, parsed.Filters[2].Value)
+
+	again, err := parsed.Encode()
+	assert.NoError(t, err)
+	assert.Equal(t, values, again)
+
+	builder := NewSQLBuilder()
+	builder.SetSchema(NewSchema().AllowJSONPaths("meta", "n"))
+	parsed.ApplyFilters(builder)
+	assert.NoError(t, builder.Err())
+	assert.Equal(t, []any{int64(9007199254740993), float64(1), 2.5, json.Number("2.0"), `{"n":18446744073709551617}`}, builder.GetParams())
+}
+
+// Test AdvancedQueryParams round-trip through URL query parameters
+func TestAdvancedQueryParams_Encode(t *testing.T) {
+	q := NewAdvancedQueryParams()
+	q.SearchGroups = []LogicalGroup{{
+		Operator:   LogicOr,
+		Conditions: []SearchCriteria{CreateSearchCondition("status", OpEqual, "active")},
+		Groups:     []LogicalGroup{CreateSearchGroup(LogicAnd, CreateSearchCondition("age", OpGreaterThanEq, float64(18)))},
+		Not:        true,
+	}}
+	q.Sort = []SortCriteria{{Field: "location", Order: SortAsc, Near: &GeoPoint{Lat: 1.5, Lng: 2}}}
+
+	values, err := q.Encode()
+	assert.NoError(t, err)
+	assert.Equal(t, url.Values{
+		"search_groups": {`[{"operator":"OR","conditions":[{"field":"status","operator":"eq","value":"active"}],"groups":[{"operator":"AND","conditions":[{"field":"age","operator":"gte","value":18}],"groups":[],"not":false}],"not":true}]`},
+		"sort":          {`[{"field":"location","order":"asc","near":{"lat":1.5,"lng":2}}]`},
+		"page":          {"1"},
+		"limit":         {"10"},
+	}, values)
+
+	parsed, err := ParseAdvancedQueryParams(values)
+	assert.NoError(t, err)
+	assert.Equal(t, q, parsed)
+
+	again, err := parsed.Encode()
+	assert.NoError(t, err)
+	assert.Equal(t, values, again)
+}
+
+// Test ParseQueryParams errors
+func TestParseQueryParams_Errors(t *testing.T) {
+	tests := []struct {
+		name     string
+		query    string
+		expected string
+	}{
+		{name: "invalid json", query: "filters=%5B", expected: "sqlbuilder: invalid query string: filters: unexpected end of JSON input"},
+		{name: "not a list", query: "sort=%7B%7D", expected: "sqlbuilder: invalid query string: sort: json: cannot unmarshal object into Go value of type []sqlbuilder.SortCriteria"},
+		{name: "page", query: "page=two", expected: `sqlbuilder: invalid query string: page "two" is not an integer`},
+		{name: "search mode", query: "search_mode=some", expected: `sqlbuilder: invalid query string: search_mode "some"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			values, err := url.ParseQuery(tt.query)
+			assert.NoError(t, err)
+
+			_, err = ParseQueryParams(values)
+			assert.ErrorIs(t, err, ErrInvalidQueryString)
+			assert.EqualError(t, err, tt.expected)
+		})
+	}
+
+	_, err := ParseAdvancedQueryParams(url.Values{"limit": {"x"}})
+	assert.ErrorIs(t, err, ErrInvalidQueryString)
+}
+
+// Test RFC 8288 Link headers
+func TestLinkHeader(t *testing.T) {
+	base := "https://api.example.com/users?sort=%5B%5D&page=2&limit=10"
+
+	tests := []struct {
+		name     string
+		meta     *PaginationMeta
+		expected string
+	}{
+		{
+			name: "middle page",
+			meta: CalculatePaginationMeta(45, 2, 10),
+			expected: `<https://api.example.com/users?limit=10&page=1&sort=%5B%5D>; rel="first", ` +
+				`<https://api.example.com/users?limit=10&page=1&sort=%5B%5D>; rel="prev", ` +
+				`<https://api.example.com/users?limit=10&page=3&sort=%5B%5D>; rel="next", ` +
+				`<https://api.example.com/users?limit=10&page=5&sort=%5B%5D>; rel="last"`,
+		},
+		{
+			name: "single page",
+			meta: CalculatePaginationMeta(3, 1, 20),
+			expected: `<https://api.example.com/users?limit=20&page=1&sort=%5B%5D>; rel="first", ` +
+				`<https://api.example.com/users?limit=20&page=1&sort=%5B%5D>; rel="last"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header, err := LinkHeader(base, tt.meta)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, header)
+		})
+	}
+
+	_, err := LinkHeader("http://[::1", CalculatePaginationMeta(1, 1, 10))
+	assert.ErrorIs(t, err, ErrInvalidQueryString)
+}
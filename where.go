@@ -0,0 +1,1438 @@
+package sqlbuilder
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// ErrSyntax is returned when a WHERE fragment cannot be parsed
+var ErrSyntax = errors.New("sqlbuilder: syntax error")
+
+// whereOperators maps SQL comparison symbols to search operators
+var whereOperators = map[string]string{
+	"=":   OpEqual,
+	"!=":  OpNotEqual,
+	"<>":  OpNotEqual,
+	">":   OpGreaterThan,
+	">=":  OpGreaterThanEq,
+	"<":   OpLessThan,
+	"<=":  OpLessThanEq,
+	"~":   OpRegex,
+	"~*":  OpIRegex,
+	"!~":  OpNotRegex,
+	"!~*": OpNotIRegex,
+}
+
+// likeOperators lists the plain, case-insensitive, negated and negated case-insensitive
+// variants of each LIKE operator
+var likeOperators = map[string][4]string{
+	OpContains:   {OpContains, OpIContains, OpNotContains, OpNotIContains},
+	OpStartsWith: {OpStartsWith, OpIStartsWith, OpNotStartsWith, OpNotIStartsWith},
+	OpEndsWith:   {OpEndsWith, OpIEndsWith, OpNotEndsWith, OpNotIEndsWith},
+	OpLike:       {OpLike, OpILike, OpNotLike, OpNotILike},
+}
+
+// whereKeywords cannot be used as unquoted column names
+var whereKeywords = map[string]bool{
+	"AND": true, "OR": true, "NOT": true, "NULL": true, "IS": true,
+	"IN": true, "LIKE": true, "REGEXP": true, "TRUE": true, "FALSE": true,
+}
+
+// ParseWhere parses a SQL boolean expression back into a LogicalGroup
+// It accepts the conditions rendered by BuildAdvancedSearchConditions for both dialects:
+// comparisons, [NOT] LIKE with optional LOWER(), [NOT] IN, IS [NOT] NULL, regex matches,
+// full-text matches, JSON paths and operators, array and geospatial operators, NOT and
+// parentheses, joined by AND/OR with the usual precedence. Placeholders take their values
+// from args: ? in order, $n by position and :name from sql.NamedArg. Literal strings,
+// numbers, TRUE, FALSE and NULL are accepted as values.
+//
+// LIKE patterns are mapped back to the narrowest operator, e.g. LOWER(name) LIKE LOWER('%go%')
+// becomes icontains "go". JSON paths become dotted fields such as metadata.color, so rendering
+// them again needs the same JSON columns in the schema. JSON documents bound as text are
+// decoded, keeping numbers as json.Number. Other function calls are rejected with ErrSyntax.
+// 1 = 0, rendered for parts that could not be compiled, becomes a group with the operator
+// "1 = 0", which renders 1 = 0 again and sets SQLBuilder.Err.
+func ParseWhere(where string, args ...any) (LogicalGroup, error) {
+	tokens, err := lexWhere(where)
+	if err != nil {
+		return LogicalGroup{}, err
+	}
+
+	p := &whereParser{tokens: tokens, args: args}
+	if p.peek().kind == tokEOF {
+		return CreateSearchGroup(LogicAnd), nil
+	}
+
+	node, err := p.parseOr()
+	if err != nil {
+		return LogicalGroup{}, err
+	}
+	if tok := p.peek(); tok.kind != tokEOF {
+		return LogicalGroup{}, p.errorf(tok, "unexpected %s", tok)
+	}
+
+	if node.group != nil {
+		return *node.group, nil
+	}
+	return CreateSearchGroup(LogicAnd, *node.criterion), nil
+}
+
+type whereTokenKind int
+
+const (
+	tokEOF whereTokenKind = iota
+	tokIdent
+	tokString
+	tokNumber
+	tokParam
+	tokSymbol
+)
+
+// whereToken is a lexed token and its byte offset in the input
+type whereToken struct {
+	kind   whereTokenKind
+	text   string
+	pos    int
+	quoted bool // Identifier written as `name` or "name"
+}
+
+// String describes the token in error messages
+func (t whereToken) String() string {
+	switch t.kind {
+	case tokEOF:
+		return "end of input"
+	case tokString:
+		return "'" + t.text + "'"
+	}
+	return strconv.Quote(t.text)
+}
+
+// whereSymbols are the punctuation tokens, longest first
+var whereSymbols = []string{
+	"!~*", "->>", "#>>", "!~", "~*", "<=", ">=", "<>", "!=", "@@", "@>", "<@", "&&", "::", "->", "#>",
+	"=", "<", ">", "~", "(", ")", ",", "-",
+}
+
+// lexWhere splits a WHERE fragment into tokens
+func lexWhere(input string) ([]whereToken, error) {
+	var tokens []whereToken
+	i := 0
+	for i < len(input) {
+		c, size := utf8.DecodeRuneInString(input[i:])
+		start := i
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+			continue
+		case c == '\'':
+			var text strings.Builder
+			for i++; ; i++ {
+				if i >= len(input) {
+					return nil, fmt.Errorf("%w at offset %d: unterminated string", ErrSyntax, start)
+				}
+				if input[i] == '\'' {
+					if i+1 < len(input) && input[i+1] == '\'' {
+						text.WriteByte('\'')
+						i++
+						continue
+					}
+					break
+				}
+				text.WriteByte(input[i])
+			}
+			i++
+			tokens = append(tokens, whereToken{kind: tokString, text: text.String(), pos: start})
+		case isWhereIdentStart(c):
+			text, end, quoted, err := lexIdent(input, i)
+			if err != nil {
+				return nil, err
+			}
+			i = end
+			tokens = append(tokens, whereToken{kind: tokIdent, text: text, pos: start, quoted: quoted})
+		case c >= '0' && c <= '9':
+			for i < len(input) && (input[i] >= '0' && input[i] <= '9' || input[i] == '.') {
+				i++
+			}
+			tokens = append(tokens, whereToken{kind: tokNumber, text: input[start:i], pos: start})
+		case c == '?':
+			i++
+			tokens = append(tokens, whereToken{kind: tokParam, text: "?", pos: start})
+		case c == '$' && i+1 < len(input) && input[i+1] >= '0' && input[i+1] <= '9':
+			for i++; i < len(input) && input[i] >= '0' && input[i] <= '9'; i++ {
+			}
+			tokens = append(tokens, whereToken{kind: tokParam, text: input[start:i], pos: start})
+		case c == ':' && isNamedParamStart(input[i+size:]):
+			i = identEnd(input, i+size)
+			tokens = append(tokens, whereToken{kind: tokParam, text: input[start:i], pos: start})
+		default:
+			symbol := ""
+			for _, candidate := range whereSymbols {
+				if strings.HasPrefix(input[i:], candidate) {
+					symbol = candidate
+					break
+				}
+			}
+			if symbol == "" {
+				return nil, fmt.Errorf("%w at offset %d: unexpected character %q", ErrSyntax, start, c)
+			}
+			i += len(symbol)
+			tokens = append(tokens, whereToken{kind: tokSymbol, text: symbol, pos: start})
+		}
+	}
+	return append(tokens, whereToken{kind: tokEOF, pos: len(input)}), nil
+}
+
+// lexIdent reads a possibly dotted identifier whose parts may be quoted with ` or "
+func lexIdent(input string, i int) (string, int, bool, error) {
+	var parts []string
+	quoted := false
+	for {
+		start := i
+		if c := input[i]; c == '`' || c == '"' {
+			end := strings.IndexByte(input[i+1:], c)
+			if end < 0 {
+				return "", 0, false, fmt.Errorf("%w at offset %d: unterminated identifier", ErrSyntax, start)
+			}
+			i += end + 2
+			parts = append(parts, input[start+1:i-1])
+			quoted = true
+		} else {
+			i = identEnd(input, i)
+			parts = append(parts, input[start:i])
+		}
+		if i+1 >= len(input) || input[i] != '.' {
+			return strings.Join(parts, "."), i, quoted, nil
+		}
+		if next, _ := utf8.DecodeRuneInString(input[i+1:]); !isWhereIdentStart(next) {
+			return strings.Join(parts, "."), i, quoted, nil
+		}
+		i++
+	}
+}
+
+// identEnd returns the offset after the unquoted identifier part starting at i
+func identEnd(input string, i int) int {
+	for i < len(input) {
+		c, size := utf8.DecodeRuneInString(input[i:])
+		if !isWhereIdentRune(c) {
+			break
+		}
+		i += size
+	}
+	return i
+}
+
+// isNamedParamStart reports whether rest starts with the name of a :name placeholder
+func isNamedParamStart(rest string) bool {
+	c, _ := utf8.DecodeRuneInString(rest)
+	return c == '_' || unicode.IsLetter(c)
+}
+
+// isWhereIdentStart reports whether c can start an identifier part
+func isWhereIdentStart(c rune) bool {
+	return c == '`' || c == '"' || c == '_' || unicode.IsLetter(c)
+}
+
+// isWhereIdentRune reports whether c can continue an unquoted identifier part
+func isWhereIdentRune(c rune) bool {
+	return c == '_' || c >= '0' && c <= '9' || unicode.IsLetter(c)
+}
+
+// whereNode is either a single criterion or a group
+type whereNode struct {
+	criterion *SearchCriteria
+	group     *LogicalGroup
+}
+
+// whereParser is a recursive descent parser over lexed tokens
+type whereParser struct {
+	tokens []whereToken
+	pos    int
+	args   []any
+	next   int // Index of the argument bound to the next ? placeholder
+}
+
+func (p *whereParser) peek() whereToken {
+	return p.tokens[p.pos]
+}
+
+func (p *whereParser) advance() whereToken {
+	tok := p.tokens[p.pos]
+	if tok.kind != tokEOF {
+		p.pos++
+	}
+	return tok
+}
+
+func (p *whereParser) errorf(tok whereToken, format string, args ...any) error {
+	return fmt.Errorf("%w at offset %d: %s", ErrSyntax, tok.pos, fmt.Sprintf(format, args...))
+}
+
+// isKeyword reports whether tok is the unquoted keyword, ignoring case
+func isKeyword(tok whereToken, keyword string) bool {
+	return tok.kind == tokIdent && !tok.quoted && strings.EqualFold(tok.text, keyword)
+}
+
+func (p *whereParser) acceptKeyword(keyword string) bool {
+	if isKeyword(p.peek(), keyword) {
+		p.advance()
+		return true
+	}
+	return false
+}
+
+func (p *whereParser) expectKeyword(keywords ...string) error {
+	for _, keyword := range keywords {
+		if tok := p.peek(); !p.acceptKeyword(keyword) {
+			return p.errorf(tok, "expected %s, got %s", keyword, tok)
+		}
+	}
+	return nil
+}
+
+func (p *whereParser) acceptSymbol(symbol string) bool {
+	if tok := p.peek(); tok.kind == tokSymbol && tok.text == symbol {
+		p.advance()
+		return true
+	}
+	return false
+}
+
+func (p *whereParser) expectSymbol(symbol string) error {
+	if tok := p.peek(); !p.acceptSymbol(symbol) {
+		return p.errorf(tok, "expected %q, got %s", symbol, tok)
+	}
+	return nil
+}
+
+// parseOr parses OR chains, which bind weaker than AND
+func (p *whereParser) parseOr() (whereNode, error) {
+	return parseChain(LogicOr, p.acceptKeyword, p.parseAnd)
+}
+
+// parseAnd parses AND chains
+func (p *whereParser) parseAnd() (whereNode, error) {
+	return parseChain(LogicAnd, p.acceptKeyword, p.parseUnary)
+}
+
+// parseUnary parses NOT prefixes
+func (p *whereParser) parseUnary() (whereNode, error) {
+	if !p.acceptKeyword("NOT") {
+		return p.parsePrimary()
+	}
+
+	// NOT REGEXP_LIKE(...) is how MySQL negates case-insensitive regex matches
+	if isKeyword(p.peek(), "REGEXP_LIKE") {
+		criterion, err := p.parseRegexpLike()
+		if err != nil {
+			return whereNode{}, err
+		}
+		criterion.Operator = map[string]string{OpRegex: OpNotRegex, OpIRegex: OpNotIRegex}[criterion.Operator]
+		return whereNode{criterion: &criterion}, nil
+	}
+
+	node, err := p.parseUnary()
+	if err != nil {
+		return whereNode{}, err
+	}
+	return negateNode(node), nil
+}
+
+// parsePrimary parses a parenthesized expression or a single predicate
+func (p *whereParser) parsePrimary() (whereNode, error) {
+	if p.acceptNever() {
+		group := CreateSearchGroup(scopeNever)
+		return whereNode{group: &group}, nil
+	}
+	if !p.castFollows() && p.acceptSymbol("(") {
+		node, err := p.parseOr()
+		if err != nil {
+			return whereNode{}, err
+		}
+		return node, p.expectSymbol(")")
+	}
+
+	criterion, err := p.parsePredicate()
+	if err != nil {
+		return whereNode{}, err
+	}
+	return whereNode{criterion: &criterion}, nil
+}
+
+// acceptNever consumes 1 = 0, which the builder renders for parts it could not compile
+func (p *whereParser) acceptNever() bool {
+	if p.pos+3 > len(p.tokens) {
+		return false
+	}
+	one, equals, zero := p.tokens[p.pos], p.tokens[p.pos+1], p.tokens[p.pos+2]
+	if one.kind != tokNumber || one.text != "1" || equals.kind != tokSymbol || equals.text != "=" ||
+		zero.kind != tokNumber || zero.text != "0" {
+		return false
+	}
+	p.pos += 3
+	return true
+}
+
+// parsePredicate parses a single condition
+func (p *whereParser) parsePredicate() (SearchCriteria, error) {
+	tok := p.peek()
+	switch {
+	case isKeyword(tok, "LOWER"):
+		return p.parseLowerLike()
+	case isKeyword(tok, "REGEXP_LIKE"):
+		return p.parseRegexpLike()
+	case isKeyword(tok, "MATCH"):
+		return p.parseMatch()
+	case isKeyword(tok, "to_tsvector"):
+		return p.parseTSVector()
+	case p.peekCall("JSON_CONTAINS"):
+		return p.parseJSONContains()
+	case p.peekCall("JSON_OVERLAPS"):
+		return p.parseJSONOverlaps()
+	case p.peekCall("JSON_CONTAINS_PATH"):
+		return p.parseJSONContainsPath()
+	case p.peekCall("jsonb_exists"):
+		return p.parseJSONBExists()
+	case p.peekCall("JSON_LENGTH"), p.peekCall("cardinality"):
+		return p.parseArrayLength()
+	case p.peekCall("ST_Distance_Sphere"):
+		return p.parseDistanceSphere()
+	case p.peekCall("ST_DWithin"):
+		return p.parseDWithin()
+	case p.peekCall("MBRContains"):
+		return p.parseMBRContains()
+	case p.peekCall("ST_Within"):
+		return p.parseSTWithin()
+	case tok.kind == tokParam || tok.kind == tokString || tok.kind == tokNumber:
+		return p.parseMemberOf()
+	}
+
+	field, document, err := p.parseOperand()
+	if err != nil {
+		return SearchCriteria{}, err
+	}
+
+	if tok := p.peek(); tok.kind == tokSymbol && (tok.text == "@>" || tok.text == "<@" || tok.text == "&&") {
+		return p.parseContainment(field, document)
+	}
+	if document {
+		return SearchCriteria{}, p.errorf(p.peek(), "expected @> after JSON document %s, got %s", field, p.peek())
+	}
+
+	if tok := p.peek(); tok.kind == tokSymbol {
+		operator, ok := whereOperators[tok.text]
+		if !ok {
+			return SearchCriteria{}, p.errorf(tok, "unsupported operator %s", tok)
+		}
+		p.advance()
+		value, err := p.parseValue()
+		return CreateSearchCondition(field, operator, value), err
+	}
+
+	if p.acceptKeyword("IS") {
+		operator := OpIsNull
+		if p.acceptKeyword("NOT") {
+			operator = OpIsNotNull
+		}
+		return CreateSearchCondition(field, operator, nil), p.expectKeyword("NULL")
+	}
+
+	negated := p.acceptKeyword("NOT")
+	switch tok := p.peek(); {
+	case p.acceptKeyword("LIKE"):
+		value, err := p.parseValue()
+		operator, pattern := likeCriterion(value, false, negated)
+		return CreateSearchCondition(field, operator, pattern), err
+	case p.acceptKeyword("REGEXP"):
+		value, err := p.parseValue()
+		operator := OpRegex
+		if negated {
+			operator = OpNotRegex
+		}
+		return CreateSearchCondition(field, operator, value), err
+	case p.acceptKeyword("IN"):
+		values, err := p.parseValueList()
+		operator := OpIn
+		if negated {
+			operator = OpNotIn
+		}
+		return CreateSearchCondition(field, operator, values), err
+	default:
+		return SearchCriteria{}, p.errorf(tok, "expected operator after %s, got %s", field, tok)
+	}
+}
+
+// parseLowerLike parses LOWER(field) [NOT] LIKE LOWER(value)
+func (p *whereParser) parseLowerLike() (SearchCriteria, error) {
+	p.advance()
+	if err := p.expectSymbol("("); err != nil {
+		return SearchCriteria{}, err
+	}
+	field, err := p.parseColumn()
+	if err != nil {
+		return SearchCriteria{}, err
+	}
+	if err := p.expectSymbol(")"); err != nil {
+		return SearchCriteria{}, err
+	}
+
+	negated := p.acceptKeyword("NOT")
+	if err := p.expectKeyword("LIKE", "LOWER"); err != nil {
+		return SearchCriteria{}, err
+	}
+	value, err := p.parseCall()
+	if err != nil {
+		return SearchCriteria{}, err
+	}
+	operator, pattern := likeCriterion(value, true, negated)
+	return CreateSearchCondition(field, operator, pattern), nil
+}
+
+// parseRegexpLike parses REGEXP_LIKE(field, value[, 'i'])
+func (p *whereParser) parseRegexpLike() (SearchCriteria, error) {
+	p.advance()
+	if err := p.expectSymbol("("); err != nil {
+		return SearchCriteria{}, err
+	}
+	field, err := p.parseColumn()
+	if err != nil {
+		return SearchCriteria{}, err
+	}
+	if err := p.expectSymbol(","); err != nil {
+		return SearchCriteria{}, err
+	}
+	value, err := p.parseValue()
+	if err != nil {
+		return SearchCriteria{}, err
+	}
+
+	operator := OpRegex
+	if p.acceptSymbol(",") {
+		tok := p.peek()
+		flags, err := p.parseValue()
+		if err != nil {
+			return SearchCriteria{}, err
+		}
+		switch flags {
+		case "i":
+			operator = OpIRegex
+		case "c":
+		default:
+			return SearchCriteria{}, p.errorf(tok, "unsupported regex flags %v", flags)
+		}
+	}
+	return CreateSearchCondition(field, operator, value), p.expectSymbol(")")
+}
+
+// parseMatch parses MySQL MATCH(columns) AGAINST(value [mode])
+func (p *whereParser) parseMatch() (SearchCriteria, error) {
+	p.advance()
+	columns, err := p.parseColumnList()
+	if err != nil {
+		return SearchCriteria{}, err
+	}
+	if err := p.expectKeyword("AGAINST"); err != nil {
+		return SearchCriteria{}, err
+	}
+	if err := p.expectSymbol("("); err != nil {
+		return SearchCriteria{}, err
+	}
+	value, err := p.parseValue()
+	if err != nil {
+		return SearchCriteria{}, err
+	}
+
+	operator := OpFullText
+	switch {
+	case p.acceptKeyword("IN"):
+		if p.acceptKeyword("BOOLEAN") {
+			operator = OpFullTextBoolean
+		} else if err := p.expectKeyword("NATURAL", "LANGUAGE"); err != nil {
+			return SearchCriteria{}, err
+		}
+		err = p.expectKeyword("MODE")
+	case p.acceptKeyword("WITH"):
+		operator = OpFullTextExpansion
+		err = p.expectKeyword("QUERY", "EXPANSION")
+	}
+	if err != nil {
+		return SearchCriteria{}, err
+	}
+	return CreateSearchCondition(strings.Join(columns, ","), operator, value), p.expectSymbol(")")
+}
+
+// parseTSVector parses PostgreSQL to_tsvector(document) @@ plainto_tsquery(value)
+// The document is a column or concat_ws(' ', columns...), websearch_to_tsquery maps to OpFullTextBoolean.
+func (p *whereParser) parseTSVector() (SearchCriteria, error) {
+	p.advance()
+	if err := p.expectSymbol("("); err != nil {
+		return SearchCriteria{}, err
+	}
+
+	var columns []string
+	if p.acceptKeyword("concat_ws") {
+		if err := p.expectSymbol("("); err != nil {
+			return SearchCriteria{}, err
+		}
+		if tok := p.advance(); tok.kind != tokString || tok.text != " " {
+			return SearchCriteria{}, p.errorf(tok, "expected ' ' separator, got %s", tok)
+		}
+		for p.acceptSymbol(",") {
+			column, err := p.parseColumn()
+			if err != nil {
+				return SearchCriteria{}, err
+			}
+			columns = append(columns, column)
+		}
+		if err := p.expectSymbol(")"); err != nil {
+			return SearchCriteria{}, err
+		}
+	} else {
+		column, err := p.parseColumn()
+		if err != nil {
+			return SearchCriteria{}, err
+		}
+		columns = append(columns, column)
+	}
+	if err := p.expectSymbol(")"); err != nil {
+		return SearchCriteria{}, err
+	}
+	if err := p.expectSymbol("@@"); err != nil {
+		return SearchCriteria{}, err
+	}
+
+	operator := OpFullText
+	tok := p.peek()
+	switch {
+	case p.acceptKeyword("websearch_to_tsquery"):
+		operator = OpFullTextBoolean
+	case !p.acceptKeyword("plainto_tsquery"):
+		return SearchCriteria{}, p.errorf(tok, "expected plainto_tsquery or websearch_to_tsquery, got %s", tok)
+	}
+	value, err := p.parseCall()
+	return CreateSearchCondition(strings.Join(columns, ","), operator, value), err
+}
+
+// parseColumn parses a column name or a JSON path extracted as text, rejecting keywords and other function calls
+func (p *whereParser) parseColumn() (string, error) {
+	tok := p.peek()
+	field, document, err := p.parseOperand()
+	if err == nil && document {
+		return "", p.errorf(tok, "expected ->> or #>> on JSON path %s", field)
+	}
+	return field, err
+}
+
+// parseOperand parses a column, a JSON path or a numeric cast of either, as rendered by resolveField
+// JSON paths become dotted fields such as metadata.size.width. document is true for paths
+// extracted as JSON with -> or #>, which only OpJSONContains and OpJSONHasKey render.
+func (p *whereParser) parseOperand() (field string, document bool, err error) {
+	switch {
+	case p.castFollows():
+		// (metadata->>'size')::numeric
+		p.advance()
+		if field, err = p.parseColumn(); err != nil {
+			return "", false, err
+		}
+		if err = p.expectSymbol(")"); err == nil {
+			err = p.expectSymbol("::")
+		}
+		if err == nil {
+			err = p.expectKeyword("numeric")
+		}
+		return field, false, err
+	case p.peekCall("CAST"):
+		// CAST(JSON_UNQUOTE(JSON_EXTRACT(metadata, '$.size')) AS DECIMAL(65, 30))
+		p.advance()
+		if err = p.expectSymbol("("); err == nil {
+			field, err = p.parseColumn()
+		}
+		if err == nil {
+			err = p.expectKeyword("AS", "DECIMAL")
+		}
+		if tok := p.peek(); err == nil && tok.kind == tokSymbol && tok.text == "(" {
+			_, err = p.parseValueList()
+		}
+		if err == nil {
+			err = p.expectSymbol(")")
+		}
+		return field, false, err
+	case p.peekCall("JSON_UNQUOTE"):
+		// JSON_UNQUOTE(JSON_EXTRACT(metadata, '$.size.width'))
+		p.advance()
+		if err = p.expectSymbol("("); err == nil {
+			err = p.expectKeyword("JSON_EXTRACT")
+		}
+		if err == nil {
+			err = p.expectSymbol("(")
+		}
+		if err != nil {
+			return "", false, err
+		}
+		if field, err = p.parseName(); err != nil {
+			return "", false, err
+		}
+		if err = p.expectSymbol(","); err != nil {
+			return "", false, err
+		}
+		path, err := p.parseMySQLJSONPath()
+		if err != nil {
+			return "", false, err
+		}
+		if err = p.expectSymbol(")"); err == nil {
+			err = p.expectSymbol(")")
+		}
+		return field + "." + path, false, err
+	}
+
+	name, err := p.parseName()
+	if err != nil {
+		return "", false, err
+	}
+	field, arrow, err := p.parseJSONArrow(name)
+	return field, arrow == "->" || arrow == "#>", err
+}
+
+// parseJSONArrow parses an optional ->, ->>, #> or #>> path after column
+// It returns the dotted field and the arrow, empty if there is none.
+func (p *whereParser) parseJSONArrow(column string) (string, string, error) {
+	arrow := p.peek()
+	if arrow.kind != tokSymbol {
+		return column, "", nil
+	}
+	switch arrow.text {
+	case "->", "->>":
+		p.advance()
+		key := p.advance()
+		if key.kind != tokString {
+			return "", "", p.errorf(key, "expected JSON key, got %s", key)
+		}
+		return column + "." + key.text, arrow.text, nil
+	case "#>", "#>>":
+		p.advance()
+		path := p.advance()
+		if path.kind != tokString || !strings.HasPrefix(path.text, "{") || !strings.HasSuffix(path.text, "}") {
+			return "", "", p.errorf(path, "expected JSON path such as '{a,b}', got %s", path)
+		}
+		segments := strings.Split(strings.Trim(path.text, "{}"), ",")
+		return column + "." + strings.Join(segments, "."), arrow.text, nil
+	}
+	return column, "", nil
+}
+
+// parseName parses a plain column name, rejecting keywords and function calls
+func (p *whereParser) parseName() (string, error) {
+	tok := p.peek()
+	if tok.kind != tokIdent || !tok.quoted && whereKeywords[strings.ToUpper(tok.text)] {
+		return "", p.errorf(tok, "expected column, got %s", tok)
+	}
+	p.advance()
+	if next := p.peek(); next.kind == tokSymbol && next.text == "(" {
+		return "", p.errorf(tok, "unsupported function %s", tok.text)
+	}
+	return tok.text, nil
+}
+
+// parseMySQLJSONPath parses a '$.a.b' literal and returns the path a.b
+func (p *whereParser) parseMySQLJSONPath() (string, error) {
+	tok := p.advance()
+	if tok.kind != tokString || !strings.HasPrefix(tok.text, "$.") {
+		return "", p.errorf(tok, "expected JSON path such as '$.a', got %s", tok)
+	}
+	return tok.text[2:], nil
+}
+
+// peekCall reports whether the next tokens are a call of the named function, ignoring case
+func (p *whereParser) peekCall(name string) bool {
+	next := p.tokens[min(p.pos+1, len(p.tokens)-1)]
+	return isKeyword(p.peek(), name) && next.kind == tokSymbol && next.text == "("
+}
+
+// castFollows reports whether the parenthesis at the current token is followed by a :: cast
+func (p *whereParser) castFollows() bool {
+	if tok := p.peek(); tok.kind != tokSymbol || tok.text != "(" {
+		return false
+	}
+	depth := 0
+	for i := p.pos; i < len(p.tokens)-1; i++ {
+		if tok := p.tokens[i]; tok.kind == tokSymbol && tok.text == "(" {
+			depth++
+		} else if tok.kind == tokSymbol && tok.text == ")" {
+			if depth--; depth == 0 {
+				next := p.tokens[i+1]
+				return next.kind == tokSymbol && next.text == "::"
+			}
+		}
+	}
+	return false
+}
+
+// parseContainment parses the PostgreSQL operators field @> value, field <@ value and field && value
+// A value cast to jsonb is a JSON document for OpJSONContains, anything else an array parameter.
+func (p *whereParser) parseContainment(field string, document bool) (SearchCriteria, error) {
+	symbol := p.advance()
+	tok := p.peek()
+	value, err := p.parseValue()
+	if err != nil {
+		return SearchCriteria{}, err
+	}
+	if p.acceptSymbol("::") {
+		if err := p.expectKeyword("jsonb"); err != nil {
+			return SearchCriteria{}, err
+		}
+		if symbol.text != "@>" {
+			return SearchCriteria{}, p.errorf(symbol, "unsupported operator %s on JSON document %s", symbol, field)
+		}
+		decoded, err := p.decodeJSON(tok, value)
+		return CreateSearchCondition(field, OpJSONContains, decoded), err
+	}
+	if document {
+		return SearchCriteria{}, p.errorf(p.peek(), "expected ::jsonb after JSON document %s, got %s", field, p.peek())
+	}
+	operator := map[string]string{"@>": OpArrayContains, "<@": OpArrayContainedBy, "&&": OpArrayOverlaps}[symbol.text]
+	return CreateSearchCondition(field, operator, value), nil
+}
+
+// parseMemberOf parses value = ANY(field) (PostgreSQL) and value MEMBER OF(field) (MySQL)
+func (p *whereParser) parseMemberOf() (SearchCriteria, error) {
+	value, err := p.parseValue()
+	if err != nil {
+		return SearchCriteria{}, err
+	}
+	if p.acceptSymbol("=") {
+		err = p.expectKeyword("ANY")
+	} else {
+		err = p.expectKeyword("MEMBER", "OF")
+	}
+	if err != nil {
+		return SearchCriteria{}, err
+	}
+	field, err := p.parseCallColumn()
+	return CreateSearchCondition(field, OpArrayContains, value), err
+}
+
+// parseJSONContains parses MySQL JSON_CONTAINS(field, value[, path]) and JSON_CONTAINS(value, field)
+// The document is decoded from the bound JSON text. An array contained in a whole column is
+// OpArrayContains, which renders the same, so the result needs no JSON column in the schema.
+func (p *whereParser) parseJSONContains() (SearchCriteria, error) {
+	p.advance()
+	if err := p.expectSymbol("("); err != nil {
+		return SearchCriteria{}, err
+	}
+
+	if tok := p.peek(); tok.kind == tokParam || tok.kind == tokString {
+		value, err := p.parseValue()
+		if err != nil {
+			return SearchCriteria{}, err
+		}
+		decoded, err := p.decodeJSON(tok, value)
+		if err != nil {
+			return SearchCriteria{}, err
+		}
+		if err := p.expectSymbol(","); err != nil {
+			return SearchCriteria{}, err
+		}
+		field, err := p.parseColumn()
+		if err != nil {
+			return SearchCriteria{}, err
+		}
+		return CreateSearchCondition(field, OpArrayContainedBy, decoded), p.expectSymbol(")")
+	}
+
+	field, err := p.parseColumn()
+	if err != nil {
+		return SearchCriteria{}, err
+	}
+	if err := p.expectSymbol(","); err != nil {
+		return SearchCriteria{}, err
+	}
+	tok := p.peek()
+	value, err := p.parseValue()
+	if err != nil {
+		return SearchCriteria{}, err
+	}
+	decoded, err := p.decodeJSON(tok, value)
+	if err != nil {
+		return SearchCriteria{}, err
+	}
+
+	operator := OpJSONContains
+	if p.acceptSymbol(",") {
+		path, err := p.parseMySQLJSONPath()
+		if err != nil {
+			return SearchCriteria{}, err
+		}
+		field += "." + path
+	} else if _, isArray := decoded.([]any); isArray {
+		operator = OpArrayContains
+	}
+	return CreateSearchCondition(field, operator, decoded), p.expectSymbol(")")
+}
+
+// parseJSONOverlaps parses MySQL JSON_OVERLAPS(field, value)
+func (p *whereParser) parseJSONOverlaps() (SearchCriteria, error) {
+	p.advance()
+	if err := p.expectSymbol("("); err != nil {
+		return SearchCriteria{}, err
+	}
+	field, err := p.parseColumn()
+	if err != nil {
+		return SearchCriteria{}, err
+	}
+	if err := p.expectSymbol(","); err != nil {
+		return SearchCriteria{}, err
+	}
+	tok := p.peek()
+	value, err := p.parseValue()
+	if err != nil {
+		return SearchCriteria{}, err
+	}
+	decoded, err := p.decodeJSON(tok, value)
+	if err != nil {
+		return SearchCriteria{}, err
+	}
+	return CreateSearchCondition(field, OpArrayOverlaps, decoded), p.expectSymbol(")")
+}
+
+// parseJSONContainsPath parses MySQL JSON_CONTAINS_PATH(field, 'one', '$.path.key')
+func (p *whereParser) parseJSONContainsPath() (SearchCriteria, error) {
+	p.advance()
+	if err := p.expectSymbol("("); err != nil {
+		return SearchCriteria{}, err
+	}
+	field, err := p.parseColumn()
+	if err != nil {
+		return SearchCriteria{}, err
+	}
+	if err := p.expectSymbol(","); err != nil {
+		return SearchCriteria{}, err
+	}
+	if tok := p.advance(); tok.kind != tokString || !strings.EqualFold(tok.text, "one") {
+		return SearchCriteria{}, p.errorf(tok, "expected 'one', got %s", tok)
+	}
+	if err := p.expectSymbol(","); err != nil {
+		return SearchCriteria{}, err
+	}
+	path, err := p.parseMySQLJSONPath()
+	if err != nil {
+		return SearchCriteria{}, err
+	}
+	if i := strings.LastIndex(path, "."); i >= 0 {
+		field, path = field+"."+path[:i], path[i+1:]
+	}
+	return CreateSearchCondition(field, OpJSONHasKey, path), p.expectSymbol(")")
+}
+
+// parseJSONBExists parses PostgreSQL jsonb_exists(document, key)
+func (p *whereParser) parseJSONBExists() (SearchCriteria, error) {
+	p.advance()
+	if err := p.expectSymbol("("); err != nil {
+		return SearchCriteria{}, err
+	}
+	name, err := p.parseName()
+	if err != nil {
+		return SearchCriteria{}, err
+	}
+	tok := p.peek()
+	field, arrow, err := p.parseJSONArrow(name)
+	if err != nil {
+		return SearchCriteria{}, err
+	}
+	if arrow == "->>" || arrow == "#>>" {
+		return SearchCriteria{}, p.errorf(tok, "expected -> or #> on JSON path %s", field)
+	}
+	if err := p.expectSymbol(","); err != nil {
+		return SearchCriteria{}, err
+	}
+	value, err := p.parseValue()
+	if err != nil {
+		return SearchCriteria{}, err
+	}
+	return CreateSearchCondition(field, OpJSONHasKey, value), p.expectSymbol(")")
+}
+
+// parseArrayLength parses JSON_LENGTH(field) op value (MySQL) and cardinality(field) op value (PostgreSQL)
+func (p *whereParser) parseArrayLength() (SearchCriteria, error) {
+	p.advance()
+	field, err := p.parseCallColumn()
+	if err != nil {
+		return SearchCriteria{}, err
+	}
+
+	tok := p.advance()
+	operator := whereOperators[tok.text]
+	if _, ok := comparisonOperators[operator]; tok.kind != tokSymbol || !ok {
+		return SearchCriteria{}, p.errorf(tok, "expected comparison after array length of %s, got %s", field, tok)
+	}
+
+	tok = p.peek()
+	value, err := p.parseValue()
+	if err != nil {
+		return SearchCriteria{}, err
+	}
+	length, ok := intValue(value)
+	if !ok {
+		return SearchCriteria{}, p.errorf(tok, "expected integer length, got %v", value)
+	}
+	return CreateSearchCondition(field, OpArrayLength, ArrayLength{Operator: operator, Length: length}), nil
+}
+
+// parseDistanceSphere parses MySQL ST_Distance_Sphere(field, point) <= meters
+func (p *whereParser) parseDistanceSphere() (SearchCriteria, error) {
+	p.advance()
+	if err := p.expectSymbol("("); err != nil {
+		return SearchCriteria{}, err
+	}
+	field, err := p.parseColumn()
+	if err != nil {
+		return SearchCriteria{}, err
+	}
+	if err := p.expectSymbol(","); err != nil {
+		return SearchCriteria{}, err
+	}
+	center, err := p.parseGeoPoint()
+	if err != nil {
+		return SearchCriteria{}, err
+	}
+	if err := p.expectSymbol(")"); err != nil {
+		return SearchCriteria{}, err
+	}
+	if err := p.expectSymbol("<="); err != nil {
+		return SearchCriteria{}, err
+	}
+	meters, err := p.parseFloat()
+	radius := GeoRadius{Lat: center.Lat, Lng: center.Lng, RadiusKm: meters / 1000}
+	return CreateSearchCondition(field, OpWithinRadius, radius), err
+}
+
+// parseDWithin parses PostGIS ST_DWithin(field::geography, point::geography, meters)
+func (p *whereParser) parseDWithin() (SearchCriteria, error) {
+	p.advance()
+	if err := p.expectSymbol("("); err != nil {
+		return SearchCriteria{}, err
+	}
+	field, err := p.parseColumn()
+	if err != nil {
+		return SearchCriteria{}, err
+	}
+	if err := p.expectCast("geography"); err != nil {
+		return SearchCriteria{}, err
+	}
+	if err := p.expectSymbol(","); err != nil {
+		return SearchCriteria{}, err
+	}
+	center, err := p.parseGeoPoint()
+	if err != nil {
+		return SearchCriteria{}, err
+	}
+	if err := p.expectCast("geography"); err != nil {
+		return SearchCriteria{}, err
+	}
+	if err := p.expectSymbol(","); err != nil {
+		return SearchCriteria{}, err
+	}
+	meters, err := p.parseFloat()
+	if err != nil {
+		return SearchCriteria{}, err
+	}
+	radius := GeoRadius{Lat: center.Lat, Lng: center.Lng, RadiusKm: meters / 1000}
+	return CreateSearchCondition(field, OpWithinRadius, radius), p.expectSymbol(")")
+}
+
+// parseMBRContains parses MySQL MBRContains(ST_GeomFromText(polygon, 4326, 'axis-order=long-lat'), field)
+func (p *whereParser) parseMBRContains() (SearchCriteria, error) {
+	p.advance()
+	if err := p.expectSymbol("("); err != nil {
+		return SearchCriteria{}, err
+	}
+	if err := p.expectKeyword("ST_GeomFromText"); err != nil {
+		return SearchCriteria{}, err
+	}
+	if err := p.expectSymbol("("); err != nil {
+		return SearchCriteria{}, err
+	}
+	tok := p.peek()
+	value, err := p.parseValue()
+	if err != nil {
+		return SearchCriteria{}, err
+	}
+	polygon, _ := value.(string)
+	box, ok := parseBBoxPolygon(polygon)
+	if !ok {
+		return SearchCriteria{}, p.errorf(tok, "expected bounding box polygon, got %v", value)
+	}
+	if err := p.expectSymbol(","); err != nil {
+		return SearchCriteria{}, err
+	}
+	if err := p.expectSRID(); err != nil {
+		return SearchCriteria{}, err
+	}
+	if err := p.expectSymbol(","); err != nil {
+		return SearchCriteria{}, err
+	}
+	if tok := p.advance(); tok.kind != tokString || tok.text != mysqlAxisOrder {
+		return SearchCriteria{}, p.errorf(tok, "expected '%s', got %s", mysqlAxisOrder, tok)
+	}
+	if err := p.expectSymbol(")"); err != nil {
+		return SearchCriteria{}, err
+	}
+	if err := p.expectSymbol(","); err != nil {
+		return SearchCriteria{}, err
+	}
+	field, err := p.parseColumn()
+	if err != nil {
+		return SearchCriteria{}, err
+	}
+	return CreateSearchCondition(field, OpWithinBBox, box), p.expectSymbol(")")
+}
+
+// parseSTWithin parses PostGIS ST_Within(field::geometry, ST_MakeEnvelope(minLng, minLat, maxLng, maxLat, 4326))
+func (p *whereParser) parseSTWithin() (SearchCriteria, error) {
+	p.advance()
+	if err := p.expectSymbol("("); err != nil {
+		return SearchCriteria{}, err
+	}
+	field, err := p.parseColumn()
+	if err != nil {
+		return SearchCriteria{}, err
+	}
+	if err := p.expectCast("geometry"); err != nil {
+		return SearchCriteria{}, err
+	}
+	if err := p.expectSymbol(","); err != nil {
+		return SearchCriteria{}, err
+	}
+	if err := p.expectKeyword("ST_MakeEnvelope"); err != nil {
+		return SearchCriteria{}, err
+	}
+	if err := p.expectSymbol("("); err != nil {
+		return SearchCriteria{}, err
+	}
+	var corners [4]float64
+	for i := range corners {
+		if corners[i], err = p.parseFloat(); err != nil {
+			return SearchCriteria{}, err
+		}
+		if err := p.expectSymbol(","); err != nil {
+			return SearchCriteria{}, err
+		}
+	}
+	if err := p.expectSRID(); err != nil {
+		return SearchCriteria{}, err
+	}
+	if err := p.expectSymbol(")"); err != nil {
+		return SearchCriteria{}, err
+	}
+	box := GeoBBox{MinLng: corners[0], MinLat: corners[1], MaxLng: corners[2], MaxLat: corners[3]}
+	return CreateSearchCondition(field, OpWithinBBox, box), p.expectSymbol(")")
+}
+
+// parseGeoPoint parses ST_SRID(POINT(lng, lat), 4326) (MySQL) and ST_SetSRID(ST_MakePoint(lng, lat), 4326) (PostGIS)
+func (p *whereParser) parseGeoPoint() (GeoPoint, error) {
+	constructor := "POINT"
+	tok := p.advance()
+	switch {
+	case isKeyword(tok, "ST_SetSRID"):
+		constructor = "ST_MakePoint"
+	case !isKeyword(tok, "ST_SRID"):
+		return GeoPoint{}, p.errorf(tok, "expected ST_SRID or ST_SetSRID, got %s", tok)
+	}
+	if err := p.expectSymbol("("); err != nil {
+		return GeoPoint{}, err
+	}
+	if err := p.expectKeyword(constructor); err != nil {
+		return GeoPoint{}, err
+	}
+	if err := p.expectSymbol("("); err != nil {
+		return GeoPoint{}, err
+	}
+	lng, err := p.parseFloat()
+	if err != nil {
+		return GeoPoint{}, err
+	}
+	if err := p.expectSymbol(","); err != nil {
+		return GeoPoint{}, err
+	}
+	lat, err := p.parseFloat()
+	if err != nil {
+		return GeoPoint{}, err
+	}
+	if err := p.expectSymbol(")"); err != nil {
+		return GeoPoint{}, err
+	}
+	if err := p.expectSymbol(","); err != nil {
+		return GeoPoint{}, err
+	}
+	if err := p.expectSRID(); err != nil {
+		return GeoPoint{}, err
+	}
+	return GeoPoint{Lat: lat, Lng: lng}, p.expectSymbol(")")
+}
+
+// expectSRID consumes the WGS84 SRID 4326, the only one the builder renders
+func (p *whereParser) expectSRID() error {
+	if tok := p.advance(); tok.kind != tokNumber || tok.text != "4326" {
+		return p.errorf(tok, "expected SRID 4326, got %s", tok)
+	}
+	return nil
+}
+
+// expectCast consumes ::type
+func (p *whereParser) expectCast(typ string) error {
+	if err := p.expectSymbol("::"); err != nil {
+		return err
+	}
+	return p.expectKeyword(typ)
+}
+
+// parseCallColumn parses the single parenthesized column of a function call
+func (p *whereParser) parseCallColumn() (string, error) {
+	if err := p.expectSymbol("("); err != nil {
+		return "", err
+	}
+	field, err := p.parseColumn()
+	if err != nil {
+		return "", err
+	}
+	return field, p.expectSymbol(")")
+}
+
+// parseFloat parses a numeric value as float64, as the geospatial operators take
+func (p *whereParser) parseFloat() (float64, error) {
+	tok := p.peek()
+	value, err := p.parseValue()
+	if err != nil {
+		return 0, err
+	}
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int()), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(v.Uint()), nil
+	}
+	return 0, p.errorf(tok, "expected number, got %v", value)
+}
+
+// decodeJSON decodes a JSON document bound as text, keeping numbers exact as json.Number
+func (p *whereParser) decodeJSON(tok whereToken, value any) (any, error) {
+	var text []byte
+	switch v := value.(type) {
+	case string:
+		text = []byte(v)
+	case []byte:
+		text = v
+	default:
+		return nil, p.errorf(tok, "expected JSON document, got %T", value)
+	}
+	decoder := json.NewDecoder(bytes.NewReader(text))
+	decoder.UseNumber()
+	var document any
+	if err := decoder.Decode(&document); err != nil || decoder.More() {
+		return nil, p.errorf(tok, "invalid JSON document %q", text)
+	}
+	return document, nil
+}
+
+// intValue converts integer values that fit an int
+func intValue(value any) (int, bool) {
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n := v.Int()
+		return int(n), int64(int(n)) == n
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n := v.Uint()
+		return int(n), n <= math.MaxInt
+	}
+	return 0, false
+}
+
+// parseColumnList parses (column, ...)
+func (p *whereParser) parseColumnList() ([]string, error) {
+	if err := p.expectSymbol("("); err != nil {
+		return nil, err
+	}
+	var columns []string
+	for {
+		column, err := p.parseColumn()
+		if err != nil {
+			return nil, err
+		}
+		columns = append(columns, column)
+		if !p.acceptSymbol(",") {
+			return columns, p.expectSymbol(")")
+		}
+	}
+}
+
+// parseCall parses the single parenthesized value of a function call
+func (p *whereParser) parseCall() (any, error) {
+	if err := p.expectSymbol("("); err != nil {
+		return nil, err
+	}
+	value, err := p.parseValue()
+	if err != nil {
+		return nil, err
+	}
+	return value, p.expectSymbol(")")
+}
+
+// parseValueList parses (value, ...)
+func (p *whereParser) parseValueList() ([]any, error) {
+	if err := p.expectSymbol("("); err != nil {
+		return nil, err
+	}
+	var values []any
+	for {
+		value, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+		if !p.acceptSymbol(",") {
+			return values, p.expectSymbol(")")
+		}
+	}
+}
+
+// parseValue parses a literal or a placeholder
+func (p *whereParser) parseValue() (any, error) {
+	tok := p.advance()
+	switch {
+	case tok.kind == tokString:
+		return tok.text, nil
+	case tok.kind == tokNumber:
+		return p.parseNumber(tok, "")
+	case tok.kind == tokSymbol && tok.text == "-":
+		if number := p.advance(); number.kind == tokNumber {
+			return p.parseNumber(number, "-")
+		}
+	case tok.kind == tokParam:
+		return p.bindArg(tok)
+	case isKeyword(tok, "TRUE"):
+		return true, nil
+	case isKeyword(tok, "FALSE"):
+		return false, nil
+	case isKeyword(tok, "NULL"):
+		return nil, nil
+	}
+	return nil, p.errorf(tok, "expected value, got %s", tok)
+}
+
+// parseNumber converts a number token to int64 or float64
+func (p *whereParser) parseNumber(tok whereToken, sign string) (any, error) {
+	if n, err := strconv.ParseInt(sign+tok.text, 10, 64); err == nil {
+		return n, nil
+	}
+	f, err := strconv.ParseFloat(sign+tok.text, 64)
+	if err != nil {
+		return nil, p.errorf(tok, "invalid number %s", tok)
+	}
+	return f, nil
+}
+
+// bindArg returns the argument bound to a placeholder
+func (p *whereParser) bindArg(tok whereToken) (any, error) {
+	switch {
+	case tok.text == "?":
+		if p.next >= len(p.args) {
+			return nil, p.errorf(tok, "missing argument %d", p.next+1)
+		}
+		p.next++
+		return p.args[p.next-1], nil
+	case strings.HasPrefix(tok.text, "$"):
+		n, _ := strconv.Atoi(tok.text[1:])
+		if n < 1 || n > len(p.args) {
+			return nil, p.errorf(tok, "missing argument %s", tok.text)
+		}
+		return p.args[n-1], nil
+	}
+
+	name := tok.text[1:]
+	for _, arg := range p.args {
+		if named, ok := arg.(sql.NamedArg); ok && named.Name == name {
+			return named.Value, nil
+		}
+	}
+	return nil, p.errorf(tok, "missing argument %s", tok.text)
+}
+
+// parseChain collects operands joined by the logical operator keyword into one group
+// It is shared by the WHERE and filter expression parsers.
+func parseChain(operator string, accept func(keyword string) bool, operand func() (whereNode, error)) (whereNode, error) {
+	first, err := operand()
+	if err != nil || !accept(operator) {
+		return first, err
+	}
+
+	group := CreateSearchGroup(operator)
+	addWhereNode(&group, first)
+	for {
+		node, err := operand()
+		if err != nil {
+			return whereNode{}, err
+		}
+		addWhereNode(&group, node)
+		if !accept(operator) {
+			return whereNode{group: &group}, nil
+		}
+	}
+}
+
+// negateNode negates a parsed node, wrapping criteria and negated groups in a NOT group
+func negateNode(node whereNode) whereNode {
+	if node.group != nil && !node.group.Not {
+		node.group.Not = true
+		return node
+	}
+	group := CreateSearchGroup(LogicAnd)
+	addWhereNode(&group, node)
+	group.Not = true
+	return whereNode{group: &group}
+}
+
+// addWhereNode appends a parsed node to a group
+func addWhereNode(group *LogicalGroup, node whereNode) {
+	if node.criterion != nil {
+		group.Conditions = append(group.Conditions, *node.criterion)
+		return
+	}
+	group.Groups = append(group.Groups, *node.group)
+}
+
+// likeCriterion maps a LIKE pattern to the narrowest operator and its value
+// Patterns with a leading and/or trailing % only become contains, starts_with or ends_with.
+func likeCriterion(value any, insensitive, negated bool) (string, any) {
+	operator, pattern := OpLike, value
+	if s, ok := value.(string); ok && s != "" {
+		switch {
+		case len(s) >= 2 && s[0] == '%' && s[len(s)-1] == '%' && !strings.Contains(s[1:len(s)-1], "%"):
+			operator, pattern = OpContains, s[1:len(s)-1]
+		case s[len(s)-1] == '%' && !strings.Contains(s[:len(s)-1], "%"):
+			operator, pattern = OpStartsWith, s[:len(s)-1]
+		case s[0] == '%' && !strings.Contains(s[1:], "%"):
+			operator, pattern = OpEndsWith, s[1:]
+		}
+	}
+
+	variant := 0
+	if insensitive {
+		variant++
+	}
+	if negated {
+		variant += 2
+	}
+	return likeOperators[operator][variant], pattern
+}
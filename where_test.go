@@ -0,0 +1,350 @@
+package sqlbuilder
+
+import (
+	"database/sql"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Test ParseWhere on hand written fragments
+func TestParseWhere(t *testing.T) {
+	tests := []struct {
+		name     string
+		where    string
+		args     []any
+		expected LogicalGroup
+	}{
+		{
+			name:     "empty",
+			where:    "  ",
+			expected: CreateSearchGroup(LogicAnd),
+		},
+		{
+			name:  "precedence",
+			where: "status = 'a' AND (age > 18 OR vip IS NOT NULL)",
+			expected: LogicalGroup{
+				Operator:   LogicAnd,
+				Conditions: []SearchCriteria{CreateSearchCondition("status", OpEqual, "a")},
+				Groups: []LogicalGroup{{
+					Operator: LogicOr,
+					Conditions: []SearchCriteria{
+						CreateSearchCondition("age", OpGreaterThan, int64(18)),
+						CreateSearchCondition("vip", OpIsNotNull, nil),
+					},
+					Groups: []LogicalGroup{},
+				}},
+			},
+		},
+		{
+			name:  "AND binds tighter than OR",
+			where: "a = 1 OR b = 2 AND c <> -2.5",
+			expected: LogicalGroup{
+				Operator:   LogicOr,
+				Conditions: []SearchCriteria{CreateSearchCondition("a", OpEqual, int64(1))},
+				Groups: []LogicalGroup{{
+					Operator: LogicAnd,
+					Conditions: []SearchCriteria{
+						CreateSearchCondition("b", OpEqual, int64(2)),
+						CreateSearchCondition("c", OpNotEqual, -2.5),
+					},
+					Groups: []LogicalGroup{},
+				}},
+			},
+		},
+		{
+			name:  "placeholders and quoted identifiers",
+			where: "`order` >= ? and \"u\".\"name\" != $3 and t.id in (?, :id)",
+			args:  []any{10, 20, "bob", sql.Named("id", 7)},
+			expected: CreateSearchGroup(LogicAnd,
+				CreateSearchCondition("order", OpGreaterThanEq, 10),
+				CreateSearchCondition("u.name", OpNotEqual, "bob"),
+				CreateSearchCondition("t.id", OpIn, []any{20, 7}),
+			),
+		},
+		{
+			name:  "like patterns",
+			where: "a LIKE '%x%' AND LOWER(b) NOT LIKE LOWER('x%') AND c LIKE '%x' AND LOWER(d) LIKE LOWER('x_y') AND e NOT LIKE '%a%b%'",
+			expected: CreateSearchGroup(LogicAnd,
+				CreateSearchCondition("a", OpContains, "x"),
+				CreateSearchCondition("b", OpNotIStartsWith, "x"),
+				CreateSearchCondition("c", OpEndsWith, "x"),
+				CreateSearchCondition("d", OpILike, "x_y"),
+				CreateSearchCondition("e", OpNotLike, "%a%b%"),
+			),
+		},
+		{
+			name:  "regex",
+			where: "a REGEXP '^x' AND b NOT REGEXP 'y' AND REGEXP_LIKE(c, 'z', 'i') AND NOT REGEXP_LIKE(d, 'w', 'i') AND e ~* 'v' AND f !~ 'u'",
+			expected: CreateSearchGroup(LogicAnd,
+				CreateSearchCondition("a", OpRegex, "^x"),
+				CreateSearchCondition("b", OpNotRegex, "y"),
+				CreateSearchCondition("c", OpIRegex, "z"),
+				CreateSearchCondition("d", OpNotIRegex, "w"),
+				CreateSearchCondition("e", OpIRegex, "v"),
+				CreateSearchCondition("f", OpNotRegex, "u"),
+			),
+		},
+		{
+			name:  "full text",
+			where: "MATCH(title, body) AGAINST('go' IN BOOLEAN MODE) OR to_tsvector(title) @@ plainto_tsquery('sql')",
+			expected: LogicalGroup{
+				Operator: LogicOr,
+				Conditions: []SearchCriteria{
+					CreateSearchCondition("title,body", OpFullTextBoolean, "go"),
+					CreateSearchCondition("title", OpFullText, "sql"),
+				},
+				Groups: []LogicalGroup{},
+			},
+		},
+		{
+			name:  "NOT",
+			where: "NOT (a = TRUE OR b IS NULL) AND NOT c = 'it''s'",
+			expected: LogicalGroup{
+				Operator: LogicAnd,
+				Groups: []LogicalGroup{
+					{
+						Operator: LogicOr,
+						Conditions: []SearchCriteria{
+							CreateSearchCondition("a", OpEqual, true),
+							CreateSearchCondition("b", OpIsNull, nil),
+						},
+						Groups: []LogicalGroup{},
+						Not:    true,
+					},
+					{
+						Operator:   LogicAnd,
+						Conditions: []SearchCriteria{CreateSearchCondition("c", OpEqual, "it's")},
+						Groups:     []LogicalGroup{},
+						Not:        true,
+					},
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			group, err := ParseWhere(tt.where, tt.args...)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, group)
+		})
+	}
+}
+
+// Test ParseWhere accepts the output of BuildAdvancedSearchConditions
+func TestParseWhere_RoundTrip(t *testing.T) {
+	group := CreateSearchGroup(LogicAnd,
+		CreateSearchCondition("status", OpEqual, "active"),
+		CreateSearchCondition("name", OpIContains, "go"),
+		CreateSearchCondition("title", OpNotStartsWith, "draft"),
+		CreateSearchCondition("age", OpLessThanEq, 65),
+		CreateSearchCondition("role", OpNotIn, []any{"guest", "bot"}),
+		CreateSearchCondition("deleted_at", OpIsNull, nil),
+		CreateSearchCondition("code", OpIRegex, "^a"),
+		CreateSearchCondition("slug", OpNotIRegex, "x$"),
+		CreateSearchCondition("title,body", OpFullText, "golang"),
+	)
+	group.Groups = append(group.Groups,
+		CreateSearchGroup(LogicOr,
+			CreateSearchCondition("vip", OpEqual, true),
+			CreateSearchCondition("score", OpGreaterThan, 90),
+		),
+		LogicalGroup{
+			Operator:   LogicAnd,
+			Not:        true,
+			Conditions: []SearchCriteria{CreateSearchCondition("email", OpEndsWith, "@test.com")},
+		},
+	)
+
+	for name, dialect := range map[string]Dialect{"mysql": DialectMySQL, "postgres": DialectPostgres} {
+		t.Run(name, func(t *testing.T) {
+			builder := NewSQLBuilder()
+			builder.SetDialect(dialect)
+			where := builder.BuildAdvancedSearchConditions([]LogicalGroup{group})
+
+			parsed, err := ParseWhere(where, builder.GetParams()...)
+			assert.NoError(t, err)
+
+			again := NewSQLBuilder()
+			again.SetDialect(dialect)
+			assert.Equal(t, where, again.BuildAdvancedSearchConditions([]LogicalGroup{parsed}))
+			assert.Equal(t, builder.GetParams(), again.GetParams())
+		})
+	}
+
+	t.Run("failed build", func(t *testing.T) {
+		failed := group
+		failed.Groups = append(append([]LogicalGroup{}, group.Groups...), CreateSearchGroup("XOR", CreateSearchCondition("a", OpEqual, 1)))
+
+		builder := NewSQLBuilder()
+		where := builder.BuildAdvancedSearchConditions([]LogicalGroup{failed})
+		assert.Equal(t, "1 = 0", where)
+		assert.ErrorIs(t, builder.Err(), ErrInvalidGroupOperator)
+
+		parsed, err := ParseWhere(where)
+		assert.NoError(t, err)
+
+		again := NewSQLBuilder()
+		assert.Equal(t, where, again.BuildAdvancedSearchConditions([]LogicalGroup{parsed}))
+		assert.Error(t, again.Err())
+
+		builder = NewSQLBuilder()
+		builder.AddWhereCondition(builder.BuildAdvancedSearchConditions([]LogicalGroup{CreateSearchGroup(LogicAnd, CreateSearchCondition("tenant_id", OpEqual, 7)), failed}))
+		builder.AddWhere("a = ?")
+		where = builder.GetWhereClause(false)
+		assert.Equal(t, "(tenant_id = ?) AND 1 = 0 AND (1 = 0)", where)
+
+		parsed, err = ParseWhere(where, builder.GetParams()...)
+		assert.NoError(t, err)
+		never := CreateSearchGroup("1 = 0")
+		assert.Equal(t, LogicalGroup{
+			Operator:   LogicAnd,
+			Conditions: []SearchCriteria{CreateSearchCondition("tenant_id", OpEqual, 7)},
+			Groups:     []LogicalGroup{never, never},
+		}, parsed)
+	})
+}
+
+// Test ParseWhere accepts the JSON, array and geospatial output of BuildAdvancedSearchConditions
+func TestParseWhere_RoundTripFunctions(t *testing.T) {
+	schema := NewSchema().AllowJSONPaths("metadata", "color", "size", "size.width", "tags")
+	group := CreateSearchGroup(LogicAnd,
+		CreateSearchCondition("metadata.color", OpEqual, "red"),
+		CreateSearchCondition("metadata.color", OpIContains, "re"),
+		CreateSearchCondition("metadata.size.width", OpGreaterThan, 10),
+		CreateSearchCondition("metadata.size.width", OpIn, []any{1, 2.5}),
+		CreateSearchCondition("metadata.size.width", OpIsNotNull, nil),
+		CreateSearchCondition("metadata.tags", OpJSONContains, []string{"sale"}),
+		CreateSearchCondition("metadata", OpJSONContains, map[string]any{"color": "red", "size": 3}),
+		CreateSearchCondition("metadata", OpJSONHasKey, "color"),
+		CreateSearchCondition("metadata.size", OpJSONHasKey, "width"),
+		CreateSearchCondition("tags", OpArrayContains, "go"),
+		CreateSearchCondition("tags", OpArrayContains, []string{"go", "sql"}),
+		CreateSearchCondition("tags", OpArrayContainedBy, []string{"go", "sql", "db"}),
+		CreateSearchCondition("tags", OpArrayOverlaps, []string{"go"}),
+		CreateSearchCondition("tags", OpArrayLength, ArrayLength{Operator: OpGreaterThanEq, Length: 2}),
+		CreateSearchCondition("location", OpWithinRadius, GeoRadius{Lat: 52.52, Lng: 13.405, RadiusKm: 5}),
+		CreateSearchCondition("location", OpWithinBBox, GeoBBox{MinLat: 52.3, MinLng: 13.0, MaxLat: 52.7, MaxLng: 13.8}),
+	)
+
+	for name, dialect := range map[string]Dialect{"mysql": DialectMySQL, "postgres": DialectPostgres} {
+		t.Run(name, func(t *testing.T) {
+			builder := NewSQLBuilder()
+			builder.SetDialect(dialect)
+			builder.SetSchema(schema)
+			where := builder.BuildAdvancedSearchConditions([]LogicalGroup{group})
+			assert.NoError(t, builder.Err())
+
+			parsed, err := ParseWhere(where, builder.GetParams()...)
+			assert.NoError(t, err)
+
+			again := NewSQLBuilder()
+			again.SetDialect(dialect)
+			again.SetSchema(schema)
+			assert.Equal(t, where, again.BuildAdvancedSearchConditions([]LogicalGroup{parsed}))
+			assert.Equal(t, builder.GetParams(), again.GetParams())
+			assert.NoError(t, again.Err())
+		})
+	}
+}
+
+// Test ParseWhere maps function calls back to the criteria they were rendered from
+func TestParseWhere_Functions(t *testing.T) {
+	tests := []struct {
+		name     string
+		where    string
+		args     []any
+		expected SearchCriteria
+	}{
+		{
+			name:     "mysql numeric json path",
+			where:    "CAST(JSON_UNQUOTE(JSON_EXTRACT(metadata, '$.size.width')) AS DECIMAL(65, 30)) > ?",
+			args:     []any{10},
+			expected: CreateSearchCondition("metadata.size.width", OpGreaterThan, 10),
+		},
+		{
+			name:     "postgres numeric json path",
+			where:    "(metadata->>'size')::numeric <= 2.5",
+			expected: CreateSearchCondition("metadata.size", OpLessThanEq, 2.5),
+		},
+		{
+			name:     "json document",
+			where:    "metadata#>'{size,width}' @> $1::jsonb",
+			args:     []any{`{"cm":12}`},
+			expected: CreateSearchCondition("metadata.size.width", OpJSONContains, map[string]any{"cm": json.Number("12")}),
+		},
+		{
+			name:     "contained by",
+			where:    "JSON_CONTAINS(?, tags)",
+			args:     []any{`["a","b"]`},
+			expected: CreateSearchCondition("tags", OpArrayContainedBy, []any{"a", "b"}),
+		},
+		{
+			name:     "member of",
+			where:    "'go' MEMBER OF(tags)",
+			expected: CreateSearchCondition("tags", OpArrayContains, "go"),
+		},
+		{
+			name:     "array length",
+			where:    "cardinality(tags) <> 0",
+			expected: CreateSearchCondition("tags", OpArrayLength, ArrayLength{Operator: OpNotEqual, Length: 0}),
+		},
+		{
+			name:     "radius",
+			where:    "ST_DWithin(location::geography, ST_SetSRID(ST_MakePoint(13.4, 52.5), 4326)::geography, 2500)",
+			expected: CreateSearchCondition("location", OpWithinRadius, GeoRadius{Lat: 52.5, Lng: 13.4, RadiusKm: 2.5}),
+		},
+		{
+			name:     "unicode identifiers",
+			where:    "größe = 1",
+			expected: CreateSearchCondition("größe", OpEqual, int64(1)),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			group, err := ParseWhere(tt.where, tt.args...)
+			assert.NoError(t, err)
+			assert.Equal(t, CreateSearchGroup(LogicAnd, tt.expected), group)
+		})
+	}
+}
+
+// Test ParseWhere errors carry the offset of the offending token
+func TestParseWhere_Errors(t *testing.T) {
+	tests := []struct {
+		name     string
+		where    string
+		args     []any
+		expected string
+	}{
+		{name: "unbalanced", where: "(a = 1", expected: `sqlbuilder: syntax error at offset 6: expected ")", got end of input`},
+		{name: "trailing", where: "a = 1 b", expected: `sqlbuilder: syntax error at offset 6: unexpected "b"`},
+		{name: "missing operator", where: "a 1", expected: `sqlbuilder: syntax error at offset 2: expected operator after a, got "1"`},
+		{name: "keyword as column", where: "AND = 1", expected: `sqlbuilder: syntax error at offset 0: expected column, got "AND"`},
+		{name: "function", where: "UPPER(name) = 'X'", expected: `sqlbuilder: syntax error at offset 0: unsupported function UPPER`},
+		{name: "unsupported operator", where: "tags @@ ?", args: []any{1}, expected: `sqlbuilder: syntax error at offset 5: unsupported operator "@@"`},
+		{name: "cast", where: "a = $1::jsonb", args: []any{1}, expected: `sqlbuilder: syntax error at offset 6: unexpected "::"`},
+		{name: "missing argument", where: "a = ? AND b = ?", args: []any{1}, expected: `sqlbuilder: syntax error at offset 14: missing argument 2`},
+		{name: "missing named argument", where: "a = :a", expected: `sqlbuilder: syntax error at offset 4: missing argument :a`},
+		{name: "unterminated string", where: "a = 'x", expected: `sqlbuilder: syntax error at offset 4: unterminated string`},
+		{name: "unexpected character", where: "a = 1; DROP TABLE t", expected: `sqlbuilder: syntax error at offset 5: unexpected character ';'`},
+		{name: "json document compared", where: "metadata->'color' = 'red'", expected: `sqlbuilder: syntax error at offset 18: expected @> after JSON document metadata.color, got "="`},
+		{name: "invalid json document", where: "JSON_OVERLAPS(tags, ?)", args: []any{"[1"}, expected: `sqlbuilder: syntax error at offset 20: invalid JSON document "[1"`},
+		{name: "other srid", where: "ST_Distance_Sphere(location, ST_SRID(POINT(1, 2), 0)) <= 5", expected: `sqlbuilder: syntax error at offset 50: expected SRID 4326, got "0"`},
+		{name: "bounding box without axis order", where: "MBRContains(ST_GeomFromText(?, 4326), location)", args: []any{"POLYGON((1 2, 3 2, 3 4, 1 4, 1 2))"}, expected: `sqlbuilder: syntax error at offset 35: expected ",", got ")"`},
+		{name: "polygon not a bounding box", where: "MBRContains(ST_GeomFromText(?, 4326, 'axis-order=long-lat'), location)", args: []any{"POLYGON((1 2, 3 2, 3 5, 1 4, 1 2))"}, expected: `sqlbuilder: syntax error at offset 28: expected bounding box polygon, got POLYGON((1 2, 3 2, 3 5, 1 4, 1 2))`},
+		{name: "unicode unexpected character", where: "a = 1 € 2", expected: `sqlbuilder: syntax error at offset 6: unexpected character '€'`},
+		{name: "one sided LOWER", where: "LOWER(a) LIKE 'x'", expected: `sqlbuilder: syntax error at offset 14: expected LOWER, got 'x'`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseWhere(tt.where, tt.args...)
+			assert.ErrorIs(t, err, ErrSyntax)
+			assert.EqualError(t, err, tt.expected)
+		})
+	}
+}